@@ -0,0 +1,48 @@
+package jv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// accessible draws the viewer for screen readers: glyphs are spelled out in
+// ASCII, the row under the cursor is described in a sentence and the
+// loading screen is redrawn once a second rather than ten times
+// it can be set in the config file or from the command line
+var accessible = false
+
+// asciiGlyphs spell out the glyphs of the viewer for screen readers, which
+// read arrows and box drawing out by their names or not at all
+var asciiGlyphs = strings.NewReplacer(
+	"→", ">", "←", "<", "›", ">",
+	"↑", "up", "↓", "down",
+	"…", "...", "⋯", "...", "–", "-",
+	"•", "*", "●", "*", "×", "x", "≈", "~",
+	"⚠", "warning:", "✗", "error:",
+	"─", "-", "│", "|", "┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"█", "#", "░", "-", "▉", "#", "▊", "#", "▋", "#", "▌", "#", "▍", "#", "▎", "#", "▏", "#",
+	"⠋", "", "⠙", "", "⠹", "", "⠸", "", "⠼", "", "⠴", "", "⠦", "", "⠧", "", "⠇", "", "⠏", "",
+)
+
+// plainView is a utility function that spells out the glyphs of a view
+func plainView(s string) string {
+	return asciiGlyphs.Replace(s)
+}
+
+// announcement describes the row under the cursor in a sentence, such as
+// "row 2 of 9, on the value of spec: {3 keys}"
+func (m *Model) announcement() string {
+	if len(m.CurrKV) == 0 {
+		return "no rows"
+	}
+	kv := m.CurrKV[m.CurrC.RowNo]
+	if kv.More {
+		return fmt.Sprintf("row %d of %d, %s", m.CurrC.RowNo+1, len(m.CurrKV), unstyle(kv.Value))
+	}
+	on := "on the key"
+	if !m.CurrC.IsKey {
+		on = "on the value of"
+	}
+	return fmt.Sprintf("row %d of %d, %s %s: %s", m.CurrC.RowNo+1, len(m.CurrKV), on,
+		unstyle(kv.Key), unstyle(m.displayValue(kv)))
+}
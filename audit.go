@@ -0,0 +1,79 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// auditPath is the file every path viewed, copied or exported in a session
+// is appended to, it can be set in the config file or from the command line
+// the values themselves are left out so that the log holds no customer data
+var auditPath = ""
+
+// auditLog is auditPath opened, nil when there is no audit log
+var auditLog *os.File
+
+// auditMu keeps the lines of the workspace's documents from interleaving
+var auditMu sync.Mutex
+
+// what is done with a path that is audited
+const (
+	auditView   = "view"
+	auditCopy   = "copy"
+	auditExport = "export"
+)
+
+// auditEvent is a line of the audit log
+type auditEvent struct {
+	Time   string `json:"time"`             // when it happened, RFC 3339 with milliseconds
+	Action string `json:"action"`           // view, copy or export
+	File   string `json:"file,omitempty"`   // the input, empty for stdin
+	Path   string `json:"path"`             // selector of the value such as .a[0]
+	Detail string `json:"detail,omitempty"` // what was copied or where it was exported to
+}
+
+// openAuditLog is a utility function that opens the audit log for appending,
+// creating it readable only by the user
+func openAuditLog(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open the audit log: %w", err)
+	}
+	auditLog = f
+	return nil
+}
+
+// audit records what was done with the value at path in the audit log
+func (m *Model) audit(action string, path []string, detail string) {
+	if auditLog == nil || m.Data == nil {
+		return
+	}
+	line, err := json.Marshal(auditEvent{
+		Time:   time.Now().Format("2006-01-02T15:04:05.000Z07:00"),
+		Action: action,
+		File:   m.Source,
+		Path:   selectorPath(m.Data, path),
+		Detail: detail,
+	})
+	if err != nil {
+		debugLog.Printf("cannot encode the audit event: %s", err)
+		return
+	}
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if _, err := auditLog.Write(append(line, '\n')); err != nil {
+		debugLog.Printf("cannot write the audit log: %s", err)
+	}
+}
+
+// copyValue copies s to the clipboard, recording the copy of the value at
+// path in the audit log
+func (m *Model) copyValue(path []string, s, what string) tea.Cmd {
+	m.audit(auditCopy, path, what)
+	return copyCmd(s, what)
+}
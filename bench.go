@@ -0,0 +1,127 @@
+package jv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// backend is a way of parsing the input into the tree the viewer explores
+type backend struct {
+	name  string
+	parse func(content []byte) (any, error)
+}
+
+// backends are compared by jv bench
+// eager parses everything up front, lazy only checks the syntax and parses
+// containers as they are opened, which is the default for files that fit
+// in memory, and streaming reads containers from the input as they are
+// opened, which is used for files larger than streamThreshold
+var backends = []backend{
+	{"eager", func(content []byte) (any, error) {
+		data, _, err := parseInput(content)
+		return materialize(data), err
+	}},
+	{"lazy", func(content []byte) (any, error) {
+		data, _, err := parseInput(content)
+		return data, err
+	}},
+	{"streaming", func(content []byte) (any, error) {
+		return openStream(context.Background(), &loadProgress{}, bytes.NewReader(content), int64(len(content)))
+	}},
+}
+
+// benchResult is how one backend did on the input
+type benchResult struct {
+	Parse  time.Duration // time taken to parse the input
+	Render time.Duration // time taken from the parsed input to the first frame
+	Heap   int64         // memory held by the parsed input
+	Alloc  int64         // memory allocated while parsing
+}
+
+// benchmark is a utility function that parses the content with a backend and
+// shows it the way the viewer would on a screen of 80 by 24
+// the indexer starts in the background while the first frame is rendered,
+// as it does in the viewer
+func benchmark(b backend, path string, content []byte) (benchResult, error) {
+	goroutines := runtime.NumGoroutine()
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	data, err := b.parse(content)
+	if err != nil {
+		return benchResult{}, err
+	}
+	result := benchResult{Parse: time.Since(start)}
+	runtime.ReadMemStats(&after)
+	result.Alloc = int64(after.TotalAlloc - before.TotalAlloc)
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	result.Heap = int64(after.HeapAlloc) - int64(before.HeapAlloc)
+	if result.Heap < 0 {
+		result.Heap = 0
+	}
+
+	m := NewModel(path)
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	start = time.Now()
+	m.Update(loadedMsg{Data: data})
+	m.View()
+	result.Render = time.Since(start)
+	// the indexer is waited for once it is cancelled so that it does not
+	// take up the memory and time of the next run
+	m.cancelTasks(true)
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > goroutines && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	runtime.KeepAlive(data)
+	return result, nil
+}
+
+// bench loads the file at path through every backend runs times and prints
+// the fastest run of each
+func bench(out io.Writer, path string, runs int) error {
+	if path == "" {
+		return fmt.Errorf("jv bench needs a file to load")
+	}
+	if runs < 1 {
+		return fmt.Errorf("expected at least 1 run but got %d", runs)
+	}
+	content, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	// sessions are neither restored nor saved so the runs are alike
+	restoreSessions = false
+	fmt.Fprintf(out, "%s, %s, best of %d runs\n\n", path, humanBytes(int64(len(content))), runs)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "backend\tparse\theap\tallocated\tfirst render")
+	for _, b := range backends {
+		var best benchResult
+		for i := 0; i < runs; i++ {
+			result, err := benchmark(b, path, content)
+			if err != nil {
+				return fmt.Errorf("cannot load %s with the %s backend: %w", path, b.name, err)
+			}
+			if i == 0 || result.Parse+result.Render < best.Parse+best.Render {
+				best = result
+			}
+		}
+		debugLog.Printf("benchmarked the %s backend: %+v", b.name, best)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", b.name,
+			best.Parse.Round(time.Microsecond), humanBytes(best.Heap),
+			humanBytes(best.Alloc), best.Render.Round(time.Microsecond))
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("cannot write the results: %w", err)
+	}
+	fmt.Fprintf(out, "\nfiles larger than %s are streamed, smaller ones are parsed lazily\n", humanBytes(streamThreshold))
+	return nil
+}
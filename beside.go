@@ -0,0 +1,88 @@
+package jv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// togglePinElement pins the array element under the cursor so that the
+// other elements of the array are shown beside it, or unpins it
+func (m *Model) togglePinElement() {
+	if len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return
+	}
+	kv := m.CurrKV[m.CurrC.RowNo]
+	path := m.currentPath()
+	if m.Flat || !kv.Index {
+		if m.Beside != nil {
+			m.Beside, m.rowCache = nil, nil
+			m.Status = "unpinned the element"
+			return
+		}
+		m.Status = "only array elements can be pinned to compare the others with"
+		return
+	}
+	if m.Beside != nil && pathKey(m.Beside) == pathKey(path) {
+		m.Beside, m.rowCache = nil, nil
+		m.Status = "unpinned " + selectorPath(m.Data, path)
+		return
+	}
+	m.Beside, m.rowCache = path, nil
+	m.Status = fmt.Sprintf("pinned %s, the other elements of the array are shown beside it", selectorPath(m.Data, path))
+}
+
+// besideBase returns the path in the pinned element matching the listing
+// when the listing is within another element of the same array
+func (m *Model) besideBase() ([]string, bool) {
+	n := len(m.Beside) - 1
+	if m.Beside == nil || m.Flat || len(m.Path) <= n {
+		return nil, false
+	}
+	if pathKey(m.Path[:n]) != pathKey(m.Beside[:n]) || m.Path[n] == m.Beside[n] {
+		return nil, false
+	}
+	base := append([]string{}, m.Beside...)
+	return append(base, m.Path[n+1:]...), true
+}
+
+// besideNote is shown in the header while the pinned element is beside the
+// listing
+func (m *Model) besideNote() string {
+	if _, ok := m.besideBase(); !ok {
+		return ""
+	}
+	return "  (beside " + sanitize(selectorPath(m.Data, m.Beside)) + ")"
+}
+
+// besideRow renders a row of the listing and the value of the same key in
+// the pinned element in two columns as wide as width, the pinned value
+// stands out when it differs
+func (m *Model) besideRow(kv KVPair, path []string, current bool, base []string, width int) string {
+	left := fmt.Sprintf("%s: %s", kv.Key, kv.Value)
+	if current && m.CurrC.IsKey {
+		left = fmt.Sprintf("%s %s: %s", m.CurrC.CursorDisplay, kv.Key, kv.Value)
+	} else if current {
+		left = fmt.Sprintf("%s: %s %s", kv.Key, m.CurrC.CursorDisplay, kv.Value)
+	}
+	left = fitCell(unstyle(left), width-1) + " "
+	if current {
+		left = style(left, styleBold)
+	} else if key := unstyle(kv.Key); strings.HasPrefix(left, key+":") {
+		left = style(key, styleKey) + left[len(key):]
+	}
+	if kv.More {
+		return left
+	}
+	pinned := append(append([]string{}, base...), path[len(m.Path):]...)
+	last := pinned[len(pinned)-1]
+	o, ok := getKAny(m.valueAt(pinned[:len(pinned)-1]))[last]
+	if !ok {
+		return left + style("(missing)", styleWarn)
+	}
+	value := unstyle(m.displayValue(KVPair{Key: last, Value: getVal(o), Path: pinned, Index: kv.Index}))
+	value = strings.TrimRight(fitCell(value, width), " ")
+	if jsonEqual(materialize(o), materialize(m.valueAt(path))) {
+		return left + style(value, styleFaint)
+	}
+	return left + style(value, styleWarn)
+}
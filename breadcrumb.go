@@ -0,0 +1,97 @@
+package jv
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// breadcrumb returns the selector of the current listing cut to fit in
+// width, leaving out the middle of a deep path such as .a.….x.y
+// the first key and as many of the last ones as fit are kept, the whole
+// path is shown when it was asked for
+func (m *Model) breadcrumb(width int) string {
+	full := sanitize(m.listingSelector())
+	if m.FullPath || utf8.RuneCountInString(full) <= width || len(m.Path) < 3 {
+		return full
+	}
+	segments := make([]string, len(m.Path))
+	for i, k := range m.Path {
+		if m.Nodes[i].Kind() == KindArray {
+			segments[i] = sanitize(fmt.Sprintf("[%s]", k))
+		} else {
+			segments[i] = sanitize(gronKey(k))
+		}
+	}
+	if r, ok := m.Slices[pathKey(m.Path)]; ok {
+		segments[len(segments)-1] += fmt.Sprintf("[%d:%d]", r[0], r[1])
+	}
+	first := segments[0]
+	if strings.HasPrefix(first, "[") {
+		first = "." + first
+	}
+	for keep := len(segments) - 2; keep > 1; keep-- {
+		s := first + ".…" + strings.Join(segments[len(segments)-keep:], "")
+		if utf8.RuneCountInString(s) <= width {
+			return s
+		}
+	}
+	return first + ".…" + segments[len(segments)-1]
+}
+
+// missingPath is the part of a path gone to that is not in the input,
+// shown beside the breadcrumb of the ancestor the listing went to instead
+type missingPath struct {
+	At       string // path of the listing it is shown at
+	Selector string // selector of the keys missing past the ancestor, such as .template.spec
+}
+
+// existingDepth returns how many keys of path lead to values in the data
+func (m *Model) existingDepth(path []string) int {
+	o := m.Data
+	for i, k := range path {
+		child, ok := getKAny(o)[k]
+		if !ok {
+			return i
+		}
+		o = child
+	}
+	return len(path)
+}
+
+// goToNearest goes to path or, when some of its keys are not in the input,
+// to the deepest of its ancestors that is rather than to an empty listing
+// it returns what is missing, such as ".spec has no .template", or "" when
+// the whole path was found
+func (m *Model) goToNearest(path []string) string {
+	found := m.existingDepth(path)
+	m.goTo(path[:found])
+	m.missing = nil
+	if found == len(path) {
+		return ""
+	}
+	parent := m.valueAt(path[:found])
+	var b strings.Builder
+	for i, k := range path[found:] {
+		if i == 0 && isArray(parent) {
+			fmt.Fprintf(&b, "[%s]", k)
+		} else {
+			b.WriteString(gronKey(k))
+		}
+	}
+	selector := sanitize(b.String())
+	m.missing = &missingPath{At: pathKey(m.Path), Selector: selector}
+	if found == 0 {
+		return "the input has no " + selector
+	}
+	return fmt.Sprintf("%s has no %s", sanitize(selectorPath(m.Data, path[:found])), selector)
+}
+
+// missingNote is shown in the header while the listing is where it went
+// instead of a path that is not in the input
+func (m *Model) missingNote() string {
+	if m.missing == nil || m.Flat || m.missing.At != pathKey(m.Path) {
+		return ""
+	}
+	return "  (" + m.missing.Selector + " missing)"
+}
@@ -0,0 +1,98 @@
+package jv
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// onSelect is the command run whenever the selection changes, it can be set
+// in the config file
+var onSelect = ""
+
+// broadcaster hands selection changes to a command or a FIFO one at a time
+// keeping only the latest when they come faster than they are handled
+type broadcaster struct {
+	command []string    // command run with the event on its standard input
+	fifo    string      // FIFO the events are written to
+	events  chan []byte // the latest event not handled yet
+}
+
+// selectionEvent is what is broadcast when the selection changes
+type selectionEvent struct {
+	Event string   `json:"event"`
+	File  string   `json:"file,omitempty"` // the input, empty for stdin
+	Path  string   `json:"path"`           // selector of the selection such as .a[0]
+	Keys  []string `json:"keys"`           // the keys on the way to the selection
+	Value any      `json:"value"`          // the selected value
+}
+
+// newBroadcaster starts handing selection changes to the command and FIFO
+// it returns nil if there are neither
+func newBroadcaster(command, fifo string) *broadcaster {
+	if strings.TrimSpace(command) == "" && fifo == "" {
+		return nil
+	}
+	b := &broadcaster{command: strings.Fields(command), fifo: fifo, events: make(chan []byte, 1)}
+	go b.run()
+	return b
+}
+
+// send queues an event, replacing one that has not been handled yet
+func (b *broadcaster) send(event []byte) {
+	for {
+		select {
+		case b.events <- event:
+			return
+		default:
+		}
+		// drop the stale event to make room
+		select {
+		case <-b.events:
+		default:
+		}
+	}
+}
+
+// run handles the events as they come
+func (b *broadcaster) run() {
+	for event := range b.events {
+		if len(b.command) > 0 {
+			c := exec.Command(b.command[0], b.command[1:]...)
+			c.Stdin = bytes.NewReader(event)
+			if err := c.Run(); err != nil {
+				debugLog.Printf("selection command failed: %s", err)
+			}
+		}
+		if b.fifo != "" {
+			// opening blocks until the other end is being read from
+			f, err := os.OpenFile(b.fifo, os.O_WRONLY|os.O_APPEND, 0)
+			if err != nil {
+				debugLog.Printf("cannot open %s: %s", b.fifo, err)
+				continue
+			}
+			if _, err := f.Write(append(event, '\n')); err != nil {
+				debugLog.Printf("cannot write to %s: %s", b.fifo, err)
+			}
+			f.Close()
+		}
+	}
+}
+
+// broadcastSelection hands the selection to the command and FIFO
+func (m *Model) broadcastSelection(path []string) {
+	event, err := json.Marshal(selectionEvent{
+		Event: "selection",
+		File:  m.Source,
+		Path:  selectorPath(m.Data, path),
+		Keys:  path,
+		Value: m.exportValue(path),
+	})
+	if err != nil {
+		debugLog.Printf("cannot encode the selection: %s", err)
+		return
+	}
+	m.broadcast.send(event)
+}
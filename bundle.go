@@ -0,0 +1,126 @@
+package jv
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tag is a value picked to be exported along with the others tagged, such
+// as the pieces of evidence from a sprawling document
+type tag struct {
+	Path []string // path of the value
+	Name string   // key of the value in the bundle, its path unless another name was given
+}
+
+// tagAt returns the tag of the value at path, -1 if it is not tagged
+func (m *Model) tagAt(path []string) int {
+	for i, t := range m.Tags {
+		if pathKey(t.Path) == pathKey(path) {
+			return i
+		}
+	}
+	return -1
+}
+
+// openTag starts typing the name to tag the value under the cursor with,
+// its path to begin with, or untags it if it is tagged already
+func (m *Model) openTag() {
+	if len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return
+	}
+	path := m.currentPath()
+	if i := m.tagAt(path); i >= 0 {
+		m.Tags = append(m.Tags[:i], m.Tags[i+1:]...)
+		m.rowCache = nil
+		m.Status = fmt.Sprintf("untagged %s, %d tagged", selectorPath(m.Data, path), len(m.Tags))
+		return
+	}
+	m.Tagging, m.TagInput = true, selectorPath(m.Data, path)
+}
+
+// updateTag handles typing the name of the value being tagged
+func (m *Model) updateTag(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.Tagging = false
+	case tea.KeyEnter:
+		name := strings.TrimSpace(m.TagInput)
+		if name == "" {
+			m.Status = "a tag needs a name"
+			return m, nil
+		}
+		for _, t := range m.Tags {
+			if t.Name == name {
+				m.Status = fmt.Sprintf("%s already tags %s", name, selectorPath(m.Data, t.Path))
+				return m, nil
+			}
+		}
+		m.Tagging = false
+		m.Tags = append(m.Tags, tag{Path: m.currentPath(), Name: name})
+		m.rowCache = nil
+		m.Status = fmt.Sprintf("tagged %s, %s exports the %d tagged values", sanitize(name), m.Keys.ExportTags.Help().Key, len(m.Tags))
+	case tea.KeyBackspace:
+		if len(m.TagInput) > 0 {
+			runes := []rune(m.TagInput)
+			m.TagInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes:
+		m.TagInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// tagNote returns the name the value at path is tagged with, to show after
+// its value
+func (m *Model) tagNote(path []string) string {
+	i := m.tagAt(path)
+	if i < 0 {
+		return ""
+	}
+	return "  " + style("(tagged as "+sanitize(m.Tags[i].Name)+")", styleFaint)
+}
+
+// exportTags shows the tagged values as one JSON object keyed by their names
+// in the detail pane, which writes it to a file
+// the values are in the order they were tagged and secrets are masked as
+// they are on screen, values no longer in the input after a reload are
+// left out
+func (m *Model) exportTags() {
+	if len(m.Tags) == 0 {
+		m.Status = fmt.Sprintf("nothing tagged, %s tags the value under the cursor", m.Keys.Tag.Help().Key)
+		return
+	}
+	var b strings.Builder
+	b.WriteString("{")
+	written, missing := 0, 0
+	for _, t := range m.Tags {
+		if m.checkPath(t.Path) != nil {
+			missing++
+			continue
+		}
+		value, err := indentedJson(m.exportValue(t.Path))
+		if err != nil {
+			m.Status = fmt.Sprintf("cannot export %s: %s", selectorPath(m.Data, t.Path), err)
+			return
+		}
+		name, _ := jsonScalar(t.Name)
+		if written > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  " + string(name) + ": " + strings.ReplaceAll(value, "\n", "\n  "))
+		written++
+	}
+	b.WriteString("\n}")
+	if written == 0 {
+		b.Reset()
+		b.WriteString("{}")
+	}
+	m.openDetail(fmt.Sprintf("Bundle of %d tagged values", written), b.String(), "bundle.json")
+	if missing > 0 {
+		m.Detail.Status = fmt.Sprintf("%d tagged values are no longer in the input and are left out", missing)
+	}
+}
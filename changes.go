@@ -0,0 +1,186 @@
+package jv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// changesMsg carries the differences between the document before and after
+// reloading
+type changesMsg struct {
+	Changes []change
+}
+
+// summaryTimeout is how long the summary of a reload is shown for
+const summaryTimeout = 5 * time.Second
+
+// summaryMsg clears the summary of a reload unless something else has been
+// shown since
+type summaryMsg struct {
+	Status string
+}
+
+// snapshot is a utility function that copies a document so that it can be
+// compared with the input once it has changed, since files are mapped into
+// memory and what they held is gone once they are written over
+// streamed documents are too large to keep a copy of so it returns nil
+func snapshot(data any) any {
+	switch v := data.(type) {
+	case json.RawMessage:
+		return append(json.RawMessage{}, v...)
+	case fileSpan:
+		debugLog.Printf("not keeping a copy of the streamed input to compare with")
+		return nil
+	}
+	return materialize(data)
+}
+
+// diffReload compares the document from before reloading with the one
+// loaded in its place in the background
+func (m *Model) diffReload(before any) tea.Cmd {
+	after := m.Data
+	return m.startTask("changes", "comparing with the last load", func(ctx context.Context, _ func(int)) tea.Msg {
+		changes := diffValues(materialize(before), materialize(after))
+		if ctx.Err() != nil {
+			return nil
+		}
+		return changesMsg{Changes: changes}
+	})
+}
+
+// changeKeys is a utility function that returns the path of a change with
+// array indices written the way paths in the listing are
+func changeKeys(c change) []string {
+	path := make([]string, len(c.Path))
+	for i, k := range c.Path {
+		path[i] = fmt.Sprint(k)
+	}
+	return path
+}
+
+// finishDiffReload marks what has changed since the last load in the listing
+// values that were added or changed are marked with + or ~ and the
+// containers holding them with •, removed values are only counted
+// a summary of the changes is shown for a few seconds
+func (m *Model) finishDiffReload(msg changesMsg) tea.Cmd {
+	m.Changes, m.changeAt = msg.Changes, -1
+	m.changed = map[string]byte{}
+	for _, c := range m.Changes {
+		path := changeKeys(c)
+		if c.Kind != changeRemoved {
+			m.changed[pathKey(path)] = c.Kind
+		}
+		for i := len(path) - 1; i > 0; i-- {
+			if _, ok := m.changed[pathKey(path[:i])]; !ok {
+				m.changed[pathKey(path[:i])] = 0
+			}
+		}
+	}
+	m.rowCache = nil
+	m.Status = changeSummary(m.Changes)
+	if len(m.Changes) > 0 {
+		m.Status += fmt.Sprintf("  (%s lists them, %s goes to the next)", m.Keys.Changes.Help().Key, m.Keys.NextChange.Help().Key)
+	}
+	status := m.Status
+	return tea.Tick(summaryTimeout, func(time.Time) tea.Msg {
+		return summaryMsg{Status: status}
+	})
+}
+
+// changeSummary is a utility function that counts the changes of each kind
+// such as reloaded: 12 paths changed, 3 added, 1 removed
+func changeSummary(changes []change) string {
+	if len(changes) == 0 {
+		return "reloaded, nothing has changed"
+	}
+	counts := map[byte]int{}
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+	parts := []string{}
+	for _, kind := range []struct {
+		kind byte
+		verb string
+	}{{changeChanged, "changed"}, {changeAdded, "added"}, {changeRemoved, "removed"}} {
+		n := counts[kind.kind]
+		if n == 0 {
+			continue
+		}
+		if len(parts) == 0 {
+			noun := "paths"
+			if n == 1 {
+				noun = "path"
+			}
+			parts = append(parts, fmt.Sprintf("%d %s %s", n, noun, kind.verb))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", n, kind.verb))
+	}
+	return "reloaded: " + strings.Join(parts, ", ")
+}
+
+// clearSummary hides the summary of a reload once it has been shown long
+// enough, leaving anything shown after it
+func (m *Model) clearSummary(msg summaryMsg) {
+	if m.Status == msg.Status {
+		m.Status = ""
+	}
+}
+
+// listChanges shows every change since the last load in the detail pane
+func (m *Model) listChanges() {
+	if m.Changes == nil {
+		m.Status = "nothing to compare with yet, changes are listed after reloading"
+		return
+	}
+	if len(m.Changes) == 0 {
+		m.Status = "nothing has changed since the last load"
+		return
+	}
+	m.openDetail(changeSummary(m.Changes), changesString(m.maskChanges(m.Changes)), "changes.txt")
+}
+
+// changeMark returns how the value at path has changed since the last load,
+// or "" if it has not
+func (m *Model) changeMark(path []string) string {
+	kind, ok := m.changed[pathKey(path)]
+	switch {
+	case !ok:
+		return ""
+	case kind == 0:
+		return "•"
+	}
+	return string(kind)
+}
+
+// nextChange goes to the next value that changed since the last load,
+// starting over from the first once the last has been shown
+// removed values are shown by going to where they were
+func (m *Model) nextChange() {
+	if m.Changes == nil {
+		m.Status = "nothing to compare with yet, changes are marked after reloading"
+		return
+	}
+	if len(m.Changes) == 0 {
+		m.Status = "nothing has changed since the last load"
+		return
+	}
+	m.changeAt = (m.changeAt + 1) % len(m.Changes)
+	c := m.Changes[m.changeAt]
+	path := changeKeys(c)
+	// a removed value is gone so its container is shown instead
+	found := m.existingDepth(path)
+	m.Flat, m.Filter = false, ""
+	if found == 0 {
+		m.goTo(nil)
+	} else {
+		m.showKey(path[:found])
+	}
+	line := changesString(m.maskChanges([]change{c}))
+	m.Status = fmt.Sprintf("change %d/%d: %s", m.changeAt+1, len(m.Changes), strings.TrimSpace(line))
+}
@@ -0,0 +1,442 @@
+package jv
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// exit codes
+const (
+	exitParseError  = 1 // the input is not valid JSON
+	exitEmptyInput  = 2 // the input is empty
+	exitSchemaError = 3 // the input does not match the schema given with -check
+	exitError       = 4 // anything else went wrong
+	exitCrash       = 5 // jv crashed, a crash report is printed
+)
+
+// version is set at build time with -ldflags "-X github.com/nishakm/jv.version=..."
+var version = "dev"
+
+// getVersion returns the version of the program, falling back to the
+// module version when it was installed with go install
+func getVersion() string {
+	if version == "dev" {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return info.Main.Version
+		}
+	}
+	return version
+}
+
+// fail prints an error and exits with the given code
+func fail(code int, err error) {
+	debugLog.Printf("exiting with code %d: %s", code, err)
+	fmt.Fprintf(os.Stderr, "jv: %s\n", err)
+	os.Exit(code)
+}
+
+// exitCode returns the exit code for an error loading the input
+func exitCode(err error) int {
+	var serr *SyntaxError
+	switch {
+	case errors.As(err, &serr):
+		return exitParseError
+	case errors.Is(err, errEmptyInput):
+		return exitEmptyInput
+	}
+	return exitError
+}
+
+// check validates the input against the schema without starting the
+// interface, printing every violation and exiting with exitSchemaError
+// if there are any
+func check(path string, git bool, schema any) {
+	data, _, err := readJsonSource(context.Background(), &loadProgress{}, path, git)
+	if err != nil {
+		fail(exitCode(err), err)
+	}
+	violations := validateSchema(schema, data)
+	for _, v := range violations {
+		fmt.Printf("%s: %s\n", selectorPath(data, v.Path), v.Message)
+	}
+	if len(violations) > 0 {
+		fail(exitSchemaError, fmt.Errorf("%d schema violations", len(violations)))
+	}
+}
+
+// Main runs the jv command with the command line arguments
+func Main() {
+	// the config file, the profile picked and then the environment set the
+	// defaults that the flags start from
+	cfg, err := loadConfig(configPath())
+	if err != nil {
+		fail(exitError, err)
+	}
+	// jv serve takes the same flags and serves the viewer to browsers,
+	// jv bench times loading the input through each backend and jv check
+	// is the same as -check, jv keys prints every key binding with the
+	// changes in the config file and the profile
+	args := os.Args[1:]
+	serving := len(args) > 0 && args[0] == "serve"
+	benching := len(args) > 0 && args[0] == "bench"
+	checking := len(args) > 0 && args[0] == "check"
+	keying := len(args) > 0 && args[0] == "keys"
+	if serving || benching || checking || keying {
+		args = args[1:]
+	}
+	profile := profileArg(args)
+	if profile != "" {
+		if err := cfg.useProfile(profile); err != nil {
+			fail(exitError, err)
+		}
+	}
+	// without a profile picked one can be detected from the input later on
+	fileCfg := cfg
+	if err := cfg.setEnv(); err != nil {
+		fail(exitError, err)
+	}
+	cfg.apply()
+
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: jv [flags] [file or url]\n")
+		fmt.Fprintf(out, "       jv serve [flags] [file or url]\n")
+		fmt.Fprintf(out, "       jv bench [-runs n] file\n")
+		fmt.Fprintf(out, "       jv check -schema schema.json | -openapi spec.yaml -operation id [file or url]\n")
+		fmt.Fprintf(out, "       jv keys [-profile name]\n")
+		fmt.Fprintf(out, "       jv completion %s\n\n", strings.Join(shells, "|"))
+		fmt.Fprintf(out, "jv explores JSON from a file, an http(s) URL, an s3:// or gs:// object,\n")
+		fmt.Fprintf(out, "a file in an archive such as bundle.zip:config.json, the output of exec:command,\n")
+		fmt.Fprintf(out, "clipboard: or from standard input if none or - is given.\n")
+		fmt.Fprintf(out, "jv serve shows it read-only in a browser instead, at the -listen address.\n")
+		fmt.Fprintf(out, "jv bench reports how long the file takes to parse and show and the memory it takes\n")
+		fmt.Fprintf(out, "with each way of parsing it, eager, lazy and streaming.\n")
+		fmt.Fprintf(out, "jv check prints where the input does not match the schema, like -check, for CI.\n")
+		fmt.Fprintf(out, "jv keys prints every key binding as it is set in the config file, like ? does.\n")
+		fmt.Fprintf(out, "Piped into another program jv prints the value under the cursor once it is closed,\n")
+		fmt.Fprintf(out, "or the value at -path when there is no terminal to show the viewer on.\n\n")
+		fmt.Fprintf(out, "Flags:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(out, "\nDefaults are read from the config file %s if it exists, JV_CONFIG changes where it is.\n", configPath())
+		fmt.Fprintf(out, "The environment variables %s override them and the flags override both.\n", strings.Join(envNames(), ", "))
+		fmt.Fprintf(out, "\nThe exit status is 0 on success, %d if the input is not valid JSON, %d if it is empty,\n", exitParseError, exitEmptyInput)
+		fmt.Fprintf(out, "%d if it does not match the schema with -check or jv check and %d for any other error.\n", exitSchemaError, exitError)
+	}
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	flag.BoolVar(&checkUpdates, "check-updates", checkUpdates, "look up the latest release on GitHub when the version is shown with A")
+	noColor := flag.Bool("no-color", !colorAllowed(), "show the listing without styling, also set by NO_COLOR")
+	inline := flag.Bool("inline", false, "run in the terminal rather than a screen of its own, so the last view stays in the scrollback on exit")
+	metrics := flag.Bool("metrics", cfg.Metrics, "show performance metrics while running")
+	order := flag.String("sort", cfg.Sort, "order to list object keys in: source, natural, key or case")
+	pins := flag.String("pin", cfg.Pin, "comma separated keys to list first in every object, such as name,status,error")
+	conceal := flag.String("conceal", cfg.Conceal, "comma separated keys to hide from the listing such as managedFields,metadata.annotations, * matches any run of characters")
+	debugPath := flag.String("debug", "", "write a debug log to this file")
+	schemaPath := flag.String("schema", "", "JSON Schema file to check the input against")
+	openAPIPath := flag.String("openapi", "", "OpenAPI spec in JSON or YAML whose response schema for the -operation the input is checked against")
+	operation := flag.String("operation", "", "operationId in the -openapi spec, such as getPets")
+	flag.Var((*headerList)(&fetchOptions.Headers), "header", "header to send when fetching a URL, such as 'Authorization: Bearer …', can be repeated")
+	flag.StringVar(&fetchOptions.User, "user", "", "user:password to send with basic auth when fetching a URL")
+	flag.StringVar(&fetchOptions.CACert, "cacert", "", "PEM file of certificate authorities to trust when fetching a URL")
+	flag.StringVar(&fetchOptions.Proxy, "proxy", "", "proxy to fetch URLs through, HTTPS_PROXY and HTTP_PROXY are used otherwise")
+	gitSpec := flag.String("git", "", "read the input from a git object such as HEAD~1:config.json instead of a file")
+	controlPath := flag.String("control", "", "listen for commands on a unix socket at this path, for scripts and editors")
+	flag.StringVar(&onSelect, "on-select", onSelect, "command to run with the selected path and value as JSON on its standard input whenever the selection changes")
+	flag.BoolVar(&tracePaths, "trace-paths", tracePaths, "print every path visited once the viewer is closed, to take notes or to go back with -path")
+	flag.BoolVar(&watchInput, "watch", watchInput, "reload the input whenever it changes, going back to the same place in it")
+	recent := flag.Bool("recent", false, "pick an input opened before to open")
+	noRestore := flag.Bool("no-restore", false, "open the input at the top instead of where it was left last time")
+	flag.StringVar(&crashPath, "crash-report", "", "write a report to this file if jv crashes, to attach to a bug report")
+	flag.StringVar(&auditPath, "audit-log", auditPath, "append every path viewed, copied or exported to this file with the time, leaving the values out")
+	selectFifo := flag.String("select-fifo", "", "FIFO to write the selected path and value to as a line of JSON whenever the selection changes")
+	listen := flag.String("listen", "localhost:8080", "address jv serve listens on, such as :8080 to share it with other machines")
+	checkOnly := flag.Bool("check", false, "check the input against the -schema or -openapi spec without opening the viewer")
+	runs := flag.Int("runs", 3, "times jv bench loads the file with each backend, the fastest run is reported")
+	flag.String("profile", profile, "named group of settings from the config file to use, also set by JV_PROFILE, otherwise picked by the keys of the input listed in the [detect] table")
+	start := flag.String("path", cfg.Path, "path to open the listing at, such as .spec.containers[0]")
+	flag.BoolVar(&renderValues, "render", renderValues, "show timestamps, UUIDs, colours, URLs and base64 in a readable form next to them")
+	zone := flag.String("time-zone", cfg.Zone, "time zone to show timestamps in, such as UTC or Europe/Berlin, the local one by default")
+	flag.StringVar(&timeFormat, "time-format", timeFormat, "format to show timestamps in: local, rfc3339 or relative")
+	flag.BoolVar(&detectIDs, "detect-ids", detectIDs, "recognise UUIDs, digests and colours, shortening UUIDs and digests but on the selected row")
+	flag.BoolVar(&wrapRows, "wrap", wrapRows, "wrap long rows of the listing onto more lines rather than cutting them short")
+	flag.BoolVar(&lineNumbers, "line-numbers", lineNumbers, "show the index of every row within its array or object in a gutter")
+	flag.BoolVar(&accessible, "accessible", accessible, "draw for screen readers: ASCII instead of arrows and box drawing, the row under the cursor described in a line and fewer redraws")
+	noMouse := flag.Bool("no-mouse", !mouseEnabled, "leave the mouse to the terminal so that text can be selected with it")
+	flag.StringVar(&mouseClick, "mouse-click", mouseClick, "what clicking a row does: select moves the cursor onto it, expand also expands it")
+	flag.IntVar(&wheelRows, "wheel-rows", wheelRows, "rows a turn of the mouse wheel moves")
+	flag.StringVar(&jqFilter, "jq", jqFilter, "jq filter to run the input through every time it is read, with -watch as it changes, such as '[.[] | select(.level == \"error\")]', needs jq on PATH")
+	flag.StringVar(&startView, "view", startView, "view to open the input in: listing, outline beside the listing, table if it is an array of objects, or flat leaves")
+	flag.IntVar(&outlineDepth, "outline-depth", outlineDepth, "levels of the document the outline lists: 1 for the top-level keys, 2 for their children as well")
+	flag.BoolVar(&maskSecrets, "mask-secrets", maskSecrets, "mask likely passwords, tokens and keys in the listing and in copies, for sharing the screen")
+	flag.IntVar(&previewDepth, "depth", previewDepth, "levels of objects and arrays to preview inline in the listing")
+	flag.IntVar(&previewItems, "preview-items", previewItems, "children of each object and array to preview inline, 0 for no limit")
+	flag.IntVar(&previewLength, "preview-length", previewLength, "longest inline preview in characters before it is cut short, 0 for no limit")
+	flag.StringVar(&inputFormat, "format", inputFormat, "format of the input: auto, json or gron")
+	flag.BoolVar(&lenient, "lenient", lenient, "accept NaN, Infinity, single quoted strings and unquoted keys")
+	flag.IntVar(&limits.Depth, "max-depth", limits.Depth, "deepest nesting level to expand, 0 for no limit")
+	flag.IntVar(&limits.KeyLen, "max-key", limits.KeyLen, "longest key in bytes to show in full, 0 for no limit")
+	flag.IntVar(&limits.ValueLen, "max-value", limits.ValueLen, "longest value in bytes to show in full, 0 for no limit")
+	// jv completion <shell> prints a completion script for the flags above
+	if len(os.Args) > 1 && os.Args[1] == "completion" {
+		if len(os.Args) != 3 {
+			fail(exitError, fmt.Errorf("usage: jv completion %s", strings.Join(shells, "|")))
+		}
+		script, err := completionScript(os.Args[2], flag.CommandLine)
+		if err != nil {
+			fail(exitError, err)
+		}
+		fmt.Print(script)
+		return
+	}
+	flag.CommandLine.Parse(args)
+
+	if *showVersion {
+		fmt.Printf("jv %s\n", getVersion())
+		return
+	}
+	if keying {
+		fmt.Println(cfg.Keys.cheatsheet())
+		return
+	}
+	// the first argument is an optional path to a JSON file or a URL
+	if flag.NArg() > 1 {
+		flag.Usage()
+		fail(exitError, fmt.Errorf("expected at most one file but got %d", flag.NArg()))
+	}
+	path := flag.Arg(0)
+	if *gitSpec != "" {
+		if path != "" {
+			fail(exitError, fmt.Errorf("expected a file or -git but got both"))
+		}
+		path = *gitSpec
+	}
+	if *recent && path != "" {
+		fail(exitError, fmt.Errorf("expected a file or -recent but got both"))
+	}
+	useColor = !*noColor
+	restoreSessions = !*noRestore
+	pinnedKeys = splitList(*pins)
+	concealPatterns = splitList(*conceal)
+	if err := checkOrder(*order); err != nil {
+		fail(exitError, err)
+	}
+	if err := checkTimeFormat(timeFormat); err != nil {
+		fail(exitError, err)
+	}
+	if err := checkMouseClick(mouseClick); err != nil {
+		fail(exitError, err)
+	}
+	if wheelRows < 1 {
+		fail(exitError, fmt.Errorf("expected -wheel-rows to be at least 1 but got %d", wheelRows))
+	}
+	if err := checkOutlineDepth(outlineDepth); err != nil {
+		fail(exitError, err)
+	}
+	if err := checkView(startView); err != nil {
+		fail(exitError, err)
+	}
+	mouseEnabled = !*noMouse
+	if timeZone, err = loadTimeZone(*zone); err != nil {
+		fail(exitError, err)
+	}
+	switch inputFormat {
+	case formatAuto, formatJson, formatGron:
+	default:
+		fail(exitError, fmt.Errorf("unknown input format %q, expected auto, json or gron", inputFormat))
+	}
+	// a detected profile applies over the config file like a picked one
+	// does, under the environment and the flags given
+	if profile == "" && len(detectRules) > 0 {
+		given := map[string]string{}
+		flag.Visit(func(f *flag.Flag) {
+			// headers add up so they are not given again
+			if f.Name != "header" {
+				given[f.Name] = f.Value.String()
+			}
+		})
+		useDetected = func(name string) (string, error) {
+			c := fileCfg
+			c.Formats = map[string]string{}
+			for pattern, format := range fileCfg.Formats {
+				c.Formats[pattern] = format
+			}
+			c.Redact = map[string]string{}
+			for pattern, action := range fileCfg.Redact {
+				c.Redact[pattern] = action
+			}
+			if err := c.useProfile(name); err != nil {
+				return "", err
+			}
+			if err := c.setEnv(); err != nil {
+				return "", err
+			}
+			c.apply()
+			*order, *pins, *conceal, *zone = c.Sort, c.Pin, c.Conceal, c.Zone
+			for name, val := range given {
+				flag.Set(name, val)
+			}
+			pinnedKeys, concealPatterns = splitList(*pins), splitList(*conceal)
+			if z, err := loadTimeZone(*zone); err == nil {
+				timeZone = z
+			}
+			return *order, nil
+		}
+	}
+	var startPath []string
+	if *start != "" {
+		if startPath, err = selectorKeys(*start); err != nil {
+			fail(exitError, fmt.Errorf("invalid path %s: %w", *start, err))
+		}
+	}
+
+	var schema any
+	switch {
+	case *schemaPath != "" && *openAPIPath != "":
+		fail(exitError, fmt.Errorf("expected a -schema or an -openapi spec but got both"))
+	case *schemaPath != "":
+		if schema, err = loadSchema(*schemaPath); err != nil {
+			fail(exitError, err)
+		}
+	case *openAPIPath != "":
+		if *operation == "" {
+			fail(exitError, fmt.Errorf("-openapi needs the -operation whose response to check against"))
+		}
+		if schema, err = loadOpenAPI(*openAPIPath, *operation); err != nil {
+			fail(exitError, err)
+		}
+	case *checkOnly || checking:
+		fail(exitError, fmt.Errorf("checking needs a -schema or an -openapi spec to check against"))
+	}
+
+	if *debugPath != "" {
+		f, err := openDebugLog(*debugPath)
+		if err != nil {
+			fail(exitError, err)
+		}
+		defer f.Close()
+		debugLog.Printf("starting jv %s with %q", getVersion(), os.Args[1:])
+	}
+
+	if *checkOnly || checking {
+		check(path, *gitSpec != "", schema)
+		return
+	}
+
+	if benching {
+		if err := bench(os.Stdout, path, *runs); err != nil {
+			fail(exitCode(err), err)
+		}
+		return
+	}
+
+	if serving {
+		if err := serve(*listen, path, *gitSpec != "", *order); err != nil {
+			fail(exitCode(err), err)
+		}
+		return
+	}
+
+	if auditPath != "" {
+		if err := openAuditLog(auditPath); err != nil {
+			fail(exitError, err)
+		}
+		defer auditLog.Close()
+	}
+
+	// with standard output piped the viewer is drawn on the terminal and the
+	// value under the cursor is printed once it is closed, without a
+	// terminal the input is printed as it is
+	piped := stdoutPiped()
+	var tty *os.File
+	if piped {
+		if tty, err = os.OpenFile("/dev/tty", os.O_RDWR, 0); err != nil {
+			if err := printValue(os.Stdout, path, *gitSpec != "", startPath); err != nil {
+				fail(exitCode(err), err)
+			}
+			return
+		}
+		defer tty.Close()
+	}
+
+	model := NewModel(path)
+	model.Schema = schema
+	model.Git = *gitSpec != ""
+	model.broadcast = newBroadcaster(onSelect, *selectFifo)
+	model.Sort = *order
+	model.Keys = cfg.Keys
+	model.Start = startPath
+	if *metrics {
+		model.Metrics = &Metrics{}
+	}
+	// the recent inputs are shown instead of loading standard input
+	if *recent {
+		model.openRecent()
+		if model.Recent == nil {
+			fail(exitError, errors.New(model.Status))
+		}
+		model.Loading = false
+	}
+
+	// a crash in the workspace stops the program through ctx so that the
+	// terminal is restored before the crash report is printed
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	w := NewWorkspace(model)
+	w.stop = stop
+	options := []tea.ProgramOption{
+		tea.WithContext(ctx),
+	}
+	if mouseEnabled {
+		options = append(options, tea.WithMouseCellMotion()) // takes mouse input
+	}
+	// inline the last view is left on the terminal once jv exits
+	if !*inline {
+		options = append(options, tea.WithAltScreen()) // opens up a new terminal screen
+	}
+	if tty != nil {
+		options = append(options, tea.WithOutput(tty))
+	}
+	p := tea.NewProgram(w, options...)
+
+	var control net.Listener
+	if *controlPath != "" {
+		removeSocket(*controlPath)
+		if control, err = serveControl(*controlPath, p); err != nil {
+			fail(exitError, err)
+		}
+	}
+
+	_, err = p.Run()
+	// closing the listener removes the socket, which exiting would not
+	if control != nil {
+		control.Close()
+	}
+	if w.crash != nil {
+		reportCrash(w.crash)
+		os.Exit(exitCrash)
+	}
+	for _, d := range w.Docs {
+		d.Model.saveSession()
+	}
+	if tracePaths {
+		printVisits(os.Stdout, w.Docs)
+	}
+	if err != nil {
+		fail(exitError, err)
+	}
+	if piped {
+		if err := printSelection(os.Stdout, w.Current()); err != nil {
+			fail(exitError, err)
+		}
+	}
+	if m := w.Current(); m != nil && m.Err != nil {
+		fail(exitCode(m.Err), m.Err)
+	}
+}
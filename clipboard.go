@@ -0,0 +1,207 @@
+package jv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clipboardMsg is sent once something has been copied
+type clipboardMsg struct {
+	Status string
+}
+
+// nativeClipboard is a utility function that returns the command that
+// copies its standard input to the clipboard of this machine, or nil if
+// there is none
+// over ssh the clipboard of this machine is not the one in front of the
+// user so there is none then
+func nativeClipboard() []string {
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		return nil
+	}
+	candidates := [][]string{}
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = append(candidates, []string{"pbcopy"})
+	case "windows":
+		candidates = append(candidates, []string{"clip.exe"})
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		if os.Getenv("DISPLAY") != "" {
+			candidates = append(candidates,
+				[]string{"xclip", "-selection", "clipboard"},
+				[]string{"xsel", "--clipboard", "--input"})
+		}
+	}
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err == nil {
+			return args
+		}
+	}
+	return nil
+}
+
+// osc52 is a utility function that returns the escape sequence asking the
+// terminal to put s on its clipboard, which works across ssh
+// tmux only passes the sequence on to the terminal when it is wrapped
+func osc52(s string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(s)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	return seq
+}
+
+// copyCmd copies s to the clipboard using the native clipboard when there
+// is one and the terminal otherwise, what describes s in the status
+func copyCmd(s, what string) tea.Cmd {
+	return func() tea.Msg {
+		if args := nativeClipboard(); args != nil {
+			c := exec.Command(args[0], args[1:]...)
+			c.Stdin = bytes.NewReader([]byte(s))
+			err := c.Run()
+			if err == nil {
+				return clipboardMsg{Status: fmt.Sprintf("copied %s", what)}
+			}
+			debugLog.Printf("cannot copy with %s: %s", args[0], err)
+		}
+		var tty io.Writer = os.Stderr
+		if f, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+			defer f.Close()
+			tty = f
+		}
+		if _, err := io.WriteString(tty, osc52(s)); err != nil {
+			return clipboardMsg{Status: fmt.Sprintf("cannot copy %s: %s", what, err)}
+		}
+		return clipboardMsg{Status: fmt.Sprintf("copied %s through the terminal", what)}
+	}
+}
+
+// clipboardValue is a utility function that returns the text copied for
+// a value, strings without their quotes and anything else as compact JSON
+func clipboardValue(o any) string {
+	o = materialize(o)
+	if s, ok := o.(string); ok {
+		return s
+	}
+	content, err := json.Marshal(o)
+	if err != nil {
+		return getVal(o)
+	}
+	return string(content)
+}
+
+// escapedValue is a utility function that returns a value as compact JSON
+// quoted as a JSON string literal, to embed in another document or in code
+func escapedValue(o any) (string, error) {
+	// HTML characters are kept as they are rather than escaped as \u003c
+	compact := func(v any) (string, error) {
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(b.String(), "\n"), nil
+	}
+	content, err := compact(materialize(o))
+	if err != nil {
+		return "", fmt.Errorf("cannot format the value: %w", err)
+	}
+	return compact(content)
+}
+
+// shellQuote is a utility function that quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// requestCommands turn a JSON body into a command line that sends it, the
+// URL is left to $URL
+var requestCommands = map[string]func(body string) string{
+	"curl": func(body string) string {
+		return fmt.Sprintf("printf '%%s' %s | curl -X POST -H 'Content-Type: application/json' -d @- \"$URL\"", shellQuote(body))
+	},
+	"HTTPie": func(body string) string {
+		return fmt.Sprintf("printf '%%s' %s | http POST \"$URL\"", shellQuote(body))
+	},
+}
+
+// requestCommand is a utility function that returns the command that sends
+// a value as the compact JSON body of a request with the given tool
+func requestCommand(tool string, o any) (string, error) {
+	body, err := json.Marshal(materialize(o))
+	if err != nil {
+		return "", fmt.Errorf("cannot format the body: %w", err)
+	}
+	return requestCommands[tool](string(body)), nil
+}
+
+// levelPaths returns the paths of the children of the current level in the
+// order they are listed, every element of an array rather than only the
+// ones loaded so far
+func (m *Model) levelPaths() [][]string {
+	paths := [][]string{}
+	if isArray(m.node()) {
+		keys, _ := orderedChildren(m.node())
+		for _, k := range keys {
+			paths = append(paths, append(append([]string{}, m.Path...), k))
+		}
+		return paths
+	}
+	for _, kv := range m.CurrKV {
+		if !kv.More {
+			paths = append(paths, m.currentPathOf(kv))
+		}
+	}
+	return paths
+}
+
+// copyLevelKeys copies the keys of the current level one per line, for
+// pasting field names into code or allowlists
+func (m *Model) copyLevelKeys() tea.Cmd {
+	if m.Flat || isArray(m.node()) {
+		m.Status = "only the keys of an object can be copied"
+		return nil
+	}
+	keys := []string{}
+	for _, p := range m.levelPaths() {
+		keys = append(keys, p[len(p)-1])
+	}
+	return m.copyValue(m.Path, strings.Join(keys, "\n"),
+		fmt.Sprintf("%d keys of %s", len(keys), selectorPath(m.Data, m.Path)))
+}
+
+// copyLevelValues copies the values of the current level that are not
+// objects or arrays one per line, strings without their quotes and
+// secrets masked if they are
+func (m *Model) copyLevelValues() tea.Cmd {
+	if m.Flat {
+		return nil
+	}
+	values := []string{}
+	for _, p := range m.levelPaths() {
+		switch v := m.exportValue(p); v.(type) {
+		case map[string]any, []any:
+		default:
+			values = append(values, clipboardValue(v))
+		}
+	}
+	if len(values) == 0 {
+		m.Status = "there are no values here that are not objects or arrays"
+		return nil
+	}
+	return m.copyValue(m.Path, strings.Join(values, "\n"),
+		fmt.Sprintf("%d values of %s", len(values), selectorPath(m.Data, m.Path)))
+}
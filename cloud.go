@@ -0,0 +1,85 @@
+package jv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// objectCommands are the cloud CLIs that write an object to standard output
+// keyed by the URL scheme, going through them means the credentials they are
+// already set up with are used
+var objectCommands = map[string][]string{
+	"s3://": {"aws", "s3", "cp", "", "-"},
+	"gs://": {"gcloud", "storage", "cat", ""},
+}
+
+// isObjectURL checks if the input is an object in cloud storage
+func isObjectURL(path string) bool {
+	return objectCommand(path) != nil
+}
+
+// objectCommand is a utility function that returns the command that fetches
+// the object, or nil if the path is not a cloud storage URL
+func objectCommand(path string) []string {
+	for scheme, template := range objectCommands {
+		if !strings.HasPrefix(path, scheme) {
+			continue
+		}
+		args := make([]string, len(template))
+		for i, arg := range template {
+			if arg == "" {
+				arg = path
+			}
+			args[i] = arg
+		}
+		return args
+	}
+	return nil
+}
+
+// objectSource reads an object in cloud storage through the cloud's CLI
+type objectSource struct{}
+
+// Detect reports whether the path is an object in cloud storage
+func (objectSource) Detect(path string) bool {
+	return isObjectURL(path)
+}
+
+// Open runs the CLI that writes the object to standard output
+func (objectSource) Open(ctx context.Context, path string) (io.ReadCloser, int64, error) {
+	args := objectCommand(path)
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil, 0, fmt.Errorf("cannot load %s: the %s CLI is needed for its credentials: %w", path, args[0], err)
+	}
+	debugLog.Printf("fetching %s with %q", path, args)
+	return startCommand(ctx, args)
+}
+
+// Watch cannot tell when an object changes
+func (objectSource) Watch(ctx context.Context, path string) (<-chan struct{}, error) {
+	return nil, errNoWatch
+}
+
+// gunzip is a utility function that decompresses gzipped content and
+// returns anything else as it is
+func gunzip(content []byte) ([]byte, error) {
+	if !bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		return content, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress: %w", err)
+	}
+	defer zr.Close()
+	content, err = io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress: %w", err)
+	}
+	debugLog.Printf("decompressed to %s", humanBytes(int64(len(content))))
+	return content, nil
+}
@@ -0,0 +1,10 @@
+// jv is a terminal viewer for JSON
+// the viewer itself is the github.com/nishakm/jv package so that other
+// bubbletea programs can embed it
+package main
+
+import "github.com/nishakm/jv"
+
+func main() {
+	jv.Main()
+}
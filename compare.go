@@ -0,0 +1,51 @@
+package jv
+
+import "fmt"
+
+// shared is what the documents of a workspace have in common
+// a model on its own has a shared of its own
+type shared struct {
+	Mark *mark // subtree marked to compare with, nil if there is none
+}
+
+// mark is a subtree kept to compare other subtrees with
+type mark struct {
+	Label string // where the subtree is, such as deploy.json .spec
+	Value any    // the subtree fully parsed so that it outlives its document
+}
+
+// subtreeLabel names the value under the cursor along with the input it
+// is in, so that subtrees from different documents can be told apart
+func (m *Model) subtreeLabel() string {
+	source := m.Source
+	if source == "" {
+		source = "stdin"
+	}
+	return sanitize(source + " " + selectorPath(m.Data, m.currentPath()))
+}
+
+// markSubtree keeps the value under the cursor to compare other values with,
+// in this document or any other open in the workspace
+func (m *Model) markSubtree() {
+	if len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return
+	}
+	m.shared.Mark = &mark{Label: m.subtreeLabel(), Value: materialize(m.currentNode())}
+	m.Status = fmt.Sprintf("marked %s, %s compares another value with it", m.shared.Mark.Label, m.Keys.Compare.Help().Key)
+}
+
+// compareWithMark shows the structural differences between the marked
+// value and the value under the cursor in the detail pane
+func (m *Model) compareWithMark() {
+	if len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return
+	}
+	mk := m.shared.Mark
+	if mk == nil {
+		m.Status = fmt.Sprintf("nothing marked, %s marks the value to compare with", m.Keys.Mark.Help().Key)
+		return
+	}
+	changes := diffValues(mk.Value, materialize(m.currentNode()))
+	m.openDetail(fmt.Sprintf("%d differences from %s to %s", len(changes), mk.Label, m.subtreeLabel()),
+		changesString(m.maskChanges(changes)), "diff.txt")
+}
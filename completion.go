@@ -0,0 +1,149 @@
+package jv
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// shells that completion scripts can be generated for
+var shells = []string{"bash", "zsh", "fish"}
+
+// flagChoices lists the values that flags taking one of a few values accept
+func flagChoices() map[string][]string {
+	return map[string][]string{
+		"sort":   orders,
+		"format": {formatAuto, formatJson, formatGron},
+	}
+}
+
+// completionFlag describes a command line flag for completion scripts
+type completionFlag struct {
+	Name    string
+	Usage   string
+	IsBool  bool     // the flag takes no value
+	Choices []string // values the flag accepts if there are only a few
+}
+
+// completionFlags is a utility function that lists the flags defined on
+// the command line in the order of their names
+func completionFlags(fs *flag.FlagSet) []completionFlag {
+	choices := flagChoices()
+	flags := []completionFlag{}
+	fs.VisitAll(func(f *flag.Flag) {
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			Name:    f.Name,
+			Usage:   f.Usage,
+			IsBool:  ok && b.IsBoolFlag(),
+			Choices: choices[f.Name],
+		})
+	})
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags
+}
+
+// completionScript is a utility function that returns a script completing
+// the flags, the completion subcommand and file names for the given shell
+func completionScript(shell string, fs *flag.FlagSet) (string, error) {
+	flags := completionFlags(fs)
+	switch shell {
+	case "bash":
+		return bashCompletion(flags), nil
+	case "zsh":
+		return zshCompletion(flags), nil
+	case "fish":
+		return fishCompletion(flags), nil
+	}
+	return "", fmt.Errorf("unknown shell %q, expected one of %s", shell, strings.Join(shells, ", "))
+}
+
+// bashCompletion returns a completion script for bash
+func bashCompletion(flags []completionFlag) string {
+	names := []string{}
+	choices := ""
+	for _, f := range flags {
+		names = append(names, "-"+f.Name)
+		if len(f.Choices) > 0 {
+			choices += fmt.Sprintf("\t\t-%s|--%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n",
+				f.Name, f.Name, strings.Join(f.Choices, " "))
+		}
+	}
+	s := "# bash completion for jv\n"
+	s += "_jv() {\n"
+	s += "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n"
+	s += "\tif [[ $COMP_CWORD -eq 2 && $prev == completion ]]; then\n"
+	s += fmt.Sprintf("\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(shells, " "))
+	s += "\t\treturn\n"
+	s += "\tfi\n"
+	s += "\tcase \"$prev\" in\n"
+	s += choices
+	s += "\tesac\n"
+	s += "\tif [[ $cur == -* ]]; then\n"
+	s += fmt.Sprintf("\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	s += "\t\treturn\n"
+	s += "\tfi\n"
+	s += "\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n"
+	s += "\tif [[ $COMP_CWORD -eq 1 ]]; then\n"
+	s += "\t\tCOMPREPLY+=($(compgen -W \"completion keys serve\" -- \"$cur\"))\n"
+	s += "\tfi\n"
+	s += "}\n"
+	s += "complete -o filenames -F _jv jv\n"
+	return s
+}
+
+// zshEscape escapes a flag description for _arguments
+func zshEscape(s string) string {
+	return strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// zshCompletion returns a completion script for zsh
+func zshCompletion(flags []completionFlag) string {
+	s := "#compdef jv\n"
+	s += "_jv() {\n"
+	s += "\tif (( CURRENT == 3 )) && [[ ${words[2]} == completion ]]; then\n"
+	s += fmt.Sprintf("\t\tcompadd %s\n", strings.Join(shells, " "))
+	s += "\t\treturn\n"
+	s += "\tfi\n"
+	s += "\t(( CURRENT == 2 )) && compadd completion keys serve\n"
+	s += "\t_arguments \\\n"
+	for _, f := range flags {
+		spec := fmt.Sprintf("-%s[%s]", f.Name, zshEscape(f.Usage))
+		switch {
+		case f.IsBool:
+		case len(f.Choices) > 0:
+			spec += fmt.Sprintf(":%s:(%s)", f.Name, strings.Join(f.Choices, " "))
+		default:
+			spec += fmt.Sprintf(":%s:", f.Name)
+		}
+		s += fmt.Sprintf("\t\t'%s' \\\n", spec)
+	}
+	s += "\t\t'*:file:_files'\n"
+	s += "}\n"
+	s += "compdef _jv jv\n"
+	return s
+}
+
+// fishCompletion returns a completion script for fish
+func fishCompletion(flags []completionFlag) string {
+	s := "# fish completion for jv\n"
+	s += "complete -c jv -n '__fish_use_subcommand' -a completion -d 'print a shell completion script'\n"
+	s += "complete -c jv -n '__fish_use_subcommand' -a serve -d 'serve a read-only view to browsers'\n"
+	s += "complete -c jv -n '__fish_use_subcommand' -a keys -d 'print every key binding'\n"
+	s += fmt.Sprintf("complete -c jv -n '__fish_seen_subcommand_from completion' -f -a '%s'\n", strings.Join(shells, " "))
+	for _, f := range flags {
+		line := fmt.Sprintf("complete -c jv -o %s -d '%s'", f.Name, strings.ReplaceAll(f.Usage, "'", `\'`))
+		switch {
+		case f.IsBool:
+		case len(f.Choices) > 0:
+			line += fmt.Sprintf(" -x -a '%s'", strings.Join(f.Choices, " "))
+		default:
+			line += " -r"
+		}
+		s += line + "\n"
+	}
+	return s
+}
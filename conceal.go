@@ -0,0 +1,97 @@
+package jv
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// concealPatterns are keys hidden from the listing since they are noise,
+// such as managedFields or metadata.annotations, they can be set from the
+// config file
+// a pattern is matched against the last keys of the path, one dotted part
+// for each, with * matching any run of characters in a key
+var concealPatterns []string
+
+// concealed is a utility function that checks if the key at the end of a
+// path matches one of the patterns
+func concealed(p []string, patterns []string) bool {
+	for _, pattern := range patterns {
+		parts := strings.Split(pattern, ".")
+		if len(parts) > len(p) {
+			continue
+		}
+		match := true
+		for i, part := range parts {
+			if ok, _ := path.Match(part, p[len(p)-len(parts)+i]); !ok {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// concealKV leaves the concealed keys of the object at path out of its
+// key-value pairs and remembers how many there were
+func (m *Model) concealKV(kvpairs []KVPair, at []string) []KVPair {
+	if m.concealCounts == nil {
+		m.concealCounts = map[string]int{}
+	}
+	m.concealCounts[pathKey(at)] = 0
+	if !m.Concealing || len(concealPatterns) == 0 {
+		return kvpairs
+	}
+	shown := []KVPair{}
+	for _, kv := range kvpairs {
+		if concealed(append(append([]string{}, at...), kv.Key), concealPatterns) {
+			m.concealCounts[pathKey(at)]++
+			continue
+		}
+		shown = append(shown, kv)
+	}
+	return shown
+}
+
+// concealNote returns how many keys of the current level are concealed, to
+// show next to where the listing is
+func (m *Model) concealNote() string {
+	n := m.concealCounts[pathKey(m.Path)]
+	switch {
+	case m.Flat || n == 0:
+		return ""
+	case n == 1:
+		return fmt.Sprintf("  (1 key concealed, %s shows it)", m.Keys.Conceal.Help().Key)
+	}
+	return fmt.Sprintf("  (%d keys concealed, %s shows them)", n, m.Keys.Conceal.Help().Key)
+}
+
+// toggleConceal shows the concealed keys or hides them again
+func (m *Model) toggleConceal() {
+	if len(concealPatterns) == 0 {
+		m.Status = "nothing to conceal, conceal = \"managedFields, _links\" in the config file hides keys"
+		return
+	}
+	m.Concealing = !m.Concealing
+	var key string
+	if len(m.CurrKV) > 0 && !m.Flat {
+		key = m.CurrKV[m.CurrC.RowNo].Key
+	}
+	m.KVCache = map[string][]KVPair{}
+	m.updateKV()
+	m.resetCursor()
+	for i, kv := range m.CurrKV {
+		if kv.Key == key {
+			m.CurrC.RowNo = i
+		}
+	}
+	m.syncPage()
+	if m.Concealing {
+		m.Status = fmt.Sprintf("concealing %s", strings.Join(concealPatterns, ", "))
+		return
+	}
+	m.Status = "showing concealed keys"
+}
@@ -0,0 +1,613 @@
+package jv
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Config holds the defaults read from the config file
+// command line flags override them
+type Config struct {
+	Sort     string            // order to list object keys in
+	Pin      string            // comma separated keys listed first in every object
+	Conceal  string            // comma separated patterns of keys hidden from the listing
+	Format   string            // format of the input
+	Lenient  bool              // accept input that is almost JSON
+	Metrics  bool              // show performance metrics
+	PageSize int               // array elements loaded at a time
+	MaxDepth int               // deepest nesting level to expand
+	MaxKey   int               // longest key shown in full
+	MaxValue int               // longest value shown in full
+	Depth    int               // levels of containers previewed inline
+	Items    int               // children previewed inline for each container
+	Preview  int               // longest preview shown in full
+	Render   bool              // show readable forms of values of known kinds
+	IDs      bool              // recognise UUIDs, digests and colours
+	Zone     string            // time zone timestamps are shown in, empty for the local one
+	Times    string            // format timestamps are shown in
+	Mask     bool              // mask likely credentials
+	Numbers  bool              // show the index of every row
+	Wrap     bool              // wrap long rows onto more lines
+	Mouse    bool              // take mouse input
+	Click    string            // what clicking a row does, select or expand
+	Wheel    int               // rows a turn of the mouse wheel moves
+	Updates  bool              // look up the latest release when the version is shown
+	Audit    string            // file the paths viewed, copied and exported are logged to
+	Access   bool              // draw the viewer for screen readers
+	Outline  int               // levels of the document the outline lists
+	View     string            // view the input opens in: listing, outline, table or flat
+	JQ       string            // jq filter the input is run through every time it is read
+	Path     string            // path to open the listing at
+	Pager    string            // command values are handed to
+	OnSelect string            // command run when the selection changes
+	Formats  map[string]string // formatters of the values of keys matching patterns
+	Redact   map[string]string // how the values of paths matching patterns are redacted
+	Detect   map[string]string // keys of the documents each profile is used for
+	Keys     KeyMap            // key bindings
+
+	// Profiles are named groups of settings for kinds of documents
+	// they are the [profile.<name>] tables of the config file
+	Profiles map[string]map[string]any
+}
+
+// defaultConfig returns the settings used when there is no config file
+func defaultConfig() Config {
+	return Config{
+		Sort:     orderSource,
+		Format:   inputFormat,
+		Lenient:  lenient,
+		PageSize: arrayPageSize,
+		MaxDepth: limits.Depth,
+		MaxKey:   limits.KeyLen,
+		MaxValue: limits.ValueLen,
+		Depth:    previewDepth,
+		Items:    previewItems,
+		Preview:  previewLength,
+		Render:   renderValues,
+		IDs:      detectIDs,
+		Times:    timeFormat,
+		Mask:     maskSecrets,
+		Numbers:  lineNumbers,
+		Wrap:     wrapRows,
+		Mouse:    mouseEnabled,
+		Click:    mouseClick,
+		Wheel:    wheelRows,
+		Updates:  checkUpdates,
+		Outline:  outlineDepth,
+		View:     startView,
+		Keys:     defaultKeyMap(),
+		Formats:  map[string]string{},
+		Redact:   map[string]string{},
+		Detect:   map[string]string{},
+		Profiles: map[string]map[string]any{},
+	}
+}
+
+// envSettings maps environment variables to the config file settings they
+// override, they sit between the config file and the command line flags
+var envSettings = map[string]string{
+	"JV_SORT":           "sort",
+	"JV_PIN":            "pin",
+	"JV_CONCEAL":        "conceal",
+	"JV_FORMAT":         "format",
+	"JV_LENIENT":        "lenient",
+	"JV_METRICS":        "metrics",
+	"JV_DEPTH":          "depth",
+	"JV_PREVIEW_ITEMS":  "preview_items",
+	"JV_PREVIEW_LENGTH": "preview_length",
+	"JV_PAGE_SIZE":      "page_size",
+	"JV_PAGER":          "pager",
+	"JV_RENDER":         "render",
+	"JV_DETECT_IDS":     "detect_ids",
+	"JV_TIME_ZONE":      "time_zone",
+	"JV_TIME_FORMAT":    "time_format",
+	"JV_MASK_SECRETS":   "mask_secrets",
+	"JV_LINE_NUMBERS":   "line_numbers",
+	"JV_WRAP":           "wrap",
+	"JV_MOUSE":          "mouse",
+	"JV_MOUSE_CLICK":    "mouse_click",
+	"JV_WHEEL_ROWS":     "wheel_rows",
+	"JV_CHECK_UPDATES":  "check_updates",
+	"JV_AUDIT_LOG":      "audit_log",
+	"JV_ACCESSIBLE":     "accessible",
+	"JV_OUTLINE_DEPTH":  "outline_depth",
+	"JV_VIEW":           "view",
+	"JV_JQ":             "jq",
+	"JV_MAX_DEPTH":      "limits.max_depth",
+	"JV_MAX_KEY":        "limits.max_key",
+	"JV_MAX_VALUE":      "limits.max_value",
+}
+
+// configPath is a utility function that returns where the config file is
+// looked for, following the XDG base directory convention
+// JV_CONFIG points somewhere else
+func configPath() string {
+	if path := os.Getenv("JV_CONFIG"); path != "" {
+		return path
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "jv", "config.toml")
+}
+
+// loadConfig is a utility function that reads the config file at path
+// on top of the defaults
+// a missing file is not an error
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+	if path == "" {
+		return cfg, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("cannot read config file: %w", err)
+	}
+	defer f.Close()
+	values, err := parseToml(f)
+	if err != nil {
+		return cfg, fmt.Errorf("cannot read config file %s: %w", path, err)
+	}
+	if err := cfg.set(values); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	for profile := range cfg.Detect {
+		if _, ok := cfg.Profiles[profile]; !ok {
+			return cfg, fmt.Errorf("invalid config file %s: detect.%s: unknown profile %s", path, profile, profile)
+		}
+	}
+	return cfg, nil
+}
+
+// set fills in the settings from the values of a config file
+func (cfg *Config) set(values map[string]any) error {
+	for key, val := range values {
+		// profiles are only applied when they are picked
+		if rest := strings.TrimPrefix(key, "profile."); rest != key {
+			name, setting, ok := strings.Cut(rest, ".")
+			if !ok {
+				return fmt.Errorf("%s: expected a [profile.%s] table", key, rest)
+			}
+			if cfg.Profiles[name] == nil {
+				cfg.Profiles[name] = map[string]any{}
+			}
+			cfg.Profiles[name][setting] = val
+			continue
+		}
+		var err error
+		switch key {
+		case "sort":
+			err = setValue(&cfg.Sort, val)
+			if err == nil {
+				err = checkOrder(cfg.Sort)
+			}
+		case "pin":
+			err = setValue(&cfg.Pin, val)
+		case "conceal":
+			err = setValue(&cfg.Conceal, val)
+		case "format":
+			err = setValue(&cfg.Format, val)
+		case "lenient":
+			err = setValue(&cfg.Lenient, val)
+		case "metrics":
+			err = setValue(&cfg.Metrics, val)
+		case "path":
+			err = setValue(&cfg.Path, val)
+		case "depth":
+			err = setValue(&cfg.Depth, val)
+		case "preview_items":
+			err = setValue(&cfg.Items, val)
+		case "preview_length":
+			err = setValue(&cfg.Preview, val)
+		case "render":
+			err = setValue(&cfg.Render, val)
+		case "detect_ids":
+			err = setValue(&cfg.IDs, val)
+		case "time_zone":
+			err = setValue(&cfg.Zone, val)
+			if err == nil {
+				_, err = loadTimeZone(cfg.Zone)
+			}
+		case "time_format":
+			err = setValue(&cfg.Times, val)
+			if err == nil {
+				err = checkTimeFormat(cfg.Times)
+			}
+		case "mask_secrets":
+			err = setValue(&cfg.Mask, val)
+		case "line_numbers":
+			err = setValue(&cfg.Numbers, val)
+		case "wrap":
+			err = setValue(&cfg.Wrap, val)
+		case "mouse":
+			err = setValue(&cfg.Mouse, val)
+		case "mouse_click":
+			err = setValue(&cfg.Click, val)
+			if err == nil {
+				err = checkMouseClick(cfg.Click)
+			}
+		case "wheel_rows":
+			err = setValue(&cfg.Wheel, val)
+			if err == nil && cfg.Wheel < 1 {
+				err = fmt.Errorf("expected at least 1 row but got %d", cfg.Wheel)
+			}
+		case "check_updates":
+			err = setValue(&cfg.Updates, val)
+		case "audit_log":
+			err = setValue(&cfg.Audit, val)
+		case "accessible":
+			err = setValue(&cfg.Access, val)
+		case "jq":
+			err = setValue(&cfg.JQ, val)
+		case "view":
+			err = setValue(&cfg.View, val)
+			if err == nil {
+				err = checkView(cfg.View)
+			}
+		case "outline_depth":
+			err = setValue(&cfg.Outline, val)
+			if err == nil {
+				err = checkOutlineDepth(cfg.Outline)
+			}
+		case "pager":
+			err = setValue(&cfg.Pager, val)
+		case "on_select":
+			err = setValue(&cfg.OnSelect, val)
+		case "page_size":
+			err = setValue(&cfg.PageSize, val)
+		case "limits.max_depth":
+			err = setValue(&cfg.MaxDepth, val)
+		case "limits.max_key":
+			err = setValue(&cfg.MaxKey, val)
+		case "limits.max_value":
+			err = setValue(&cfg.MaxValue, val)
+		default:
+			// [keys] maps actions to comma separated lists of keys
+			if action := strings.TrimPrefix(key, "keys."); action != key {
+				var keys string
+				err = setValue(&keys, val)
+				if err == nil {
+					err = cfg.Keys.rebind(action, keys)
+				}
+				break
+			}
+			// [detect] maps profiles to the keys of the documents they are
+			// used for
+			if profile := strings.TrimPrefix(key, "detect."); profile != key {
+				var keys string
+				err = setValue(&keys, val)
+				if err == nil && len(splitList(keys)) == 0 {
+					err = fmt.Errorf("expected the keys of the documents to use %s for", profile)
+				}
+				if err == nil {
+					cfg.Detect[profile] = keys
+				}
+				break
+			}
+			// [formats] maps key patterns to formatters
+			if pattern := strings.TrimPrefix(key, "formats."); pattern != key {
+				var format string
+				err = setValue(&format, val)
+				if err == nil {
+					err = checkFormat(format)
+				}
+				if err == nil {
+					cfg.Formats[pattern] = format
+				}
+				break
+			}
+			// [redact] maps path and key patterns to how their values are
+			// redacted
+			if pattern := strings.TrimPrefix(key, "redact."); pattern != key {
+				var action string
+				err = setValue(&action, val)
+				if err == nil {
+					_, err = parseRedaction(pattern, action)
+				}
+				if err == nil {
+					cfg.Redact[pattern] = action
+				}
+				break
+			}
+			err = fmt.Errorf("unknown setting")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// useProfile applies the settings of the named profile
+func (cfg *Config) useProfile(name string) error {
+	settings, ok := cfg.Profiles[name]
+	if !ok {
+		names := []string{}
+		for n := range cfg.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %s, the config file has no profiles", name)
+		}
+		return fmt.Errorf("unknown profile %s, expected one of %s", name, strings.Join(names, ", "))
+	}
+	if err := cfg.set(settings); err != nil {
+		return fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// profileArg is a utility function that finds the profile picked on the
+// command line before the flags are parsed, since the profile sets the
+// defaults of the other flags
+func profileArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == "profile" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(name, "profile=") {
+			return strings.TrimPrefix(name, "profile=")
+		}
+	}
+	return os.Getenv("JV_PROFILE")
+}
+
+// envNames returns the environment variables that override settings in order
+func envNames() []string {
+	names := []string{}
+	for name := range envSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setEnv fills in the settings from environment variables
+func (cfg *Config) setEnv() error {
+	for _, name := range envNames() {
+		env, ok := os.LookupEnv(name)
+		if !ok || env == "" {
+			continue
+		}
+		if err := cfg.set(map[string]any{envSettings[name]: envValue(env)}); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// apply makes the settings the defaults of the program
+func (cfg Config) apply() {
+	inputFormat = cfg.Format
+	pinnedKeys = splitList(cfg.Pin)
+	concealPatterns = splitList(cfg.Conceal)
+	lenient = cfg.Lenient
+	if cfg.PageSize > 0 {
+		arrayPageSize = cfg.PageSize
+	}
+	limits = Limits{Depth: cfg.MaxDepth, KeyLen: cfg.MaxKey, ValueLen: cfg.MaxValue}
+	previewDepth = cfg.Depth
+	previewItems = cfg.Items
+	previewLength = cfg.Preview
+	renderValues = cfg.Render
+	detectIDs = cfg.IDs
+	timeFormat = cfg.Times
+	// the zone was checked when it was set
+	if zone, err := loadTimeZone(cfg.Zone); err == nil {
+		timeZone = zone
+	}
+	maskSecrets = cfg.Mask
+	lineNumbers = cfg.Numbers
+	wrapRows = cfg.Wrap
+	mouseEnabled = cfg.Mouse
+	mouseClick = cfg.Click
+	wheelRows = cfg.Wheel
+	checkUpdates = cfg.Updates
+	auditPath = cfg.Audit
+	accessible = cfg.Access
+	outlineDepth = cfg.Outline
+	startView = cfg.View
+	jqFilter = cfg.JQ
+	pager = cfg.Pager
+	onSelect = cfg.OnSelect
+	keyFormats = sortedFormats(cfg.Formats)
+	redactRules = redactionRules(cfg.Redact)
+	detectRules = detectionRules(cfg.Detect)
+}
+
+// envValue is the value of an environment variable, which has no quotes
+// to tell strings from numbers and booleans so it is read as whatever the
+// setting it is stored in holds
+type envValue string
+
+// setValue is a utility function that stores a config value in a setting
+// of the same type
+func setValue[T any](setting *T, val any) error {
+	if env, ok := val.(envValue); ok {
+		return setEnvValue(setting, string(env))
+	}
+	v, ok := val.(T)
+	if !ok {
+		return fmt.Errorf("expected a %T but got %v", *setting, val)
+	}
+	*setting = v
+	return nil
+}
+
+// setEnvValue is a utility function that reads the value of an environment
+// variable as the type of the setting it is stored in
+func setEnvValue(setting any, env string) error {
+	switch s := setting.(type) {
+	case *string:
+		*s = env
+	case *bool:
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return fmt.Errorf("expected a bool but got %s", env)
+		}
+		*s = b
+	case *int:
+		i, err := strconv.Atoi(strings.ReplaceAll(env, "_", ""))
+		if err != nil {
+			return fmt.Errorf("expected a int but got %s", env)
+		}
+		*s = i
+	default:
+		return fmt.Errorf("expected a %T but got %s", setting, env)
+	}
+	return nil
+}
+
+// parseToml is a utility function that reads the subset of TOML used by the
+// config file: [tables] and key = value pairs whose values are strings,
+// integers or booleans
+// keys inside a table are returned as table.key
+func parseToml(f *os.File) (map[string]any, error) {
+	values := map[string]any{}
+	table := ""
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated table name", n)
+			}
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, rest, err := parseTomlKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		val, err := parseTomlValue(rest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if table != "" {
+			key = table + "." + key
+		}
+		values[key] = val
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// parseTomlKey reads the key of a key = value pair and returns it along
+// with the value
+// quoted keys are read up to their closing quote since they can hold an =,
+// like the paths of the [redact] table
+func parseTomlKey(line string) (string, string, error) {
+	key, rest := "", ""
+	switch {
+	case strings.HasPrefix(line, `"`):
+		end := 1
+		for end < len(line) && line[end] != '"' {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(line) {
+			return "", "", fmt.Errorf("unterminated key")
+		}
+		var err error
+		if key, err = strconv.Unquote(line[:end+1]); err != nil {
+			return "", "", fmt.Errorf("invalid key %s", line[:end+1])
+		}
+		rest = line[end+1:]
+	case strings.HasPrefix(line, "'"):
+		end := strings.Index(line[1:], "'")
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated key")
+		}
+		key, rest = line[1:end+1], line[end+2:]
+	default:
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return "", "", fmt.Errorf("expected key = value")
+		}
+		key, rest = strings.TrimSpace(line[:eq]), line[eq:]
+	}
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "=") {
+		return "", "", fmt.Errorf("expected key = value")
+	}
+	return key, strings.TrimSpace(rest[1:]), nil
+}
+
+// parseTomlValue reads a string, integer or boolean
+// followed by an optional comment
+func parseTomlValue(s string) (any, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		end := 1
+		for end < len(s) && s[end] != '"' {
+			if s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(s) {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		if err := checkTomlRest(s[end+1:]); err != nil {
+			return nil, err
+		}
+		return strconv.Unquote(s[:end+1])
+	case strings.HasPrefix(s, "'"):
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		if err := checkTomlRest(s[end+2:]); err != nil {
+			return nil, err
+		}
+		return s[1 : end+1], nil
+	}
+	if i := strings.Index(s, "#"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	i, err := strconv.Atoi(strings.ReplaceAll(s, "_", ""))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported value %s", s)
+	}
+	return i, nil
+}
+
+// checkTomlRest checks that only a comment follows a value
+func checkTomlRest(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected %s after value", rest)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package jv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetEnv(t *testing.T) {
+	t.Setenv("JV_PIN", "1")
+	t.Setenv("JV_PAGER", "t")
+	t.Setenv("JV_LENIENT", "1")
+	t.Setenv("JV_PAGE_SIZE", "1_000")
+	cfg := defaultConfig()
+	if err := cfg.setEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Pin != "1" || cfg.Pager != "t" || !cfg.Lenient || cfg.PageSize != 1000 {
+		t.Errorf("pin %q, pager %q, lenient %v and page size %d", cfg.Pin, cfg.Pager, cfg.Lenient, cfg.PageSize)
+	}
+	t.Setenv("JV_LENIENT", "yes")
+	if err := cfg.setEnv(); err == nil {
+		t.Errorf("JV_LENIENT=yes is not a bool but was accepted")
+	}
+}
+
+func TestParseToml(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]any
+		wantErr bool
+	}{
+		{"bare key", "sort = \"key\"\n", map[string]any{"sort": "key"}, false},
+		{"quoted key with =", "[redact]\n\"/a=b/\" = \"hash\"\n", map[string]any{"redact./a=b/": "hash"}, false},
+		{"quoted key with escaped quote", "[redact]\n\"a\\\"=b\" = \"drop\"\n", map[string]any{"redact.a\"=b": "drop"}, false},
+		{"literal key with =", "[keys]\n'a=b' = 'x'\n", map[string]any{"keys.a=b": "x"}, false},
+		{"value with =", "jq = \".a == 1\" # comment\n", map[string]any{"jq": ".a == 1"}, false},
+		{"no value", "sort\n", nil, true},
+		{"unterminated key", "\"sort = 1\n", nil, true},
+		{"quoted key without value", "\"sort\" \"key\"\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, err := parseToml(f)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
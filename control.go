@@ -0,0 +1,260 @@
+package jv
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// controlTimeout is how long a control command waits for jv to answer
+const controlTimeout = 5 * time.Second
+
+// controlMsg is a command received on the control socket
+type controlMsg struct {
+	Verb  string        // what to do, such as goto or dump
+	Arg   string        // the rest of the line
+	Reply chan<- string // where the answer goes as one line of JSON
+}
+
+// subscribeMsg adds a connection to the ones told when the selection changes
+type subscribeMsg struct {
+	Events chan string
+}
+
+// unsubscribeMsg removes a connection that has closed from the ones told
+// when the selection changes
+type unsubscribeMsg struct {
+	Events chan string
+}
+
+// controlKeys are the key names the key command understands besides
+// single characters
+var controlKeys = map[string]tea.KeyType{
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"backspace": tea.KeyBackspace,
+	"tab":       tea.KeyTab,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"ctrl+c":    tea.KeyCtrlC,
+}
+
+// serveControl listens on a unix socket for commands driving the program,
+// one per line:
+//
+//	goto <selector>   go to a path such as .spec.containers[0]
+//	search <query>    show the leaves matching the query
+//	key <name>        press a key such as down, enter or s
+//	dump              print the path and value under the cursor
+//	view              print the screen as it is drawn
+//	subscribe         print an event whenever the selection changes
+//	open <path>       open an input in a new tab
+//	close             close the tab that is shown
+//	tab <n>           show the nth tab
+//	quit              quit jv
+//
+// every command is answered with one line of JSON
+func serveControl(path string, p *tea.Program) (net.Listener, error) {
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the control socket: %w", err)
+	}
+	debugLog.Printf("listening for control commands on %s", path)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go handleControl(conn, p)
+		}
+	}()
+	return l, nil
+}
+
+// handleControl answers the commands sent on one connection
+func handleControl(conn net.Conn, p *tea.Program) {
+	defer conn.Close()
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		debugLog.Printf("control command %q", line)
+		if verb == "subscribe" {
+			events := make(chan string, 16)
+			p.Send(subscribeMsg{Events: events})
+			defer p.Send(unsubscribeMsg{Events: events})
+			fmt.Fprintln(conn, controlReply(nil, map[string]any{"subscribed": true}))
+			// the connection is read on to find out when it closes, since
+			// there may be no event to fail to write for a long time
+			closed := make(chan struct{})
+			go func() {
+				for sc.Scan() {
+				}
+				close(closed)
+			}()
+			for {
+				select {
+				case event := <-events:
+					if _, err := fmt.Fprintln(conn, event); err != nil {
+						return
+					}
+				case <-closed:
+					return
+				}
+			}
+		}
+		reply := make(chan string, 1)
+		p.Send(controlMsg{Verb: verb, Arg: strings.TrimSpace(arg), Reply: reply})
+		select {
+		case answer := <-reply:
+			fmt.Fprintln(conn, answer)
+		case <-time.After(controlTimeout):
+			fmt.Fprintln(conn, controlReply(fmt.Errorf("no answer from jv"), nil))
+			return
+		}
+	}
+}
+
+// controlReply is a utility function that formats the answer to a command
+func controlReply(err error, fields map[string]any) string {
+	reply := map[string]any{"ok": err == nil}
+	if err != nil {
+		reply["error"] = err.Error()
+	}
+	for k, v := range fields {
+		reply[k] = v
+	}
+	content, merr := json.Marshal(reply)
+	if merr != nil {
+		return fmt.Sprintf(`{"ok":false,"error":%q}`, merr.Error())
+	}
+	return string(content)
+}
+
+// controlKey is a utility function that turns a key name into a key press
+func controlKey(name string) tea.KeyMsg {
+	if t, ok := controlKeys[name]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	if name == "space" {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+// selection returns the path to the value under the cursor, or to the
+// current level if it has no rows
+func (m *Model) selection() []string {
+	if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+		return m.currentPath()
+	}
+	return m.Path
+}
+
+// control carries out a command from the control socket
+func (m *Model) control(msg controlMsg) tea.Cmd {
+	if m.Loading || m.Err != nil {
+		msg.Reply <- controlReply(fmt.Errorf("the input is not loaded"), nil)
+		return nil
+	}
+	var cmd tea.Cmd
+	var err error
+	fields := map[string]any{}
+	switch msg.Verb {
+	case "goto":
+		var path []string
+		if path, err = selectorKeys(msg.Arg); err != nil {
+			break
+		}
+		if err = m.checkPath(path); err == nil {
+			m.Detail, m.Palette, m.Flat = nil, nil, false
+			m.goTo(path)
+		}
+	case "search":
+		m.Detail, m.Palette = nil, nil
+		m.Flat, m.Filter = true, msg.Arg
+		m.resetCursor()
+		m.updateKV()
+		cmd = m.startSearch()
+	case "key":
+		_, cmd = m.Update(controlKey(msg.Arg))
+	case "dump":
+		path := m.selection()
+		fields["path"] = selectorPath(m.Data, path)
+		fields["keys"] = path
+		fields["value"] = m.exportValue(path)
+	case "view":
+		fields["view"] = m.View()
+	case "quit":
+		cmd = tea.Quit
+	default:
+		err = fmt.Errorf("unknown command %s", msg.Verb)
+	}
+	if err == nil {
+		fields["path"] = selectorPath(m.Data, m.selection())
+	}
+	msg.Reply <- controlReply(err, fields)
+	return cmd
+}
+
+// unsubscribe stops telling a connection that has closed about the selection
+func (m *Model) unsubscribe(events chan string) {
+	for i, ch := range m.subscribers {
+		if ch == events {
+			m.subscribers = append(m.subscribers[:i], m.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// notifySelection tells the subscribers and the broadcaster when the
+// selection has changed, and records it in the audit log
+// subscribers that are not keeping up miss events rather than holding up jv
+func (m *Model) notifySelection() {
+	if (len(m.subscribers) == 0 && m.broadcast == nil && auditLog == nil) || m.Loading || m.Data == nil {
+		return
+	}
+	path := selectorPath(m.Data, m.selection())
+	if path == m.lastSelection {
+		return
+	}
+	m.lastSelection = path
+	m.audit(auditView, m.selection(), "")
+	if m.broadcast != nil {
+		m.broadcastSelection(m.selection())
+	}
+	event, _ := json.Marshal(map[string]any{"event": "selection", "path": path})
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- string(event):
+		default:
+		}
+	}
+}
+
+// removeSocket is a utility function that removes a control socket left
+// behind by a jv that did not exit cleanly
+func removeSocket(path string) {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			// another jv is still listening on it
+			conn.Close()
+			return
+		}
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,106 @@
+package jv
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// crashPath is the file a crash report is written to, it can be set from
+// the command line
+var crashPath string
+
+// crash is a panic caught while the interface was running
+type crash struct {
+	Value any    // what the panic was called with
+	Stack []byte // stack of the goroutine that panicked
+	Shape string // what the input and the interface looked like, without any values
+}
+
+// crashed records a panic, the program is then stopped by quitting, which
+// restores the terminal
+// only the first panic is kept since the others tend to follow from it
+func (w *Workspace) crashed(r any, stack []byte) {
+	if w.crash == nil {
+		w.crash = &crash{Value: r, Stack: stack, Shape: w.shape()}
+		debugLog.Printf("crashed: %v\n%s", r, stack)
+	}
+}
+
+// crashMsg carries a panic in a command or a task back to the workspace
+type crashMsg struct {
+	Value any
+	Stack []byte
+}
+
+// recoverCommand is a utility function that turns a panic in a command
+// or a task into a crashMsg, it is deferred with a pointer to its result
+func recoverCommand(msg *tea.Msg) {
+	if r := recover(); r != nil {
+		*msg = crashMsg{Value: r, Stack: debug.Stack()}
+	}
+}
+
+// shape describes the documents that are open without any of their keys
+// or values so that the report can be shared
+func (w *Workspace) shape() string {
+	lines := []string{fmt.Sprintf("documents: %d open", len(w.Docs))}
+	for i, d := range w.Docs {
+		m := d.Model
+		input := []string{strings.TrimSuffix(strings.TrimPrefix(fmt.Sprintf("%T", sourceFor(m.Source)), "jv."), "Source")}
+		if m.Git {
+			input[0] = "git"
+		}
+		if m.Progress != nil {
+			input = append(input, fmt.Sprintf("%d bytes", atomic.LoadInt64(&m.Progress.Bytes)))
+		}
+		if m.Data != nil {
+			input = append(input, NewNode(m.Data).Kind().String()+" at the root")
+		}
+		state := []string{"mode " + m.mode().String(), fmt.Sprintf("%d levels deep", len(m.Path)),
+			fmt.Sprintf("%d levels listed", len(m.KVCache))}
+		if m.Flat {
+			state = append(state, "flattened")
+		}
+		if m.Filter != "" {
+			state = append(state, fmt.Sprintf("filter of %d characters", len(m.Filter)))
+		}
+		if m.Index != nil {
+			state = append(state, fmt.Sprintf("%d leaves indexed", len(m.Index)))
+		}
+		marker := ""
+		if i == w.Active {
+			marker = ", shown"
+		}
+		lines = append(lines, fmt.Sprintf("document %d%s: %s; %s", i+1, marker,
+			strings.Join(input, ", "), strings.Join(state, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// report returns the crash report printed once the terminal is restored
+func (c *crash) report() string {
+	return fmt.Sprintf("jv crashed: %v\n\njv %s, %s, %s/%s\n%s\n\n%s",
+		c.Value, getVersion(), runtime.Version(), runtime.GOOS, runtime.GOARCH, c.Shape, c.Stack)
+}
+
+// reportCrash prints the crash report and writes it to crashPath if it is
+// set, since it is easier to attach a file to a bug report
+func reportCrash(c *crash) {
+	report := c.report()
+	fmt.Fprintln(os.Stderr, report)
+	if crashPath == "" {
+		fmt.Fprintln(os.Stderr, "please report this along with the report above, -crash-report writes it to a file")
+		return
+	}
+	if err := os.WriteFile(crashPath, []byte(report), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "jv: cannot write the crash report: %s\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "the crash report was written to %s, please attach it when reporting this\n", crashPath)
+}
@@ -0,0 +1,23 @@
+package jv
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// debugLog records what is going on when -debug is given
+// the terminal is taken up by the listing so it goes to a file
+var debugLog = log.New(io.Discard, "", log.Ltime|log.Lmicroseconds)
+
+// openDebugLog is a utility function that starts writing the debug log
+// to the end of the file at path
+func openDebugLog(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open debug log: %w", err)
+	}
+	debugLog.SetOutput(f)
+	return f, nil
+}
@@ -0,0 +1,187 @@
+package jv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Detail is a scrollable pane used to show generated output
+// for the node under the cursor
+type Detail struct {
+	Title    string   // shown above the content
+	Content  string   // the full content, also used when exporting
+	Lines    []string // the content split into lines for scrolling
+	Offset   int      // first line being displayed
+	FileName string   // file the content gets written to on export
+	Status   string   // result of the last action taken in the pane
+	Text     string   // the content as plain text, such as markup without its tags, "" if there is none
+
+	next func() (string, bool) // lays out the next line of content scrolled to, nil once every line is in Lines
+}
+
+// largeString is the size from which a string shown in the detail pane is
+// said to be laid out as it is scrolled to
+const largeString = 1 << 20
+
+// openDetail shows the given content in the detail pane
+func (m *Model) openDetail(title, content, fileName string) {
+	m.Detail = &Detail{
+		Title:    title,
+		Content:  content,
+		Lines:    strings.Split(content, "\n"),
+		Offset:   0,
+		FileName: fileName,
+	}
+}
+
+// openString shows a string value in the detail pane wrapped to the width
+// of the terminal, laying out only the lines scrolled to so that embedded
+// files and blobs of several megabytes open at once
+func (m *Model) openString(title, s string) {
+	wrap := m.Width
+	if wrap < minWidth {
+		wrap = minWidth
+	}
+	// lines are cut at line breaks or once they are as wide as the pane
+	rest := s
+	next := func() (string, bool) {
+		if rest == "" {
+			return "", false
+		}
+		end, runes := 0, 0
+		for end < len(rest) && rest[end] != '\n' && runes < wrap {
+			_, size := utf8.DecodeRuneInString(rest[end:])
+			end += size
+			runes++
+		}
+		line := sanitize(rest[:end])
+		if end < len(rest) && rest[end] == '\n' {
+			end++
+		}
+		rest = rest[end:]
+		return line, true
+	}
+	m.Detail = &Detail{
+		Title:    title,
+		Content:  s,
+		Lines:    []string{},
+		FileName: "value.txt",
+		next:     next,
+	}
+	if len(s) >= largeString {
+		m.Detail.Status = fmt.Sprintf("large string of %s, laid out as it is scrolled to", humanBytes(int64(len(s))))
+	}
+	m.Detail.fill(m.detailHeight())
+}
+
+// fill lays out the content still to be laid out until there are at least
+// n lines or none is left
+func (d *Detail) fill(n int) {
+	for len(d.Lines) < n && d.next != nil {
+		line, ok := d.next()
+		if !ok {
+			d.next = nil
+			break
+		}
+		d.Lines = append(d.Lines, line)
+	}
+}
+
+// detailHeight is the number of content lines the detail pane can show
+// leaving room for the title and the help line
+func (m *Model) detailHeight() int {
+	if m.Height > 5 {
+		return m.Height - 5
+	}
+	return 1
+}
+
+// scrollDetail moves the detail pane by n lines keeping it within the content
+func (m *Model) scrollDetail(n int) {
+	d := m.Detail
+	d.Offset += n
+	d.fill(d.Offset + m.detailHeight())
+	if d.Offset > len(d.Lines)-m.detailHeight() {
+		d.Offset = len(d.Lines) - m.detailHeight()
+	}
+	if d.Offset < 0 {
+		d.Offset = 0
+	}
+}
+
+// updateDetail handles key presses while the detail pane is open
+func (m *Model) updateDetail(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	// the wheel scrolls the content
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			break
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.scrollDetail(-wheelRows)
+		case tea.MouseButtonWheelDown:
+			m.scrollDetail(wheelRows)
+		}
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		// close returns to the listing
+		case key.Matches(msg, m.Keys.Close):
+			m.Detail = nil
+		case key.Matches(msg, m.Keys.Up):
+			m.scrollDetail(-1)
+		case key.Matches(msg, m.Keys.Down):
+			m.scrollDetail(1)
+		case key.Matches(msg, m.Keys.PageUp):
+			m.scrollDetail(-m.detailHeight())
+		case key.Matches(msg, m.Keys.PageDown):
+			m.scrollDetail(m.detailHeight())
+		// write saves the content to a file in the current directory
+		case key.Matches(msg, m.Keys.Write):
+			err := os.WriteFile(m.Detail.FileName, []byte(m.Detail.Content), 0644)
+			if err != nil {
+				debugLog.Printf("cannot write %s: %s", m.Detail.FileName, err)
+				m.Detail.Status = fmt.Sprintf("cannot write %s: %s", m.Detail.FileName, err)
+			} else {
+				m.Detail.Status = fmt.Sprintf("wrote %s", m.Detail.FileName)
+				m.audit(auditExport, m.selection(), m.Detail.FileName)
+			}
+		// the content and its plain text take turns, the one shown is written
+		case key.Matches(msg, m.Keys.PlainText):
+			if d := m.Detail; d.Text != "" {
+				d.Content, d.Text = d.Text, d.Content
+				d.Lines, d.Offset = strings.Split(d.Content, "\n"), 0
+			}
+		// the pager shows the whole content with its own search and scrolling
+		case key.Matches(msg, m.Keys.Pager):
+			return m, pageContent([]byte(m.Detail.Content + "\n"))
+		}
+	}
+	return m, nil
+}
+
+// viewDetail renders the detail pane
+func (m *Model) viewDetail() string {
+	d := m.Detail
+	s := style(d.Title, styleBold) + "\n\n"
+	end := d.Offset + m.detailHeight()
+	if end > len(d.Lines) {
+		end = len(d.Lines)
+	}
+	for _, line := range d.Lines[d.Offset:end] {
+		s += fmt.Sprintf("%s\n", line)
+	}
+	s += "\n"
+	if d.Status != "" {
+		s += fmt.Sprintf("%s  ", d.Status)
+	}
+	s += style(m.Keys.detailHelp(d.Text != ""), styleFaint) + "\n"
+	return s
+}
@@ -0,0 +1,103 @@
+package jv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// detectRule picks a profile for documents that have all of its keys
+// keys are dotted paths such as log.level
+type detectRule struct {
+	Profile string
+	Keys    []string
+}
+
+// detectRules are tried in the order of their profiles on every document
+// loaded, they are the [detect] table of the config file
+var detectRules []detectRule
+
+// useDetected applies the settings of a profile detected from the input
+// under the environment and the flags, returning the order to list keys in
+// it is set by Main when no profile was picked, nil otherwise
+var useDetected func(profile string) (string, error)
+
+// detectionRules is a utility function that returns the rules of the
+// [detect] table, which maps profiles to comma separated keys
+func detectionRules(detect map[string]string) []detectRule {
+	rules := []detectRule{}
+	for profile, keys := range detect {
+		rules = append(rules, detectRule{Profile: profile, Keys: splitList(keys)})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Profile < rules[j].Profile
+	})
+	return rules
+}
+
+// hasKey is a utility function that reports whether the dotted key is in o
+// arrays such as logs are looked into through their first element
+func hasKey(o any, key string) bool {
+	for _, k := range strings.Split(key, ".") {
+		if isArray(o) {
+			o = getKAny(o)["0"]
+		}
+		if o == nil || isArray(o) {
+			return false
+		}
+		v, ok := getKAny(o)[k]
+		if !ok {
+			return false
+		}
+		o = v
+	}
+	return true
+}
+
+// detectProfile is a utility function that returns the first rule whose
+// keys are all in the document
+func detectProfile(data any, rules []detectRule) (detectRule, bool) {
+	for _, rule := range rules {
+		found := len(rule.Keys) > 0
+		for _, key := range rule.Keys {
+			if !hasKey(data, key) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return rule, true
+		}
+	}
+	return detectRule{}, false
+}
+
+// applyDetected switches to the profile the loaded document is detected
+// as, keeping the current settings when no rule matches
+func (m *Model) applyDetected() {
+	if useDetected == nil {
+		return
+	}
+	rule, ok := detectProfile(m.Data, detectRules)
+	if !ok || rule.Profile == m.Profile {
+		return
+	}
+	order, err := useDetected(rule.Profile)
+	if err != nil {
+		debugLog.Printf("cannot use the detected profile %s: %s", rule.Profile, err)
+		m.Status = fmt.Sprintf("cannot use the detected profile %s: %s", rule.Profile, err)
+		return
+	}
+	debugLog.Printf("detected profile %s", rule.Profile)
+	m.Profile = rule.Profile
+	// the profile can redact more of the input
+	if len(redactRules) > 0 {
+		m.Data, m.Relaxed = redactInput(m.Data, m.Relaxed)
+		m.Nodes, m.level = []*Node{NewNode(m.Data)}, nil
+		m.refreshOutline()
+	}
+	m.Sort, m.Pinned = order, pinnedKeys
+	m.Masking, m.LineNumbers, m.Wrap = maskSecrets, lineNumbers, wrapRows
+	m.KVCache, m.rowCache = map[string][]KVPair{}, nil
+	m.Status = fmt.Sprintf("using the %s profile, the input has %s", rule.Profile, strings.Join(rule.Keys, ", "))
+}
@@ -0,0 +1,135 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// kinds of change between two documents
+const (
+	changeAdded   = '+'
+	changeRemoved = '-'
+	changeChanged = '~'
+)
+
+// change is one difference between two documents
+type change struct {
+	Path []any // string keys and int indices to the value that differs
+	Kind byte  // changeAdded, changeRemoved or changeChanged
+	Old  any   // the value before, nil if it was added
+	New  any   // the value after, nil if it was removed
+}
+
+// diffValues is a utility function that returns the structural differences
+// between two materialized values, object keys are matched by name and
+// array elements by position
+func diffValues(a, b any) []change {
+	changes := []change{}
+	diffInto(a, b, []any{}, &changes)
+	return changes
+}
+
+// diffInto adds the differences between a and b at path to changes
+func diffInto(a, b any, path []any, changes *[]change) {
+	at := func() []any {
+		return append([]any{}, path...)
+	}
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := []string{}
+		for k := range x {
+			keys = append(keys, k)
+		}
+		for k := range y {
+			if _, ok := x[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			xv, inA := x[k]
+			yv, inB := y[k]
+			switch {
+			case !inB:
+				*changes = append(*changes, change{Path: append(at(), k), Kind: changeRemoved, Old: xv})
+			case !inA:
+				*changes = append(*changes, change{Path: append(at(), k), Kind: changeAdded, New: yv})
+			default:
+				diffInto(xv, yv, append(at(), k), changes)
+			}
+		}
+		return
+	case []any:
+		y, ok := b.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(x) || i < len(y); i++ {
+			switch {
+			case i >= len(y):
+				*changes = append(*changes, change{Path: append(at(), i), Kind: changeRemoved, Old: x[i]})
+			case i >= len(x):
+				*changes = append(*changes, change{Path: append(at(), i), Kind: changeAdded, New: y[i]})
+			default:
+				diffInto(x[i], y[i], append(at(), i), changes)
+			}
+		}
+		return
+	}
+	if !jsonEqual(a, b) {
+		*changes = append(*changes, change{Path: at(), Kind: changeChanged, Old: a, New: b})
+	}
+}
+
+// formatSelector is a utility function that writes a path of string keys
+// and int indices as a jq style selector
+func formatSelector(path []any) string {
+	s := ""
+	for _, k := range path {
+		if i, ok := k.(int); ok {
+			s += fmt.Sprintf("[%d]", i)
+		} else {
+			s += gronKey(k.(string))
+		}
+	}
+	if s == "" || strings.HasPrefix(s, "[") {
+		s = "." + s
+	}
+	return s
+}
+
+// diffValue formats a value in a change as one line of JSON
+func diffValue(o any) string {
+	content, err := json.Marshal(o)
+	if err != nil {
+		return getVal(o)
+	}
+	return truncate(sanitize(string(content)), limits.ValueLen)
+}
+
+// changesString is a utility function that lists changes one per line
+// with + for added, - for removed and ~ for changed values
+func changesString(changes []change) string {
+	if len(changes) == 0 {
+		return "(no differences)"
+	}
+	lines := []string{}
+	for _, c := range changes {
+		path := sanitize(formatSelector(c.Path))
+		switch c.Kind {
+		case changeAdded:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", path, diffValue(c.New)))
+		case changeRemoved:
+			lines = append(lines, fmt.Sprintf("- %s: %s", path, diffValue(c.Old)))
+		default:
+			lines = append(lines, fmt.Sprintf("~ %s: %s → %s", path, diffValue(c.Old), diffValue(c.New)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
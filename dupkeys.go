@@ -0,0 +1,150 @@
+package jv
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dupKeysMsg carries the duplicate keys found in the input
+type dupKeysMsg struct {
+	DupKeys map[string][]string // duplicated keys keyed by the path of their object
+}
+
+// keyFrame is an object or array being walked while looking for duplicate keys
+type keyFrame struct {
+	isObj     bool
+	path      []string
+	seen      map[string]bool // keys seen so far in an object
+	key       string          // key of the value being read in an object
+	expectKey bool            // the next token in an object is a key
+	index     int             // index of the value being read in an array
+}
+
+// rawReader is a utility function that returns a reader over the unparsed input
+// or nil if the data has already been parsed
+func rawReader(data any) io.Reader {
+	switch val := data.(type) {
+	case json.RawMessage:
+		return bytes.NewReader(val)
+	case fileSpan:
+		return io.NewSectionReader(val.src.r, val.off, val.size)
+	}
+	return nil
+}
+
+// findDuplicateKeys is a utility function that walks the input token by token
+// and returns every key that appears more than once in the same object
+// the result is keyed by the path of the object
+// normal parsing silently keeps the last value of a duplicated key
+func findDuplicateKeys(data any) map[string][]string {
+	dups := map[string][]string{}
+	// back to back documents are checked one at a time
+	if docs, ok := data.([]any); ok {
+		for i, doc := range docs {
+			scanDuplicateKeys(doc, []string{strconv.Itoa(i)}, dups)
+		}
+		return dups
+	}
+	scanDuplicateKeys(data, []string{}, dups)
+	return dups
+}
+
+// scanDuplicateKeys adds the duplicate keys in a raw value found at root to dups
+func scanDuplicateKeys(data any, root []string, dups map[string][]string) {
+	r := rawReader(data)
+	if r == nil {
+		return
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	stack := []*keyFrame{}
+	// childPath returns the path of the value about to be read
+	childPath := func() []string {
+		if len(stack) == 0 {
+			return root
+		}
+		top := stack[len(stack)-1]
+		path := append([]string{}, top.path...)
+		if top.isObj {
+			return append(path, top.key)
+		}
+		return append(path, strconv.Itoa(top.index))
+	}
+	// valueDone moves the parent on once one of its values has been read
+	valueDone := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.isObj {
+			top.expectKey = true
+		} else {
+			top.index++
+		}
+	}
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return
+		}
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if key, ok := t.(string); ok && top.isObj && top.expectKey {
+				if top.seen[key] {
+					pk := pathKey(top.path)
+					dups[pk] = append(dups[pk], key)
+				}
+				top.seen[key] = true
+				top.key = key
+				top.expectKey = false
+				continue
+			}
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			stack = append(stack, &keyFrame{
+				isObj:     t == json.Delim('{'),
+				path:      childPath(),
+				seen:      map[string]bool{},
+				expectKey: true,
+			})
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+}
+
+// dupKeysCmd looks for duplicate keys in the background
+func dupKeysCmd(data any) tea.Cmd {
+	return func() tea.Msg {
+		dups := findDuplicateKeys(data)
+		debugLog.Printf("found duplicate keys in %d objects", len(dups))
+		return dupKeysMsg{DupKeys: dups}
+	}
+}
+
+// isDupKey checks if a key at the current level appears more than once
+func (m *Model) isDupKey(key string) bool {
+	for _, k := range m.DupKeys[pathKey(m.Path)] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// dupKeyCount returns the number of duplicated keys in the document
+func (m *Model) dupKeyCount() int {
+	n := 0
+	for _, keys := range m.DupKeys {
+		n += len(keys)
+	}
+	return n
+}
@@ -0,0 +1,83 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// DuplicateGroup is a value that occurs at more than one path
+type DuplicateGroup struct {
+	Value string   // compact JSON of the value
+	Paths []string // every path the value occurs at
+}
+
+// findDuplicates is a utility function that groups identical values and subtrees
+// that occur at more than one path in the data
+// nulls, booleans and empty containers are too common to be interesting
+// so they are left out
+func findDuplicates(data any) []DuplicateGroup {
+	data = materialize(data)
+	seen := map[string][]string{}
+	var walk func(o any, path []string)
+	walk = func(o any, path []string) {
+		children := getKAny(o)
+		if tooDeep(path) {
+			return
+		}
+		if len(path) > 0 {
+			switch o.(type) {
+			case nil, bool:
+			default:
+				if children == nil || len(children) > 0 {
+					// marshalling sorts the keys so equal subtrees give the same string
+					content, err := json.Marshal(o)
+					if err == nil {
+						p := selectorPath(data, path)
+						seen[string(content)] = append(seen[string(content)], p)
+					}
+				}
+			}
+		}
+		for k, v := range children {
+			walk(v, append(append([]string{}, path...), k))
+		}
+	}
+	walk(data, []string{})
+	groups := []DuplicateGroup{}
+	for v, paths := range seen {
+		if len(paths) > 1 {
+			sort.Strings(paths)
+			groups = append(groups, DuplicateGroup{Value: v, Paths: paths})
+		}
+	}
+	// larger subtrees first since they are the most likely copy-paste errors
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].Value) != len(groups[j].Value) {
+			return len(groups[i].Value) > len(groups[j].Value)
+		}
+		return groups[i].Value < groups[j].Value
+	})
+	return groups
+}
+
+// duplicatesString returns a report of the duplicated values in the data
+func duplicatesString(data any) string {
+	groups := findDuplicates(data)
+	if len(groups) == 0 {
+		return "No duplicated values found"
+	}
+	s := ""
+	for _, g := range groups {
+		value := g.Value
+		// cut on a rune so that no character is split in half
+		if runes := []rune(value); len(runes) > 60 {
+			value = string(runes[:57]) + "..."
+		}
+		s += fmt.Sprintf("%d × %s\n", len(g.Paths), value)
+		for _, p := range g.Paths {
+			s += fmt.Sprintf("    %s\n", p)
+		}
+	}
+	return s
+}
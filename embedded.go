@@ -0,0 +1,139 @@
+package jv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// embedded is a format that string values can hold, which is laid out in a
+// readable form in the detail pane
+type embedded struct {
+	kind     string                        // name of the format, such as table
+	show     func(s string) (string, bool) // lays the string out, false if it is not of the format
+	text     func(s string) (string, bool) // the string as plain text, nil if the format has no such form
+	fileName string                        // file the layout gets written to on export
+}
+
+// embeds are tried in order on a string value when it is expanded
+var embeds = []embedded{
+	{"table", showTable, nil, "table.txt"},
+	{"markup", showMarkup, markupText, "markup.xml"},
+}
+
+// openEmbedded shows the string under the cursor laid out in the detail
+// pane if it holds a format that is known, and as it is otherwise
+func (m *Model) openEmbedded() {
+	s, ok := materializeScalar(m.currentNode()).(string)
+	if !ok || m.maskedAt(m.currentPath()) {
+		return
+	}
+	for _, e := range embeds {
+		if content, ok := e.show(s); ok {
+			m.openDetail(fmt.Sprintf("%s in %s", e.kind, sanitize(selectorPath(m.Data, m.currentPath()))), content, e.fileName)
+			if e.text != nil {
+				m.Detail.Text, _ = e.text(s)
+			}
+			return
+		}
+	}
+	m.openString(fmt.Sprintf("String at %s", sanitize(selectorPath(m.Data, m.currentPath()))), s)
+}
+
+// tableCellWidth is the widest a cell of an embedded table is shown
+const tableCellWidth = 40
+
+// markdownRule matches the line under the header of a markdown table
+var markdownRule = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)+\|?$`)
+
+// parseEmbeddedTable is a utility function that reads a markdown table or
+// CSV, tab or semicolon separated values from a string
+// it takes at least two rows of at least two columns, all the same width,
+// so that prose with commas in it is not mistaken for a table
+func parseEmbeddedTable(s string) (string, [][]string, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "\n") {
+		return "", nil, false
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > 2 && markdownRule.MatchString(strings.TrimSpace(lines[1])) {
+		rows := [][]string{}
+		for i, line := range lines {
+			if i == 1 {
+				continue
+			}
+			line = strings.Trim(strings.TrimSpace(line), "|")
+			cells := strings.Split(line, "|")
+			for j := range cells {
+				cells[j] = strings.TrimSpace(cells[j])
+			}
+			rows = append(rows, cells)
+		}
+		return "markdown", rows, true
+	}
+	for _, sep := range []struct {
+		name  string
+		comma rune
+	}{{"CSV", ','}, {"TSV", '\t'}, {"semicolon separated", ';'}} {
+		r := csv.NewReader(strings.NewReader(s))
+		r.Comma = sep.comma
+		r.TrimLeadingSpace = true
+		rows, err := r.ReadAll()
+		if err == nil && len(rows) >= 2 && len(rows[0]) >= 2 {
+			return sep.name, rows, true
+		}
+	}
+	return "", nil, false
+}
+
+// renderTable says that a string holds a table and how large it is
+func renderTable(value any) (string, bool) {
+	s, _ := value.(string)
+	kind, rows, ok := parseEmbeddedTable(s)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s table, %d rows × %d columns", kind, len(rows)-1, len(rows[0])), true
+}
+
+// showTable lays out a string that holds a table in aligned columns with
+// a rule under the header
+func showTable(s string) (string, bool) {
+	_, rows, ok := parseEmbeddedTable(s)
+	if !ok {
+		return "", false
+	}
+	widths := []int{}
+	for _, row := range rows {
+		for i, cell := range row {
+			n := utf8.RuneCountInString(sanitize(cell))
+			if n > tableCellWidth {
+				n = tableCellWidth
+			}
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	lines := []string{}
+	for r, row := range rows {
+		cells := []string{}
+		for i, cell := range row {
+			cells = append(cells, fitCell(sanitize(cell), widths[i]))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+		if r == 0 {
+			rule := []string{}
+			for _, w := range widths {
+				rule = append(rule, strings.Repeat("─", w))
+			}
+			lines = append(lines, strings.Join(rule, "  "))
+		}
+	}
+	return strings.Join(lines, "\n"), true
+}
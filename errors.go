@@ -0,0 +1,84 @@
+package jv
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// errEmptyInput is returned when the input has no JSON in it at all
+var errEmptyInput = errors.New("the input is empty")
+
+// SyntaxError describes where the input stops being valid JSON
+type SyntaxError struct {
+	Line   int    // line of the offending input, starting at 1
+	Column int    // column of the offending input, starting at 1
+	Text   string // the line containing the offending input
+	Err    error  // the underlying error from the decoder
+}
+
+// Error formats the error with the offending line and a marker under the column
+func (e *SyntaxError) Error() string {
+	prefix := fmt.Sprintf("%5d | ", e.Line)
+	s := fmt.Sprintf("invalid JSON at line %d, column %d: %s\n\n", e.Line, e.Column, e.Err)
+	s += prefix + e.Text + "\n"
+	s += strings.Repeat(" ", len(prefix)-2) + "| " + strings.Repeat(" ", e.Column-1) + "^"
+	return s
+}
+
+// Unwrap returns the underlying error from the decoder
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// newSyntaxError is a utility function that locates a decoding error in the input
+// errors without an offset are returned as they are
+func newSyntaxError(r io.ReaderAt, size int64, err error) error {
+	var offset int64
+	var serr *json.SyntaxError
+	var terr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &serr):
+		offset = serr.Offset
+	case errors.As(err, &terr):
+		offset = terr.Offset
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		offset = size
+	default:
+		return fmt.Errorf("cannot unmarshal JSON data: %w", err)
+	}
+	// count lines up to the offset without holding the input in memory
+	br := bufio.NewReader(io.NewSectionReader(r, 0, size))
+	line, lineStart := 1, int64(0)
+	for pos := int64(0); pos < offset; pos++ {
+		c, rerr := br.ReadByte()
+		if rerr != nil {
+			break
+		}
+		if c == '\n' && pos < offset-1 {
+			line++
+			lineStart = pos + 1
+		}
+	}
+	// the offset points just past the offending byte
+	column := int(offset - lineStart)
+	if column < 1 {
+		column = 1
+	}
+	text, _ := bufio.NewReader(io.NewSectionReader(r, lineStart, size-lineStart)).ReadString('\n')
+	text = strings.TrimRight(text, "\r\n")
+	// very long lines, like minified JSON, are cut down around the column
+	const window = 60
+	if column > window {
+		cut := column - window/2
+		text = "…" + string([]rune(text[cut:]))
+		column = column - cut + 1
+	}
+	if len([]rune(text)) > 2*window {
+		text = string([]rune(text)[:2*window]) + "…"
+	}
+	return &SyntaxError{Line: line, Column: column, Text: text, Err: err}
+}
@@ -0,0 +1,53 @@
+package jv
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+)
+
+// listingLines returns the current level with every row loaded so far,
+// not only the page shown, styled as it is on screen
+func (m *Model) listingLines() []string {
+	lines := []string{"You are here: " + style(sanitize(m.listingSelector()), styleBold), ""}
+	if len(m.CurrKV) == 0 {
+		return append(lines, m.emptyPlaceholder())
+	}
+	return append(lines, m.getPageItems(0, len(m.CurrKV))...)
+}
+
+// exportListing writes the current level to a file in the current directory
+// as plain text, or as HTML with the styling kept, to paste into tickets
+func (m *Model) exportListing(asHTML bool) {
+	if m.Flat && len(m.CurrKV) == 0 {
+		return
+	}
+	lines := m.listingLines()
+	name := exportFileName(m.Source, "listing.txt")
+	var content string
+	if asHTML {
+		name = exportFileName(m.Source, "listing.html")
+		for i, l := range lines {
+			lines[i] = styledHTML(l)
+		}
+		source := m.Source
+		if source == "" {
+			source = "stdin"
+		}
+		content = fmt.Sprintf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<pre>\n%s\n</pre>\n</body>\n</html>\n",
+			html.EscapeString(sanitize(source+" "+m.listingSelector())), strings.Join(lines, "\n"))
+	} else {
+		for i, l := range lines {
+			lines[i] = unstyle(l)
+		}
+		content = strings.Join(lines, "\n") + "\n"
+	}
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		debugLog.Printf("cannot write %s: %s", name, err)
+		m.Status = fmt.Sprintf("cannot write %s: %s", name, err)
+		return
+	}
+	m.Status = fmt.Sprintf("wrote %d rows to %s", len(m.CurrKV), name)
+	m.audit(auditExport, m.Path, name)
+}
@@ -0,0 +1,362 @@
+package jv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Exporter formats a value in another format, such as YAML or Go types
+// the export menu lists every exporter to format the value under the
+// cursor with
+type Exporter interface {
+	// Name is shown in the export menu and above the output
+	Name() string
+	// FileName is the file the output is written to from the detail pane
+	FileName() string
+	// Export formats the value
+	Export(v ExportValue) (string, error)
+}
+
+// ExportValue is the value an exporter formats
+type ExportValue struct {
+	Name  string // key of the value, types are named after it
+	Path  string // gron path of the value such as json.items[0]
+	Value any    // the value fully parsed, with its secrets masked
+}
+
+// exporters are listed in the export menu in order
+var exporters = []Exporter{
+	jsonExporter{},
+	yamlExporter{},
+	csvExporter{},
+	gronExporter{},
+	goExporter{},
+	tsExporter{},
+	tsExporter{Strict: true},
+	htmlExporter{},
+}
+
+// RegisterExporter adds an exporter to the end of the export menu, for
+// programs embedding the viewer that export to formats of their own
+func RegisterExporter(e Exporter) {
+	exporters = append(exporters, e)
+}
+
+// jsonExporter writes indented JSON
+type jsonExporter struct{}
+
+func (jsonExporter) Name() string     { return "JSON" }
+func (jsonExporter) FileName() string { return "value.json" }
+func (jsonExporter) Export(v ExportValue) (string, error) {
+	return indentedJson(v.Value)
+}
+
+// yamlExporter writes block style YAML
+type yamlExporter struct{}
+
+func (yamlExporter) Name() string     { return "YAML" }
+func (yamlExporter) FileName() string { return "value.yaml" }
+func (yamlExporter) Export(v ExportValue) (string, error) {
+	var b strings.Builder
+	writeYaml(&b, materialize(v.Value), "")
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// csvExporter writes an array as CSV with a row for every element
+type csvExporter struct{}
+
+func (csvExporter) Name() string     { return "CSV" }
+func (csvExporter) FileName() string { return "value.csv" }
+func (csvExporter) Export(v ExportValue) (string, error) {
+	return csvString(v.Value)
+}
+
+// gronExporter writes gron assignment statements
+type gronExporter struct{}
+
+func (gronExporter) Name() string     { return "gron" }
+func (gronExporter) FileName() string { return "gron.txt" }
+func (gronExporter) Export(v ExportValue) (string, error) {
+	return strings.Join(gronLines(v.Path, v.Value), "\n"), nil
+}
+
+// goExporter writes Go types with json tags
+type goExporter struct{}
+
+func (goExporter) Name() string     { return "Go types" }
+func (goExporter) FileName() string { return "types.go" }
+func (goExporter) Export(v ExportValue) (string, error) {
+	return goStructString(v.Name, v.Value), nil
+}
+
+// tsExporter writes TypeScript interfaces, marking keys that only some
+// array elements have as optional unless it is strict
+type tsExporter struct {
+	Strict bool
+}
+
+func (e tsExporter) Name() string {
+	if e.Strict {
+		return "TypeScript interfaces (strict)"
+	}
+	return "TypeScript interfaces"
+}
+func (tsExporter) FileName() string { return "types.ts" }
+func (e tsExporter) Export(v ExportValue) (string, error) {
+	return tsInterfaceString(v.Name, v.Value, !e.Strict), nil
+}
+
+// htmlExporter writes a page with the value as indented JSON
+type htmlExporter struct{}
+
+func (htmlExporter) Name() string     { return "HTML" }
+func (htmlExporter) FileName() string { return "value.html" }
+func (htmlExporter) Export(v ExportValue) (string, error) {
+	content, err := indentedJson(v.Value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<pre>\n%s\n</pre>\n</body>\n</html>",
+		html.EscapeString(sanitize(v.Path)), html.EscapeString(content)), nil
+}
+
+// indentedJson is a utility function that returns a value as indented JSON
+// keeping HTML characters as they are
+func indentedJson(o any) (string, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(materialize(o)); err != nil {
+		return "", fmt.Errorf("cannot format the value: %w", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// yamlPlain matches strings that YAML reads back as the same string
+// without quotes
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./-]*( [A-Za-z0-9_./-]+)*$`)
+
+// yamlText is a utility function that writes a string, number, boolean or
+// null as YAML, quoting strings that would be read as something else
+func yamlText(o any) string {
+	switch v := o.(type) {
+	case nil:
+		return "null"
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+		default:
+			if yamlPlain.MatchString(v) {
+				return v
+			}
+		}
+		quoted, _ := json.Marshal(v)
+		return string(quoted)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(o)
+}
+
+// writeYaml is a utility function that writes a parsed value as block
+// style YAML with every line starting with indent
+// object keys are written in order
+func writeYaml(b *strings.Builder, o any, indent string) {
+	switch v := o.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			b.WriteString(indent + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b.WriteString(indent + yamlText(k) + ":")
+			writeYamlChild(b, v[k], indent)
+		}
+	case []any:
+		if len(v) == 0 {
+			b.WriteString(indent + "[]\n")
+			return
+		}
+		for _, e := range v {
+			b.WriteString(indent + "-")
+			writeYamlChild(b, e, indent)
+		}
+	default:
+		b.WriteString(indent + yamlText(o) + "\n")
+	}
+}
+
+// writeYamlChild writes the value after a key or a dash, on the same line
+// unless it is a container with something in it
+// objects in arrays start on the line of their dash
+func writeYamlChild(b *strings.Builder, o any, indent string) {
+	switch v := o.(type) {
+	case map[string]any:
+		if len(v) > 0 && strings.HasSuffix(b.String(), "-") {
+			var item strings.Builder
+			writeYaml(&item, v, indent+"  ")
+			b.WriteString(" " + strings.TrimPrefix(item.String(), indent+"  "))
+			return
+		}
+		if len(v) > 0 {
+			b.WriteString("\n")
+			writeYaml(b, v, indent+"  ")
+			return
+		}
+		b.WriteString(" {}\n")
+		return
+	case []any:
+		if len(v) > 0 {
+			b.WriteString("\n")
+			writeYaml(b, v, indent+"  ")
+			return
+		}
+		b.WriteString(" []\n")
+		return
+	}
+	b.WriteString(" " + yamlText(o) + "\n")
+}
+
+// csvString is a utility function that writes an array as CSV
+// objects get a column for every key any of them has, in order, and other
+// values a single column, strings are written without their quotes and
+// anything else as compact JSON
+func csvString(o any) (string, error) {
+	rows, ok := materialize(o).([]any)
+	if !ok {
+		return "", fmt.Errorf("only arrays can be written as CSV")
+	}
+	columns := []string{}
+	seen := map[string]bool{}
+	objects := len(rows) > 0
+	for _, row := range rows {
+		obj, ok := row.(map[string]any)
+		if !ok {
+			objects = false
+			break
+		}
+		for k := range obj {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+	if !objects {
+		columns = []string{"value"}
+	}
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write(columns)
+	for _, row := range rows {
+		record := []string{clipboardValue(row)}
+		if objects {
+			obj := row.(map[string]any)
+			record = make([]string, len(columns))
+			for i, c := range columns {
+				if v, ok := obj[c]; ok {
+					record[i] = clipboardValue(v)
+				}
+			}
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("cannot write CSV: %w", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// ExportMenu is the list of exporters to format the value under the cursor
+// with
+type ExportMenu struct {
+	Cursor int // exporter that enter picks
+}
+
+// openExportMenu starts picking a format to export the value under the
+// cursor in
+func (m *Model) openExportMenu() {
+	if len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return
+	}
+	m.Exporting = &ExportMenu{}
+}
+
+// showExport shows the value under the cursor formatted by the exporter in
+// the detail pane, which writes it to a file
+// huge values are written straight to a file as JSON in the background
+func (m *Model) showExport(e Exporter) tea.Cmd {
+	if len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return nil
+	}
+	path := m.currentPath()
+	if _, ok := e.(jsonExporter); ok && inputSize(m.valueAt(path)) >= streamExportSize {
+		return m.streamExport(path, exportFileName(m.Source, e.FileName()), false)
+	}
+	content, err := e.Export(ExportValue{
+		Name:  m.CurrKV[m.CurrC.RowNo].Key,
+		Path:  gronPath(m.Data, path),
+		Value: m.exportValue(path),
+	})
+	if err != nil {
+		m.Status = fmt.Sprintf("cannot export %s as %s: %s", selectorPath(m.Data, path), e.Name(), err)
+		return nil
+	}
+	m.openDetail(fmt.Sprintf("%s of %s", e.Name(), selectorPath(m.Data, path)), content, e.FileName())
+	return nil
+}
+
+// updateExportMenu handles key presses while the export menu is open
+func (m *Model) updateExportMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	menu := m.Exporting
+	switch {
+	case key.Matches(msg, m.Keys.Quit):
+		return m, tea.Quit
+	case msg.Type == tea.KeyEsc:
+		m.Exporting = nil
+	case key.Matches(msg, m.Keys.Up):
+		if menu.Cursor > 0 {
+			menu.Cursor--
+		}
+	case key.Matches(msg, m.Keys.Down):
+		if menu.Cursor < len(exporters)-1 {
+			menu.Cursor++
+		}
+	case msg.Type == tea.KeyEnter:
+		m.Exporting = nil
+		return m, m.showExport(exporters[menu.Cursor])
+	}
+	return m, nil
+}
+
+// viewExportMenu renders the export menu
+func (m *Model) viewExportMenu() string {
+	s := fmt.Sprintf("Export %s as\n\n", style(sanitize(selectorPath(m.Data, m.currentPath())), styleBold))
+	for i, e := range exporters {
+		if i == m.Exporting.Cursor {
+			s += style("→ "+sanitize(e.Name()), styleBold) + "\n"
+		} else {
+			s += "  " + sanitize(e.Name()) + "\n"
+		}
+	}
+	s += "\n" + style("Export: enter  Cancel: esc  Up: ↑  Down: ↓", styleFaint) + "\n"
+	return s
+}
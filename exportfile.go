@@ -0,0 +1,238 @@
+package jv
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// streamExportSize is the size in the input from which a value exported as
+// JSON is written straight to a file in the background rather than shown in
+// the detail pane first
+const streamExportSize = 16 << 20
+
+// streamChunk is how many bytes are written between reports of progress
+// and checks for cancellation
+const streamChunk = 64 << 10
+
+// exportMsg is sent once a value has been written to a file in the
+// background
+type exportMsg struct {
+	Path  []string // path of the value written
+	File  string   // file it was written to
+	Bytes int64    // size of the file
+	Note  string   // said after the file is written, such as how many values were masked
+	Err   error
+}
+
+// jsonStreamer writes a value as indented JSON as it walks it, children of
+// values not parsed yet are parsed one at a time so that no copy of a huge
+// value is built in memory
+type jsonStreamer struct {
+	ctx      context.Context
+	w        *bufio.Writer
+	scalar   func(path []string, key string, o any) any // the value written in place of a scalar, such as a mask
+	total    int64                                      // size of the value in the input, 0 if it is not known
+	read     int64                                      // bytes of the input written so far
+	written  int64                                      // bytes written so far
+	reported int64                                      // bytes written when progress was last reported
+	progress func(int)
+}
+
+// inputSize is a utility function that returns the size of a value in the
+// input, 0 for values that have been parsed already
+func inputSize(o any) int64 {
+	switch v := o.(type) {
+	case fileSpan:
+		return v.size
+	case json.RawMessage:
+		return int64(len(v))
+	}
+	return 0
+}
+
+// jsonScalar is a utility function that encodes a scalar the way
+// indentedJson does, without escaping HTML characters
+func jsonScalar(o any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(o); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// write writes s, reporting progress as a percentage of the input and
+// stopping once the export is cancelled
+func (s *jsonStreamer) write(b []byte) error {
+	n, err := s.w.Write(b)
+	s.written += int64(n)
+	if err != nil {
+		return err
+	}
+	if s.written-s.reported >= streamChunk {
+		s.reported = s.written
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+		if s.total > 0 {
+			percent := int(s.read * 100 / s.total)
+			if percent > 99 {
+				percent = 99
+			}
+			s.progress(percent)
+		}
+	}
+	return nil
+}
+
+// value writes o at the indentation of depth, key is the key it is under
+// or the key of the array it is in
+func (s *jsonStreamer) value(o any, path []string, key string, depth int) error {
+	keys, children := orderedChildren(o)
+	if keys == nil {
+		out := materialize(o)
+		if s.scalar != nil {
+			out = s.scalar(path, key, out)
+		}
+		b, err := jsonScalar(out)
+		if err != nil {
+			return err
+		}
+		if n := inputSize(o); n > 0 {
+			s.read += n
+		} else {
+			s.read += int64(len(b))
+		}
+		return s.write(b)
+	}
+	// the brackets, and the quotes, colons and commas around the children
+	// count towards the input read
+	s.read += 2
+	open, close := "{", "}"
+	arr := isArray(o)
+	if arr {
+		open, close = "[", "]"
+	}
+	if len(keys) == 0 {
+		return s.write([]byte(open + close))
+	}
+	indent := strings.Repeat("  ", depth+1)
+	if err := s.write([]byte(open + "\n")); err != nil {
+		return err
+	}
+	for i, k := range keys {
+		if err := s.write([]byte(indent)); err != nil {
+			return err
+		}
+		childKey := key
+		s.read++
+		if !arr {
+			childKey = k
+			s.read += int64(len(k)) + 3
+			b, _ := jsonScalar(k)
+			if err := s.write(append(b, ": "...)); err != nil {
+				return err
+			}
+		}
+		child := append(append([]string{}, path...), k)
+		if err := s.value(children[k], child, childKey, depth+1); err != nil {
+			return err
+		}
+		sep := ",\n"
+		if i == len(keys)-1 {
+			sep = "\n"
+		}
+		if err := s.write([]byte(sep)); err != nil {
+			return err
+		}
+	}
+	return s.write([]byte(strings.Repeat("  ", depth) + close))
+}
+
+// writeJsonFile is a utility function that streams o as indented JSON to
+// name, through a file next to it that takes its place once it is complete
+// so that a cancelled export leaves nothing behind
+func writeJsonFile(ctx context.Context, name string, o any, path []string,
+	scalar func(path []string, key string, o any) any, progress func(int)) (int64, error) {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return 0, fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	s := &jsonStreamer{ctx: ctx, w: bufio.NewWriter(f), scalar: scalar, total: inputSize(o), progress: progress}
+	err = s.value(o, path, "", 0)
+	if err == nil {
+		err = s.write([]byte("\n"))
+	}
+	if err == nil {
+		err = s.w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return 0, fmt.Errorf("cannot write %s: %w", name, err)
+	}
+	return s.written, nil
+}
+
+// streamExport writes the value at path as JSON to a file in the current
+// directory in the background, with its progress shown and cancellable
+// secrets are masked as they are on screen, or all of them if every is set
+func (m *Model) streamExport(path []string, name string, every bool) tea.Cmd {
+	// the masking is worked out from a copy of what is revealed since the
+	// task runs alongside the viewer
+	view := &Model{Masking: m.Masking || every, Revealed: map[string]bool{}}
+	for k, v := range m.Revealed {
+		view.Revealed[k] = v
+	}
+	masked := 0
+	// a copy of the document to share keeps the type and length of every
+	// secret, the elements of an array are secrets when the array is
+	scalar := func(path []string, key string, o any) any {
+		switch {
+		case every && isSecret(key, o):
+			masked++
+			return secretPlaceholder(o)
+		case !every && len(path) > 0 && view.masked(path) && isSecret(path[len(path)-1], o):
+			return secretMask
+		}
+		return o
+	}
+	o := m.valueAt(path)
+	title := fmt.Sprintf("exporting %s to %s", selectorPath(m.Data, path), name)
+	return m.startProgressTask("export", title, func(ctx context.Context, progress func(int)) tea.Msg {
+		n, err := writeJsonFile(ctx, name, o, path, scalar, progress)
+		msg := exportMsg{Path: path, File: name, Bytes: n, Err: err}
+		if every {
+			msg.Note = fmt.Sprintf(" with %d values masked", masked)
+		}
+		return msg
+	})
+}
+
+// finishExport says where the value was written once it is
+func (m *Model) finishExport(msg exportMsg) {
+	if msg.Err != nil {
+		debugLog.Printf("%s", msg.Err)
+		m.Status = msg.Err.Error()
+		return
+	}
+	m.Status = fmt.Sprintf("wrote %s of %s to %s%s", humanBytes(msg.Bytes), selectorPath(m.Data, msg.Path), msg.File, msg.Note)
+	m.audit(auditExport, msg.Path, msg.File)
+}
@@ -0,0 +1,298 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// expr is a small expression over a value such as len(.containers) or
+// .limits.cpu - .requests.cpu, the computed columns of tables are made of
+// them
+// selectors end at a space like they do everywhere else, so operators are
+// written with spaces around them
+type expr interface {
+	eval(o any) (any, error)
+}
+
+// exprPath is the value at a selector, null if it is missing
+type exprPath []string
+
+// exprLiteral is a number or a string written out
+type exprLiteral struct {
+	value any
+}
+
+// exprCall is a function applied to the value of an expression
+type exprCall struct {
+	name string
+	arg  expr
+}
+
+// exprBinary is an arithmetic operation on the values of two expressions
+type exprBinary struct {
+	op   byte
+	a, b expr
+}
+
+// exprFunctions are the functions expressions can call
+var exprFunctions = map[string]func(any) (any, error){
+	// len is the number of characters of a string or children of an object
+	// or array
+	"len": func(o any) (any, error) {
+		switch v := o.(type) {
+		case string:
+			return float64(utf8.RuneCountInString(v)), nil
+		case []any:
+			return float64(len(v)), nil
+		case map[string]any:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("cannot take the length of %s", getVal(o))
+	},
+	// number reads a number out of a string such as "2.5"
+	"number": func(o any) (any, error) {
+		f, ok := exprNumber(o)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a number", getVal(o))
+		}
+		return f, nil
+	},
+}
+
+// exprNumber is a utility function that returns a value as a number, and
+// false if it is not one or a string holding one
+func exprNumber(o any) (float64, bool) {
+	switch v := o.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (p exprPath) eval(o any) (any, error) {
+	for _, k := range p {
+		child, ok := getKAny(o)[k]
+		if !ok {
+			return nil, nil
+		}
+		o = child
+	}
+	return materialize(o), nil
+}
+
+func (l exprLiteral) eval(any) (any, error) {
+	return l.value, nil
+}
+
+func (c exprCall) eval(o any) (any, error) {
+	v, err := c.arg.eval(o)
+	if err != nil {
+		return nil, err
+	}
+	return exprFunctions[c.name](v)
+}
+
+func (b exprBinary) eval(o any) (any, error) {
+	x, err := b.a.eval(o)
+	if err != nil {
+		return nil, err
+	}
+	y, err := b.b.eval(o)
+	if err != nil {
+		return nil, err
+	}
+	// strings are joined by +
+	if xs, ok := x.(string); ok && b.op == '+' {
+		if ys, ok := y.(string); ok {
+			return xs + ys, nil
+		}
+	}
+	xf, xok := x.(float64)
+	if n, ok := x.(json.Number); ok {
+		xf, xok = exprNumber(n)
+	}
+	yf, yok := y.(float64)
+	if n, ok := y.(json.Number); ok {
+		yf, yok = exprNumber(n)
+	}
+	if !xok || !yok {
+		return nil, fmt.Errorf("cannot apply %c to %s and %s", b.op, getVal(x), getVal(y))
+	}
+	switch b.op {
+	case '+':
+		return xf + yf, nil
+	case '-':
+		return xf - yf, nil
+	case '*':
+		return xf * yf, nil
+	}
+	if yf == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	return xf / yf, nil
+}
+
+// exprParser reads an expression from its text
+type exprParser struct {
+	s   string // the text
+	pos int    // where reading is up to
+}
+
+// parseExpr is a utility function that reads an expression
+func parseExpr(s string) (expr, error) {
+	p := &exprParser{s: s}
+	e, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.skip(); p.pos < len(p.s) {
+		return nil, fmt.Errorf("unexpected %q in %s", p.s[p.pos:], s)
+	}
+	return e, nil
+}
+
+// skip moves past spaces
+func (p *exprParser) skip() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// sum reads terms added or subtracted
+func (p *exprParser) sum() (expr, error) {
+	e, err := p.product()
+	for err == nil {
+		p.skip()
+		if p.pos == len(p.s) || (p.s[p.pos] != '+' && p.s[p.pos] != '-') {
+			break
+		}
+		op := p.s[p.pos]
+		p.pos++
+		var b expr
+		if b, err = p.product(); err == nil {
+			e = exprBinary{op: op, a: e, b: b}
+		}
+	}
+	return e, err
+}
+
+// product reads operands multiplied or divided
+func (p *exprParser) product() (expr, error) {
+	e, err := p.operand()
+	for err == nil {
+		p.skip()
+		if p.pos == len(p.s) || (p.s[p.pos] != '*' && p.s[p.pos] != '/') {
+			break
+		}
+		op := p.s[p.pos]
+		p.pos++
+		var b expr
+		if b, err = p.operand(); err == nil {
+			e = exprBinary{op: op, a: e, b: b}
+		}
+	}
+	return e, err
+}
+
+// operand reads a selector, a literal, a call or an expression in
+// parentheses
+func (p *exprParser) operand() (expr, error) {
+	p.skip()
+	if p.pos == len(p.s) {
+		return nil, fmt.Errorf("%s ends early", p.s)
+	}
+	rest := p.s[p.pos:]
+	switch c := rest[0]; {
+	case c == '.':
+		// a selector ends at a space or at the parenthesis or comma after it
+		end := strings.IndexAny(rest, " (),")
+		if end < 0 {
+			end = len(rest)
+		}
+		keys, err := selectorKeys(rest[:end])
+		if err != nil {
+			return nil, err
+		}
+		p.pos += end
+		return exprPath(keys), nil
+	case c == '(':
+		p.pos++
+		e, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(')')
+	case c == '"':
+		end := 1
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		var s string
+		if end >= len(rest) || json.Unmarshal([]byte(rest[:end+1]), &s) != nil {
+			return nil, fmt.Errorf("invalid string in %s", p.s)
+		}
+		p.pos += end + 1
+		return exprLiteral{s}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		if c == '-' && (len(rest) == 1 || !isDigit(rest[1])) {
+			// a minus before anything else negates it
+			p.pos++
+			e, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return exprBinary{op: '-', a: exprLiteral{0.0}, b: e}, nil
+		}
+		end := 1
+		for end < len(rest) && strings.IndexByte("0123456789.eE", rest[end]) >= 0 {
+			end++
+		}
+		f, err := strconv.ParseFloat(rest[:end], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", rest[:end])
+		}
+		p.pos += end
+		return exprLiteral{f}, nil
+	case c >= 'a' && c <= 'z':
+		end := 1
+		for end < len(rest) && rest[end] >= 'a' && rest[end] <= 'z' {
+			end++
+		}
+		name := rest[:end]
+		if _, ok := exprFunctions[name]; !ok {
+			return nil, fmt.Errorf("unknown function %s", name)
+		}
+		p.pos += end
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		arg, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		return exprCall{name: name, arg: arg}, p.expect(')')
+	}
+	return nil, fmt.Errorf("unexpected %q in %s", rest, p.s)
+}
+
+// expect moves past the character c, which has to come next
+func (p *exprParser) expect(c byte) error {
+	p.skip()
+	if p.pos == len(p.s) || p.s[p.pos] != c {
+		return fmt.Errorf("expected %c in %s", c, p.s)
+	}
+	p.pos++
+	return nil
+}
@@ -0,0 +1,80 @@
+package jv
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseExpr(t *testing.T) {
+	row := `{"name": "web", "containers": [1, 2, 3], "limits": {"cpu": 4}, "requests": {"cpu": "1.5"}, "a-b": 2}`
+	tests := []struct {
+		expr    string
+		want    any
+		wantErr string
+	}{
+		{expr: `len(.containers)`, want: 3.0},
+		{expr: `len(.name)`, want: 3.0},
+		{expr: `.limits.cpu - number(.requests.cpu)`, want: 2.5},
+		{expr: `.limits.cpu * 2 + 1`, want: 9.0},
+		{expr: `1 + .limits.cpu * 2`, want: 9.0},
+		{expr: `(1 + .limits.cpu) * 2`, want: 10.0},
+		{expr: `10 - 4 - 3`, want: 3.0},
+		{expr: `12 / 3 / 2`, want: 2.0},
+		{expr: `-.limits.cpu`, want: -4.0},
+		{expr: `-1.5e1`, want: -15.0},
+		{expr: `.name + "-" + .name`, want: "web-web"},
+		{expr: `.["a-b"] * 2`, want: 4.0},
+		{expr: `.missing`, want: nil},
+		{expr: `len(.containers`, wantErr: "expected )"},
+		{expr: `size(.containers)`, wantErr: "unknown function size"},
+		{expr: `1 +`, wantErr: "ends early"},
+		{expr: `1 2`, wantErr: "unexpected"},
+		{expr: `"open`, wantErr: "invalid string"},
+		{expr: `1..2`, wantErr: "invalid number"},
+		{expr: `.a[`, wantErr: "unterminated index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := parseExpr(tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := e.eval(json.RawMessage(row))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	row := json.RawMessage(`{"name": "web", "n": 0, "ok": true}`)
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{`10 / .n`, "division by zero"},
+		{`.name * 2`, "cannot apply *"},
+		{`len(.ok)`, "cannot take the length"},
+		{`number(.name)`, "is not a number"},
+	}
+	for _, tt := range tests {
+		e, err := parseExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.expr, err)
+		}
+		if _, err := e.eval(row); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got error %v, want %q", tt.expr, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package jv
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateError handles the keys of the screen shown when the input cannot
+// be loaded, which offers to load it again or to open another file
+// the error stays set on quitting so that the exit status reports it
+func (m *Model) updateError(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.Opening {
+		return m.updateOpen(msg)
+	}
+	switch {
+	case key.Matches(msg, m.Keys.Quit), key.Matches(msg, m.Keys.Close):
+		return m, tea.Quit
+	case key.Matches(msg, m.Keys.Retry):
+		if m.canRetry() {
+			return m, m.reloadInPlace()
+		}
+	case key.Matches(msg, m.Keys.Open):
+		m.Opening, m.OpenPath = true, pathInput{}
+	}
+	return m, nil
+}
+
+// updateOpen handles typing the path of a file to open instead
+func (m *Model) updateOpen(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.Opening = false
+	case tea.KeyEnter:
+		if m.OpenPath.Value == "" {
+			return m, nil
+		}
+		m.Opening = false
+		// the start path was meant for the input that failed
+		m.Start = nil
+		return m, m.reload(m.OpenPath.path(), false)
+	default:
+		m.OpenPath.update(msg)
+	}
+	return m, nil
+}
+
+// canRetry checks if loading the input again could work, which it cannot
+// for stdin that has already been read or for JSON handed over in memory
+func (m *Model) canRetry() bool {
+	return m.loaded == nil && !(stdinSource{}).Detect(m.Source)
+}
+
+// reload forgets the document shown so far and loads the input at path
+func (m *Model) reload(path string, git bool) tea.Cmd {
+	debugLog.Printf("reloading from %s", path)
+	m.Source, m.Git = path, git
+	m.loaded = nil
+	m.spot = nil
+	m.Err = nil
+	m.Loading = true
+	m.Progress = &loadProgress{}
+	closeInput(m.Data)
+	m.Data, m.Scalar = nil, false
+	m.Path, m.Nodes, m.level = []string{}, nil, nil
+	m.KVCache, m.Shown, m.Samples, m.Slices = map[string][]KVPair{}, map[string]int{}, nil, nil
+	m.cancelTasks(true)
+	m.Index = nil
+	m.DupKeys, m.Relaxed, m.Violations, m.Jumps = nil, nil, nil, nil
+	m.Flat, m.Filter, m.Filtering = false, "", false
+	m.CurrKV, m.rowCache = nil, nil
+	m.Table, m.Stats, m.inferred = nil, nil, nil
+	m.Changes, m.changed, m.previous = nil, nil, nil
+	m.Beside = nil
+	m.resetCursor()
+	return m.Init()
+}
+
+// viewError renders the error that stopped the input from loading
+func (m *Model) viewError() string {
+	source := m.Source
+	if source == "" {
+		source = "stdin"
+	}
+	s := style("Cannot load "+sanitize(source), styleBold) + "\n\n"
+	s += m.Err.Error() + "\n\n"
+	if m.Opening {
+		s += m.OpenPath.view("Open", m.Width) + "\n\n"
+	}
+	s += style(m.Keys.errorHelp(m.canRetry()), styleFaint) + "\n"
+	return s
+}
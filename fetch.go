@@ -0,0 +1,142 @@
+package jv
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// FetchOptions control how input given as a URL is fetched
+type FetchOptions struct {
+	Headers []string // extra request headers written as "Name: value"
+	User    string   // user:password for basic auth
+	CACert  string   // PEM file of certificate authorities to trust
+	Proxy   string   // proxy URL, the environment's proxy settings are used if empty
+}
+
+// fetchOptions are the options in use, they can be set from the command line
+var fetchOptions FetchOptions
+
+// headerList collects the repeatable -header flag
+type headerList []string
+
+// String returns the headers given so far
+func (h *headerList) String() string {
+	return strings.Join(*h, ", ")
+}
+
+// Set adds a header, checking that it has a name and a value
+func (h *headerList) Set(s string) error {
+	name, _, ok := strings.Cut(s, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected Name: value but got %q", s)
+	}
+	*h = append(*h, s)
+	return nil
+}
+
+// sameOrigin checks if two URLs have the same scheme, host and port
+func sameOrigin(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil || !isURL(a) {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil || !isURL(b) {
+		return false
+	}
+	return ua.Scheme == ub.Scheme && strings.EqualFold(ua.Host, ub.Host)
+}
+
+// isURL checks if the input is a URL to fetch rather than a file
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// httpClient is a utility function that builds the client used to fetch
+// input with the CA bundle and proxy from the options
+func httpClient(opts FetchOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Proxy != "" {
+		proxy, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %s: %w", opts.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.CACert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// urlSource fetches input from an http or https URL with the fetch options
+type urlSource struct {
+	Anonymous bool // the headers and credentials of the options are left out
+}
+
+// Detect reports whether the path is a URL
+func (urlSource) Detect(path string) bool {
+	return isURL(path)
+}
+
+// Open sends the request and returns the body of a successful response
+func (s urlSource) Open(ctx context.Context, rawURL string) (io.ReadCloser, int64, error) {
+	client, err := httpClient(fetchOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot fetch %s: %w", rawURL, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.Anonymous {
+		return fetchResponse(client, req)
+	}
+	for _, h := range fetchOptions.Headers {
+		name, val, _ := strings.Cut(h, ":")
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(val))
+	}
+	if fetchOptions.User != "" {
+		user, password, _ := strings.Cut(fetchOptions.User, ":")
+		req.SetBasicAuth(user, password)
+	}
+	return fetchResponse(client, req)
+}
+
+// fetchResponse is a utility function that sends the request and returns
+// the body of a successful response
+func fetchResponse(client *http.Client, req *http.Request) (io.ReadCloser, int64, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot fetch JSON input: %w", err)
+	}
+	debugLog.Printf("fetched %s: %s", req.URL.Redacted(), resp.Status)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("cannot fetch %s: %s", req.URL.Redacted(), resp.Status)
+	}
+	// files served as they are stored may still be gzipped, which reading
+	// the source takes care of
+	return resp.Body, resp.ContentLength, nil
+}
+
+// Watch cannot tell when what a URL serves changes
+func (urlSource) Watch(ctx context.Context, path string) (<-chan struct{}, error) {
+	return nil, errNoWatch
+}
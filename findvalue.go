@@ -0,0 +1,78 @@
+package jv
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// occurrencesMsg carries every leaf holding the value looked for
+type occurrencesMsg struct {
+	Label   string   // the value as it is shown
+	Results []KVPair // leaves holding the value
+}
+
+// findValue is a utility function that returns every leaf in data whose
+// value is exactly the given one, of the same type too so that "1" and 1
+// are told apart, stopping early once ctx is cancelled
+func findValue(ctx context.Context, data any, value any) []KVPair {
+	label := getVal(value)
+	found := []KVPair{}
+	walkLeaves(data, data, []string{}, func(kv KVPair) bool {
+		// leaves are compared as text first so that only the few that look
+		// the same get parsed
+		if kv.Value != label {
+			return ctx.Err() == nil
+		}
+		o := data
+		for _, k := range kv.Path {
+			o = getKAny(o)[k]
+		}
+		if jsonEqual(materializeScalar(o), value) {
+			found = append(found, kv)
+		}
+		return ctx.Err() == nil
+	})
+	return found
+}
+
+// findOccurrences lists every place the value under the cursor appears,
+// such as an id referred to from elsewhere in the document
+func (m *Model) findOccurrences() tea.Cmd {
+	if m.Flat || len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return nil
+	}
+	value := materializeScalar(m.currentNode())
+	if getKAny(value) != nil {
+		m.Status = "only strings, numbers, booleans and null can be looked for"
+		return nil
+	}
+	label := diffValue(value)
+	if m.maskedAt(m.currentPath()) {
+		label = secretMask
+	}
+	data := m.Data
+	return m.startTask("occurrences", fmt.Sprintf("looking for %s", label), func(ctx context.Context, _ func(int)) tea.Msg {
+		found := findValue(ctx, data, value)
+		if ctx.Err() != nil {
+			return nil
+		}
+		sortLeaves(found)
+		return occurrencesMsg{Label: label, Results: found}
+	})
+}
+
+// showOccurrences lists the leaves holding the value in the flattened view,
+// where enter goes to one of them
+func (m *Model) showOccurrences(msg occurrencesMsg) {
+	if m.Flat {
+		return
+	}
+	m.Flat, m.Filter = true, ""
+	m.Occurrence, m.occurrences = msg.Label, msg.Results
+	m.resetCursor()
+	m.updateKV()
+	m.syncPage()
+	m.Status = fmt.Sprintf("%s appears %d times, %s goes to one", msg.Label, len(msg.Results), m.Keys.Expand.Help().Key)
+}
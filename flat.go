@@ -0,0 +1,160 @@
+package jv
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// flatLeaves is a utility function that returns every leaf in the data
+// as a key-value pair whose key is the full path to the leaf
+// empty objects and arrays count as leaves
+// progress, if not nil, gets called with the number of leaves found so far
+// walking stops early once ctx is cancelled
+func flatLeaves(ctx context.Context, data any, progress func(int)) []KVPair {
+	leaves := []KVPair{}
+	walkLeaves(data, data, []string{}, func(leaf KVPair) bool {
+		leaves = append(leaves, leaf)
+		if progress != nil && len(leaves)%1000 == 0 {
+			progress(len(leaves))
+		}
+		return ctx.Err() == nil
+	})
+	sortLeaves(leaves)
+	return leaves
+}
+
+// walkLeaves is a utility function that calls visit for every leaf under o
+// which is found at path in data
+// walking stops as soon as visit returns false
+func walkLeaves(data any, o any, path []string, visit func(KVPair) bool) bool {
+	return walkLeavesFrom(o, path, selectorPath(data, path), visit)
+}
+
+// walkLeavesFrom walks the leaves under o whose selector is sel
+// building the selectors of the children from it as it goes
+func walkLeavesFrom(o any, path []string, sel string, visit func(KVPair) bool) bool {
+	children := walkKAny(o)
+	// containers past the nesting limit are treated as leaves
+	if (len(children) == 0 || tooDeep(path)) && len(path) > 0 {
+		return visit(KVPair{
+			Key:   sel,
+			Value: getVal(o),
+			Path:  path,
+		})
+	}
+	arr := isArray(o)
+	for k, v := range children {
+		// the root selector is a lone dot that the first key replaces
+		child := sel
+		if child == "." {
+			child = ""
+		}
+		if arr {
+			child += "[" + k + "]"
+		} else {
+			child += gronKey(k)
+		}
+		if strings.HasPrefix(child, "[") {
+			child = "." + child
+		}
+		if !walkLeavesFrom(v, append(append([]string{}, path...), k), child, visit) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkKAny is a utility function that returns the children of o like
+// getKAny without indexing those of a streamed container, for walks over
+// the whole input that would otherwise keep all of it in memory
+func walkKAny(o any) map[string]any {
+	if span, ok := o.(fileSpan); ok {
+		return span.walkChildren()
+	}
+	return getKAny(o)
+}
+
+// sortLeaves is a utility function that sorts leaves by their path
+// so that array elements stay in order
+func sortLeaves(leaves []KVPair) {
+	sort.Slice(leaves, func(i, j int) bool {
+		return pathLess(leaves[i].Path, leaves[j].Path)
+	})
+}
+
+// pathLess compares two paths one key at a time
+// indices are compared as numbers so [2] comes before [10]
+func pathLess(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		x, errX := strconv.Atoi(a[i])
+		y, errY := strconv.Atoi(b[i])
+		if errX == nil && errY == nil && x != y {
+			return x < y
+		}
+		return a[i] < b[i]
+	}
+	return len(a) < len(b)
+}
+
+// filterKV is a utility function that keeps the key-value pairs
+// whose key or value contains the filter text, ignoring case
+func filterKV(kvpairs []KVPair, filter string) []KVPair {
+	if filter == "" {
+		return kvpairs
+	}
+	filter = strings.ToLower(filter)
+	filtered := []KVPair{}
+	for _, kv := range kvpairs {
+		if matchesQuery(kv, filter) {
+			filtered = append(filtered, kv)
+		}
+	}
+	return filtered
+}
+
+// updateFilter handles key presses while the filter text is being typed
+func (m *Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.Completions = nil
+	// the filter searches every leaf rather than the occurrences listed
+	m.Occurrence, m.occurrences = "", nil
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	// tab completes a filter such as .spec.tem like a path to go to
+	case tea.KeyTab:
+		if !strings.HasPrefix(m.Filter, ".") {
+			return m, nil
+		}
+		m.Filter, m.Completions = m.completePath(m.Filter)
+	// enter or esc stops typing and keeps the filter
+	case tea.KeyEnter, tea.KeyEsc:
+		m.Filtering = false
+		return m, nil
+	case tea.KeyBackspace:
+		if len(m.Filter) > 0 {
+			runes := []rune(m.Filter)
+			m.Filter = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.Filter += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	// the results replace the listing once the search finishes
+	return m, m.startSearch()
+}
+
+// jumpToLeaf leaves the flattened view and navigates to the leaf under the cursor
+func (m *Model) jumpToLeaf() {
+	leaf := m.CurrKV[m.CurrC.RowNo]
+	m.Flat = false
+	m.Filter = ""
+	m.showKey(leaf.Path)
+}
@@ -0,0 +1,162 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keyFormat shows the values of keys matching a pattern with a formatter
+type keyFormat struct {
+	Pattern string // glob the key is matched with, such as *_ms
+	Format  string // name of the formatter, such as duration_ms
+}
+
+// keyFormats are tried in order on the key of every value, they are set
+// from the [formats] table of the config file
+var keyFormats []keyFormat
+
+// formatters show a string or number of a known unit in a readable form
+// none shows the value without the renderers
+var formatters = map[string]func(value any) (string, bool){
+	"duration_s":  formatDuration(time.Second),
+	"duration_ms": formatDuration(time.Millisecond),
+	"duration_us": formatDuration(time.Microsecond),
+	"duration_ns": formatDuration(time.Nanosecond),
+	"size":        formatSize,
+	"date":        formatDate,
+	"percent":     formatPercent,
+	"none":        func(any) (string, bool) { return "", true },
+}
+
+// checkFormat is a utility function that checks a formatter name
+func checkFormat(name string) error {
+	if _, ok := formatters[name]; ok {
+		return nil
+	}
+	names := []string{}
+	for n := range formatters {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown formatter %s, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// sortedFormats is a utility function that orders the patterns of the config
+// file so that the most specific, the longest, are tried first
+func sortedFormats(formats map[string]string) []keyFormat {
+	sorted := []keyFormat{}
+	for pattern, format := range formats {
+		sorted = append(sorted, keyFormat{Pattern: pattern, Format: format})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i].Pattern) != len(sorted[j].Pattern) {
+			return len(sorted[i].Pattern) > len(sorted[j].Pattern)
+		}
+		return sorted[i].Pattern < sorted[j].Pattern
+	})
+	return sorted
+}
+
+// formatKeyValue is a utility function that returns the readable form of a
+// string or number from the formatter of the first pattern its key matches
+// ok is false when no pattern matches or the value is not of its unit, so
+// the renderers are tried instead
+// the formatters apply even when the renderers are turned off
+func formatKeyValue(key string, o any) (string, bool) {
+	switch o.(type) {
+	case string, json.Number:
+	default:
+		return "", false
+	}
+	for _, f := range keyFormats {
+		if matched, _ := path.Match(f.Pattern, key); !matched {
+			continue
+		}
+		if s, ok := formatters[f.Format](o); ok {
+			return s, true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// formatKey is a utility function that returns the key the formatters are
+// matched with, elements of an array take the key of the array so that
+// the elements of durations_ms are durations too
+func formatKey(path []string, index bool) string {
+	switch {
+	case len(path) == 0:
+		return ""
+	case index && len(path) > 1:
+		return path[len(path)-2]
+	}
+	return path[len(path)-1]
+}
+
+// formatNumber is a utility function that reads a number, written as a
+// JSON number or as a string
+func formatNumber(value any) (float64, bool) {
+	var s string
+	switch v := value.(type) {
+	case json.Number:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+// formatDuration returns a formatter of numbers counting the given unit
+func formatDuration(unit time.Duration) func(any) (string, bool) {
+	return func(value any) (string, bool) {
+		n, ok := formatNumber(value)
+		if !ok {
+			return "", false
+		}
+		return time.Duration(n * float64(unit)).String(), true
+	}
+}
+
+// formatSize shows a number of bytes in KB, MB and so on
+func formatSize(value any) (string, bool) {
+	n, ok := formatNumber(value)
+	if !ok || n < 0 {
+		return "", false
+	}
+	return humanBytes(int64(n)), true
+}
+
+// formatDate shows RFC 3339 times and Unix times in seconds or
+// milliseconds, which are told apart by their size
+// unlike the timestamp renderer any Unix time is taken
+func formatDate(value any) (string, bool) {
+	if s, ok := renderTimestamp(value); ok {
+		return s, true
+	}
+	n, ok := formatNumber(value)
+	if !ok {
+		return "", false
+	}
+	t := time.Unix(int64(n), 0)
+	if max := float64(epoch.max); n >= max || n <= -max {
+		t = time.UnixMilli(int64(n))
+	}
+	return t.Local().Format("2006-01-02 15:04:05 MST") + ", " + relativeTime(time.Since(t)), true
+}
+
+// formatPercent shows a fraction such as 0.25 as 25%
+func formatPercent(value any) (string, bool) {
+	n, ok := formatNumber(value)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatFloat(n*100, 'f', -1, 64) + "%", true
+}
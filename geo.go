@@ -0,0 +1,203 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// plot size of the bounding box map in characters
+const (
+	plotWidth  = 60
+	plotHeight = 20
+)
+
+// bbox is a bounding box of GeoJSON coordinates
+type bbox struct {
+	MinX, MinY, MaxX, MaxY float64
+	Points                 int // number of positions inside
+}
+
+// add grows the box to take in a position
+func (b *bbox) add(x, y float64) {
+	if b.Points == 0 {
+		b.MinX, b.MaxX, b.MinY, b.MaxY = x, x, y, y
+	}
+	b.MinX, b.MaxX = math.Min(b.MinX, x), math.Max(b.MaxX, x)
+	b.MinY, b.MaxY = math.Min(b.MinY, y), math.Max(b.MaxY, y)
+	b.Points++
+}
+
+// merge grows the box to take in another one
+func (b *bbox) merge(o bbox) {
+	if o.Points == 0 {
+		return
+	}
+	points := b.Points
+	b.add(o.MinX, o.MinY)
+	b.add(o.MaxX, o.MaxY)
+	b.Points = points + o.Points
+}
+
+// geoNumber reads a coordinate
+func geoNumber(o any) (float64, bool) {
+	n, ok := o.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+// addCoordinates adds the positions nested anywhere in coordinates to the box
+// a position is an array starting with two numbers
+func (b *bbox) addCoordinates(coordinates any) {
+	arr, ok := coordinates.([]any)
+	if !ok {
+		return
+	}
+	if len(arr) >= 2 {
+		x, okx := geoNumber(arr[0])
+		y, oky := geoNumber(arr[1])
+		if okx && oky {
+			b.add(x, y)
+			return
+		}
+	}
+	for _, c := range arr {
+		b.addCoordinates(c)
+	}
+}
+
+// geometryBox returns the geometry's type and bounding box, taking in the
+// members of a GeometryCollection
+func geometryBox(geometry any) (string, bbox) {
+	var b bbox
+	g, ok := geometry.(map[string]any)
+	if !ok {
+		return "no geometry", b
+	}
+	kind, _ := g["type"].(string)
+	if kind == "GeometryCollection" {
+		members, _ := g["geometries"].([]any)
+		for _, member := range members {
+			_, mb := geometryBox(member)
+			b.merge(mb)
+		}
+		return kind, b
+	}
+	b.addCoordinates(g["coordinates"])
+	return kind, b
+}
+
+// isGeoJSON checks if a materialized value is a GeoJSON object of the given type
+func isGeoJSON(o any, kind string) bool {
+	m, ok := o.(map[string]any)
+	return ok && m["type"] == kind
+}
+
+// featureSummary is a utility function that sums up a feature as its
+// geometry type, bounding box and number of properties
+func featureSummary(feature map[string]any) string {
+	kind, b := geometryBox(feature["geometry"])
+	props, _ := feature["properties"].(map[string]any)
+	s := kind
+	if b.Points > 0 {
+		s += fmt.Sprintf("  bbox [%g, %g, %g, %g]", b.MinX, b.MinY, b.MaxX, b.MaxY)
+	}
+	if b.Points > 1 {
+		s += fmt.Sprintf("  %d positions", b.Points)
+	}
+	if len(props) == 1 {
+		return s + "  1 property"
+	}
+	return s + fmt.Sprintf("  %d properties", len(props))
+}
+
+// geoSummary returns the summary row of a GeoJSON feature in the listing
+func (m *Model) geoSummary(kv KVPair) (string, bool) {
+	if kv.More || kv.Path != nil || kv.Value != "{}" {
+		return "", false
+	}
+	child := m.children()[kv.Key]
+	// features are small next to their collection so the check is cheap
+	// once it is known that the row is an object with a type
+	if stringAt(getKAny(child), "type") != "Feature" {
+		return "", false
+	}
+	feature, _ := materialize(child).(map[string]any)
+	return featureSummary(feature), true
+}
+
+// geoFeatures returns the features to plot: those of the collection being
+// listed or of the whole document, or the feature under the cursor
+func (m *Model) geoFeatures() []any {
+	for _, o := range []any{m.node(), m.Data} {
+		if stringAt(getKAny(o), "type") == "FeatureCollection" {
+			collection := materialize(o).(map[string]any)
+			features, _ := collection["features"].([]any)
+			return features
+		}
+	}
+	if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+		if feature := materialize(m.currentNode()); isGeoJSON(feature, "Feature") {
+			return []any{feature}
+		}
+	}
+	return nil
+}
+
+// plotString is a utility function that draws the bounding box of every
+// feature on a grid scaled to fit all of them, with north at the top
+func plotString(features []any) string {
+	boxes := []bbox{}
+	var all bbox
+	for _, f := range features {
+		feature, _ := f.(map[string]any)
+		if _, b := geometryBox(feature["geometry"]); b.Points > 0 {
+			boxes = append(boxes, b)
+			all.merge(b)
+		}
+	}
+	if all.Points == 0 {
+		return "(no coordinates)"
+	}
+	grid := make([][]rune, plotHeight)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", plotWidth))
+	}
+	// cell returns the column and row of a position
+	cell := func(x, y float64) (int, int) {
+		col, row := 0, plotHeight-1
+		if all.MaxX > all.MinX {
+			col = int((x - all.MinX) / (all.MaxX - all.MinX) * (plotWidth - 1))
+		}
+		if all.MaxY > all.MinY {
+			row = plotHeight - 1 - int((y-all.MinY)/(all.MaxY-all.MinY)*(plotHeight-1))
+		}
+		return col, row
+	}
+	for _, b := range boxes {
+		left, bottom := cell(b.MinX, b.MinY)
+		right, top := cell(b.MaxX, b.MaxY)
+		if left == right && top == bottom {
+			grid[top][left] = '*'
+			continue
+		}
+		for c := left; c <= right; c++ {
+			grid[top][c], grid[bottom][c] = '-', '-'
+		}
+		for r := top; r <= bottom; r++ {
+			grid[r][left], grid[r][right] = '|', '|'
+		}
+		grid[top][left], grid[top][right], grid[bottom][left], grid[bottom][right] = '+', '+', '+', '+'
+	}
+	lines := []string{fmt.Sprintf("%d features within [%g, %g, %g, %g]", len(boxes), all.MinX, all.MinY, all.MaxX, all.MaxY)}
+	lines = append(lines, "┌"+strings.Repeat("─", plotWidth)+"┐")
+	for _, row := range grid {
+		lines = append(lines, "│"+string(row)+"│")
+	}
+	lines = append(lines, "└"+strings.Repeat("─", plotWidth)+"┘")
+	return strings.Join(lines, "\n")
+}
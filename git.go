@@ -0,0 +1,123 @@
+package jv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diffMsg is sent once the differences between two documents are known
+type diffMsg struct {
+	Title   string
+	Changes []change
+	Err     error
+}
+
+// runGit is a utility function that runs git and returns what it wrote
+// to standard output, or what it complained about if it failed
+func runGit(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, "git", args...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// readJsonGit is a utility function that reads JSON from a git object
+// such as HEAD~1:config.json and returns an any along with any values
+// rewritten in lenient mode
+func readJsonGit(ctx context.Context, spec string) (any, map[string]string, error) {
+	if !strings.Contains(spec, ":") {
+		return nil, nil, fmt.Errorf("expected a revision and a path like HEAD~1:config.json but got %s", spec)
+	}
+	content, err := runGit(ctx, "show", spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read %s: %w", spec, err)
+	}
+	return parseInput(content)
+}
+
+// readJsonSource is a utility function that reads the input from the git
+// object at path if git is set, and from the file, URL or stdin otherwise
+func readJsonSource(ctx context.Context, progress *loadProgress, path string, git bool) (any, map[string]string, error) {
+	var data any
+	var relaxed map[string]string
+	var err error
+	if git {
+		data, relaxed, err = readJsonGit(ctx, path)
+	} else {
+		data, relaxed, err = readJsonInput(ctx, progress, path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// values are redacted before anything else sees them, jq included, and
+	// again after it since it can move them under other keys
+	data, relaxed = redactInput(data, relaxed)
+	if jqFilter != "" {
+		if data, err = runJq(ctx, jqFilter, data); err != nil {
+			return nil, nil, err
+		}
+		data, relaxed = redactInput(data, nil)
+	}
+	return data, relaxed, nil
+}
+
+// workingTreePath is a utility function that returns the file in the working
+// tree for a git object, paths in git objects are relative to the top of
+// the repository unless they start with ./ or ../
+func workingTreePath(ctx context.Context, spec string) (string, error) {
+	_, path, _ := strings.Cut(spec, ":")
+	if strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") {
+		return path, nil
+	}
+	top, err := runGit(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(strings.TrimSpace(string(top)), path), nil
+}
+
+// diffWorkingTree compares the document from git with the same file in the
+// working tree in the background
+func diffWorkingTree(spec string, data any) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		path, err := workingTreePath(ctx, spec)
+		if err != nil {
+			return diffMsg{Err: err}
+		}
+		current, _, err := readJsonFile(ctx, &loadProgress{}, path)
+		if err != nil {
+			return diffMsg{Err: err}
+		}
+		current, _ = redactInput(current, nil)
+		changes := diffValues(materialize(data), materialize(current))
+		debugLog.Printf("found %d changes between %s and %s", len(changes), spec, path)
+		return diffMsg{
+			Title:   fmt.Sprintf("Changes from %s to %s", spec, path),
+			Changes: changes,
+		}
+	}
+}
+
+// finishDiff shows the differences in the detail pane
+func (m *Model) finishDiff(msg diffMsg) {
+	if msg.Err != nil {
+		m.Status = fmt.Sprintf("cannot diff: %s", msg.Err)
+		return
+	}
+	m.Status = ""
+	m.openDetail(sanitize(msg.Title), changesString(m.maskChanges(msg.Changes)), "changes.diff")
+}
@@ -0,0 +1,146 @@
+package jv
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// goGenerator collects the Go type definitions generated for a schema
+type goGenerator struct {
+	names []string          // type names in the order they were generated
+	defs  map[string]string // type name to its definition
+}
+
+// goStructString is a utility function that returns Go type definitions
+// with json tags matching the given any
+func goStructString(name string, o any) string {
+	g := &goGenerator{defs: map[string]string{}}
+	root := exportedName(name)
+	t := g.goType(inferSchema(materialize(o)), root)
+	// scalars and arrays at the top level get a named type of their own
+	if t != root {
+		g.names = append([]string{root}, g.names...)
+		g.defs[root] = fmt.Sprintf("type %s %s", root, t)
+	}
+	defs := []string{}
+	for _, n := range g.names {
+		defs = append(defs, g.defs[n])
+	}
+	src := strings.Join(defs, "\n\n") + "\n"
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return src
+	}
+	return string(formatted)
+}
+
+// goType returns the Go type for a schema, generating struct definitions
+// for objects with the given name
+func (g *goGenerator) goType(s map[string]any, name string) string {
+	switch s["type"] {
+	case "object":
+		return g.goStruct(s, name)
+	case "array":
+		if items, ok := s["items"].(map[string]any); ok {
+			return "[]" + g.goType(items, name+"Item")
+		}
+		return "[]any"
+	case "string":
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	}
+	// nulls and mixed types can hold anything
+	return "any"
+}
+
+// goStruct generates a struct definition for an object schema and returns its name
+func (g *goGenerator) goStruct(s map[string]any, name string) string {
+	name = g.uniqueName(name)
+	// reserve the name before generating nested structs
+	g.names = append(g.names, name)
+	props := s["properties"].(map[string]any)
+	required := map[string]bool{}
+	for _, k := range s["required"].([]string) {
+		required[k] = true
+	}
+	keys := []string{}
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	def := fmt.Sprintf("type %s struct {\n", name)
+	// keys such as foo-bar and fooBar turn into the same name so the later
+	// ones are numbered, their tags keep the keys apart
+	fields := map[string]bool{}
+	for _, k := range keys {
+		field := exportedName(k)
+		for i, base := 2, field; fields[field]; i++ {
+			field = fmt.Sprintf("%s%d", base, i)
+		}
+		fields[field] = true
+		ps := props[k].(map[string]any)
+		t := g.goType(ps, field)
+		tag := k
+		if !required[k] {
+			tag += ",omitempty"
+			// omitempty only leaves out nested structs through a pointer
+			if ps["type"] == "object" {
+				t = "*" + t
+			}
+		}
+		def += fmt.Sprintf("\t%s %s `json:%q`\n", field, t, tag)
+	}
+	def += "}"
+	g.defs[name] = def
+	return name
+}
+
+// uniqueName returns a type name that has not been generated yet
+func (g *goGenerator) uniqueName(name string) string {
+	unique := name
+	for i := 2; ; i++ {
+		if _, ok := g.defs[unique]; !ok && !g.reserved(unique) {
+			return unique
+		}
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+// reserved checks if a type name has already been taken
+func (g *goGenerator) reserved(name string) bool {
+	for _, n := range g.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// exportedName is a utility function that turns a JSON key
+// into an exported Go identifier
+func exportedName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	name := ""
+	for _, w := range words {
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		name += string(runes)
+	}
+	if name == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		return "Field" + name
+	}
+	return name
+}
@@ -0,0 +1,79 @@
+package jv
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGoStructString(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "keys turning into the same name",
+			input: `{"foo-bar": 1, "fooBar": "x", "foo_bar": true}`,
+			want: []string{
+				"FooBar int64 `json:\"foo-bar\"`",
+				"FooBar2 string `json:\"fooBar\"`",
+				"FooBar3 bool `json:\"foo_bar\"`",
+			},
+		},
+		{
+			name:  "keys differing only in case",
+			input: `{"Type": "a", "type": "b"}`,
+			want: []string{
+				"Type string `json:\"Type\"`",
+				"Type2 string `json:\"type\"`",
+			},
+		},
+		{
+			name:  "numbered name taken by another key",
+			input: `{"a-b": 1, "aB": 2, "aB2": 3}`,
+			want: []string{
+				"AB int64 `json:\"a-b\"`",
+				"AB2 int64 `json:\"aB\"`",
+				"AB22 int64 `json:\"aB2\"`",
+			},
+		},
+		{
+			name:  "nested objects under colliding keys",
+			input: `{"x-y": {"a": 1}, "xY": {"b": 2}}`,
+			want: []string{
+				"XY XY `json:\"x-y\"`",
+				"XY2 XY2 `json:\"xY\"`",
+			},
+		},
+		{
+			name:  "keys starting with digits",
+			input: `{"1st": 1, "": 2}`,
+			want: []string{
+				"Field int64 `json:\"\"`",
+				"Field1st int64 `json:\"1st\"`",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parseJson([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := goStructString("Root", data)
+			// the generated code has to compile, which duplicate fields stop
+			if _, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+got, 0); err != nil {
+				t.Fatalf("generated code does not parse: %s\n%s", err, got)
+			}
+			// fields are compared without the alignment gofmt adds
+			fields := strings.Join(strings.Fields(got), " ")
+			for _, w := range tt.want {
+				if !strings.Contains(fields, w) {
+					t.Errorf("missing %q in\n%s", w, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package jv
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openGoTo starts typing the path to go to, starting from the current one
+// so that the key of a child can be typed straight away
+func (m *Model) openGoTo() {
+	if m.Flat {
+		return
+	}
+	m.GoingTo, m.GoToInput, m.Completions = true, ".", nil
+	if len(m.Path) > 0 {
+		m.GoToInput = selectorPath(m.Data, m.Path) + "."
+	}
+}
+
+// updateGoTo handles typing the path to go to
+// tab completes the key being typed from the schema
+func (m *Model) updateGoTo(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.Completions = nil
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.GoingTo = false
+	case tea.KeyTab:
+		m.GoToInput, m.Completions = m.completePath(m.GoToInput)
+	case tea.KeyEnter:
+		path, err := selectorKeys(strings.TrimSpace(m.GoToInput))
+		if err != nil {
+			m.Status = fmt.Sprintf("cannot go to %s: %s", m.GoToInput, err)
+			return m, nil
+		}
+		if err := m.checkPath(path); err != nil {
+			m.Status = err.Error()
+			return m, nil
+		}
+		m.GoingTo = false
+		m.Flat, m.Filter = false, ""
+		m.goTo(path)
+	case tea.KeyBackspace:
+		if len(m.GoToInput) > 0 {
+			runes := []rune(m.GoToInput)
+			m.GoToInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes:
+		m.GoToInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// completionSchema returns the schema keys are completed from, the one
+// the input is checked against or else one inferred from the input
+func (m *Model) completionSchema() any {
+	if m.Schema != nil {
+		return m.Schema
+	}
+	if m.inferred == nil {
+		m.inferred = inferSchema(materialize(m.Data))
+	}
+	return m.inferred
+}
+
+// completePath completes the last key of a selector such as .spec.tem from
+// the keys the schema allows there
+// it returns the selector completed as far as the keys agree, along with
+// the keys to pick from when there is more than one
+func (m *Model) completePath(input string) (string, []string) {
+	i := strings.LastIndexAny(input, ".[")
+	if i < 0 {
+		return input, nil
+	}
+	partial := input[i+1:]
+	if input[i] == '[' {
+		// only quoted keys are completed, not indices
+		if !strings.HasPrefix(partial, `"`) {
+			return input, nil
+		}
+		partial = partial[1:]
+	}
+	if strings.ContainsAny(partial, `"]`) {
+		return input, nil
+	}
+	path, err := selectorKeys(input[:i])
+	if err != nil {
+		return input, nil
+	}
+	root := m.completionSchema()
+	schemas := expandSchema(root, root, 0)
+	for _, k := range path {
+		schemas = schemaChildren(root, schemas, k)
+	}
+	keys := []string{}
+	for _, k := range schemaKeys(schemas) {
+		if strings.HasPrefix(k, partial) {
+			keys = append(keys, k)
+		}
+	}
+	switch len(keys) {
+	case 0:
+		return input, nil
+	case 1:
+		return input[:i] + gronKey(keys[0]), nil
+	}
+	common := commonPrefix(keys)
+	if input[i] == '.' && gronIdentifier.MatchString(common) {
+		input = input[:i] + "." + common
+	}
+	return input, keys
+}
+
+// expandSchema is a utility function that follows the $refs and the
+// allOf, anyOf and oneOf of a schema to the schemas that describe values
+// depth stops schemas that refer to themselves from looping forever
+func expandSchema(root, s any, depth int) []any {
+	schema, ok := s.(map[string]any)
+	if !ok || depth > 20 {
+		return nil
+	}
+	schemas := []any{schema}
+	if ref, ok := schema["$ref"].(string); ok {
+		if target, err := resolveRef(root, ref); err == nil {
+			schemas = append(schemas, expandSchema(root, target, depth+1)...)
+		}
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		for _, sub := range listOf(schema[keyword]) {
+			schemas = append(schemas, expandSchema(root, sub, depth+1)...)
+		}
+	}
+	return schemas
+}
+
+// schemaChildren is a utility function that returns the schemas of the
+// value under key in the values the schemas describe
+func schemaChildren(root any, schemas []any, key string) []any {
+	children := []any{}
+	for _, s := range schemas {
+		schema := s.(map[string]any)
+		if props, ok := schema["properties"].(map[string]any); ok {
+			if child, ok := props[key]; ok {
+				children = append(children, expandSchema(root, child, 0)...)
+				continue
+			}
+		}
+		if extra, ok := schema["additionalProperties"].(map[string]any); ok {
+			children = append(children, expandSchema(root, extra, 0)...)
+		}
+		if _, err := strconv.Atoi(key); err == nil {
+			children = append(children, expandSchema(root, schema["items"], 0)...)
+		}
+	}
+	return children
+}
+
+// schemaKeys is a utility function that returns the keys of the objects
+// the schemas describe in order
+func schemaKeys(schemas []any) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, s := range schemas {
+		props, _ := s.(map[string]any)["properties"].(map[string]any)
+		for k := range props {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
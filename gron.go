@@ -0,0 +1,244 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gronIdentifier matches keys that can be written with dot notation
+var gronIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// gronKey returns the selector for an object key
+func gronKey(key string) string {
+	if gronIdentifier.MatchString(key) {
+		return "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return fmt.Sprintf("[%s]", quoted)
+}
+
+// gronPath is a utility function that returns the gron selector
+// for the given path into the data
+func gronPath(data any, path []string) string {
+	s := "json"
+	o := data
+	for _, k := range path {
+		if isArray(o) {
+			s += fmt.Sprintf("[%s]", k)
+		} else {
+			s += gronKey(k)
+		}
+		o = getKAny(o)[k]
+	}
+	return s
+}
+
+// selectorPath is a utility function that returns the path into the data as
+// a jq style selector such as .a[0]["app.kubernetes.io/name"]
+// keys that are not plain identifiers are quoted so the path is unambiguous
+func selectorPath(data any, path []string) string {
+	s := strings.TrimPrefix(gronPath(data, path), "json")
+	if s == "" || strings.HasPrefix(s, "[") {
+		s = "." + s
+	}
+	return s
+}
+
+// gronLines is a utility function that returns every value in the given any
+// as an assignment statement starting from the given selector
+func gronLines(prefix string, o any) []string {
+	o = materialize(o)
+	switch val := o.(type) {
+	case map[string]any:
+		lines := []string{fmt.Sprintf("%s = {};", prefix)}
+		keys := []string{}
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			lines = append(lines, gronLines(prefix+gronKey(k), val[k])...)
+		}
+		return lines
+	case []any:
+		lines := []string{fmt.Sprintf("%s = [];", prefix)}
+		for i, v := range val {
+			lines = append(lines, gronLines(fmt.Sprintf("%s[%d]", prefix, i), v)...)
+		}
+		return lines
+	}
+	content, _ := json.Marshal(o)
+	return []string{fmt.Sprintf("%s = %s;", prefix, content)}
+}
+
+// ungron is a utility function that rebuilds data from gron assignment statements
+func ungron(content []byte) (any, error) {
+	var root any
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		path, val, err := parseGronLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if root, err = setGronPath(root, path, val); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no gron statements found")
+	}
+	return root, nil
+}
+
+// parseGronLine splits a statement like json.a[0]["b c"] = 1; into
+// its path of string keys and int indices and the assigned value
+func parseGronLine(line string) ([]any, any, error) {
+	if !strings.HasPrefix(line, "json") {
+		return nil, nil, fmt.Errorf("statement does not start with json")
+	}
+	path, rest, err := parseSelector(line[len("json"):])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid statement %s: %w", line, err)
+	}
+	if !strings.HasPrefix(rest, " = ") {
+		return nil, nil, fmt.Errorf("invalid selector in %s", line)
+	}
+	content := strings.TrimSuffix(strings.TrimPrefix(rest, " = "), ";")
+	val, err := decodeJson([]byte(content))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid value in %s: %w", line, err)
+	}
+	return path, val, nil
+}
+
+// parseSelector reads a selector like .a[0]["b c"] from the start of s into
+// its path of string keys and int indices and returns the rest of s
+// the selector ends at the first space or at the end of s
+func parseSelector(s string) ([]any, string, error) {
+	rest := s
+	path := []any{}
+	for rest != "" && !strings.HasPrefix(rest, " ") {
+		switch {
+		// a lone dot is the root and a dot before brackets is jq style
+		case rest == "." || strings.HasPrefix(rest, ".["):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "."):
+			end := 1
+			for end < len(rest) && rest[end] != '.' && rest[end] != '[' && rest[end] != ' ' {
+				end++
+			}
+			path = append(path, rest[1:end])
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "[\""):
+			// find the closing quote skipping escaped characters
+			end := 2
+			for end < len(rest) && rest[end] != '"' {
+				if rest[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end+1 >= len(rest) || rest[end+1] != ']' {
+				return nil, "", fmt.Errorf("unterminated key in %s", s)
+			}
+			var key string
+			if err := json.Unmarshal([]byte(rest[1:end+1]), &key); err != nil {
+				return nil, "", fmt.Errorf("invalid key in %s: %w", s, err)
+			}
+			path = append(path, key)
+			rest = rest[end+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, "", fmt.Errorf("unterminated index in %s", s)
+			}
+			i, err := strconv.Atoi(rest[1:end])
+			if err != nil || i < 0 {
+				return nil, "", fmt.Errorf("invalid index in %s", s)
+			}
+			path = append(path, i)
+			rest = rest[end+1:]
+		default:
+			return nil, "", fmt.Errorf("invalid selector %s", s)
+		}
+	}
+	return path, rest, nil
+}
+
+// selectorKeys is a utility function that reads a whole selector such as
+// .spec.containers[0] into the keys of its path
+func selectorKeys(s string) ([]string, error) {
+	selector, rest, err := parseSelector(s)
+	if err == nil && rest != "" {
+		err = fmt.Errorf("unexpected %q after the selector", rest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	path := []string{}
+	for _, k := range selector {
+		if i, ok := k.(int); ok {
+			path = append(path, strconv.Itoa(i))
+		} else {
+			path = append(path, k.(string))
+		}
+	}
+	return path, nil
+}
+
+// maxGronGap is how far past the end of an array a statement can assign an
+// element, so that a single statement cannot fill memory with nulls
+const maxGronGap = 1024
+
+// setGronPath sets the value at the given path creating any containers on the way
+func setGronPath(node any, path []any, val any) (any, error) {
+	if len(path) == 0 {
+		// an empty container does not replace one that is already filled in
+		if m, ok := val.(map[string]any); ok && len(m) == 0 {
+			if _, ok := node.(map[string]any); ok {
+				return node, nil
+			}
+		}
+		if a, ok := val.([]any); ok && len(a) == 0 {
+			if _, ok := node.([]any); ok {
+				return node, nil
+			}
+		}
+		return val, nil
+	}
+	switch k := path[0].(type) {
+	case int:
+		arr, _ := node.([]any)
+		if k-len(arr) > maxGronGap {
+			return nil, fmt.Errorf("index %d is too far past the end of an array of %d elements", k, len(arr))
+		}
+		for len(arr) <= k {
+			arr = append(arr, nil)
+		}
+		child, err := setGronPath(arr[k], path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		arr[k] = child
+		return arr, nil
+	case string:
+		m, ok := node.(map[string]any)
+		if !ok {
+			m = map[string]any{}
+		}
+		child, err := setGronPath(m[k], path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = child
+		return m, nil
+	}
+	return node, nil
+}
@@ -0,0 +1,80 @@
+package jv
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUngron(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    any
+		wantErr string
+	}{
+		{
+			name:  "objects and arrays",
+			input: "json = {};\njson.a = [];\njson.a[0] = 1;\njson.a[1] = \"x\";\njson[\"b c\"] = true;",
+			want:  map[string]any{"a": []any{json.Number("1"), "x"}, "b c": true},
+		},
+		{
+			name:  "containers are created on the way",
+			input: "json.a[1].b = null;",
+			want:  map[string]any{"a": []any{nil, map[string]any{"b": nil}}},
+		},
+		{
+			name:  "an empty container does not replace a filled one",
+			input: "json.a.b = 1;\njson.a = {};",
+			want:  map[string]any{"a": map[string]any{"b": json.Number("1")}},
+		},
+		{
+			name:  "gaps up to the limit are filled with nulls",
+			input: "json[2] = 1;",
+			want:  []any{nil, nil, json.Number("1")},
+		},
+		{
+			name:    "huge index",
+			input:   "json[999999999] = 1;",
+			wantErr: "line 1: index 999999999 is too far past the end",
+		},
+		{
+			name:    "huge index in a nested array",
+			input:   "json.a = [];\njson.a[0] = 1;\njson.a[5000] = 2;",
+			wantErr: "line 3: index 5000 is too far past the end of an array of 1 elements",
+		},
+		{
+			name:    "negative index",
+			input:   "json[-1] = 1;",
+			wantErr: "line 1: invalid statement",
+		},
+		{
+			name:    "not a statement",
+			input:   "json.a = 1;\nfoo = 2;",
+			wantErr: "line 2: statement does not start with json",
+		},
+		{
+			name:    "no statements",
+			input:   "\n\n",
+			wantErr: "no gron statements found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ungron([]byte(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
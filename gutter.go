@@ -0,0 +1,51 @@
+package jv
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// lineNumbers starts the viewer with the index of every row shown in a
+// gutter, it can be toggled with #
+var lineNumbers bool
+
+// rowNumber is a utility function that returns the index shown in the
+// gutter for the row at i of the listing
+// array elements keep their index in the array whatever order or slice
+// they are listed in, other rows are numbered by their place in the listing
+func rowNumber(kv KVPair, i int) string {
+	if kv.More {
+		return ""
+	}
+	if kv.Index && kv.Path == nil {
+		return kv.Key
+	}
+	return strconv.Itoa(i)
+}
+
+// gutterWidth returns how wide the gutter is for the rows from start to end
+// so that the rows of a page line up
+func (m *Model) gutterWidth(start, end int) int {
+	width := len(strconv.Itoa(len(m.CurrKV) - 1))
+	for i := start; i < end; i++ {
+		if n := len(rowNumber(m.CurrKV[i], i)); n > width {
+			width = n
+		}
+	}
+	return width
+}
+
+// gutter returns the index of the row at i padded to the width of the gutter
+func (m *Model) gutter(i, width int) string {
+	return style(fmt.Sprintf("%*s", width, rowNumber(m.CurrKV[i], i)), styleFaint) + " "
+}
+
+// toggleLineNumbers shows or hides the index of every row
+func (m *Model) toggleLineNumbers() {
+	m.LineNumbers = !m.LineNumbers
+	if m.LineNumbers {
+		m.Status = "showing row indices"
+		return
+	}
+	m.Status = "hiding row indices"
+}
@@ -0,0 +1,107 @@
+package jv
+
+import (
+	"encoding/json"
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// headlessSteps is the most messages a single action is followed through,
+// in case commands keep starting more commands
+const headlessSteps = 100
+
+// Headless runs a model without a terminal for tests and scripts
+// every action waits for the commands it starts, such as searches, and
+// hands their results back to the model before returning
+type Headless struct {
+	Model *Model
+}
+
+// NewHeadless returns a headless model showing the given JSON on a screen
+// of the given size
+func NewHeadless(content []byte, width, height int) (*Headless, error) {
+	m, err := NewModelFromJson(content)
+	if err != nil {
+		return nil, err
+	}
+	h := &Headless{Model: m}
+	h.Send(tea.WindowSizeMsg{Width: width, Height: height})
+	h.run(m.Init())
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return h, nil
+}
+
+// Send hands a message to the model and carries out what it starts
+func (h *Headless) Send(msg tea.Msg) {
+	_, cmd := h.Model.Update(msg)
+	h.run(cmd)
+}
+
+// run carries out commands one after the other
+// ticks and anything waiting on a terminal are dropped
+func (h *Headless) run(cmd tea.Cmd) {
+	queue := []tea.Cmd{cmd}
+	for steps := 0; len(queue) > 0 && steps < headlessSteps; steps++ {
+		cmd, queue = queue[0], queue[1:]
+		if cmd == nil {
+			continue
+		}
+		switch msg := cmd().(type) {
+		case nil, spinnerMsg, tea.QuitMsg:
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		default:
+			_, next := h.Model.Update(msg)
+			queue = append(queue, next)
+		}
+	}
+}
+
+// Press presses keys one after the other, named like the key command of
+// the control socket: a single character or up, down, enter, esc and so on
+func (h *Headless) Press(keys ...string) {
+	for _, k := range keys {
+		h.Send(controlKey(k))
+	}
+}
+
+// GoTo opens the listing at the selector, such as .spec.containers[0]
+func (h *Headless) GoTo(selector string) error {
+	return h.control("goto", selector)
+}
+
+// Search shows the leaves whose path or value contains the query
+func (h *Headless) Search(query string) error {
+	return h.control("search", query)
+}
+
+// Selection returns the selector of the value under the cursor
+func (h *Headless) Selection() string {
+	return selectorPath(h.Model.Data, h.Model.selection())
+}
+
+// View renders the screen
+func (h *Headless) View() string {
+	return h.Model.View()
+}
+
+// control carries out a command of the control socket
+func (h *Headless) control(verb, arg string) error {
+	reply := make(chan string, 1)
+	_, cmd := h.Model.Update(controlMsg{Verb: verb, Arg: arg, Reply: reply})
+	h.run(cmd)
+	var answer struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(<-reply), &answer); err != nil {
+		return err
+	}
+	if !answer.OK {
+		return errors.New(answer.Error)
+	}
+	return nil
+}
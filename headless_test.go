@@ -0,0 +1,135 @@
+package jv
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// headless opens a fixture from the test directory on an 80×20 screen
+func headless(t *testing.T, name string) *Headless {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("test", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return headlessJson(t, string(content))
+}
+
+// headlessJson opens the given JSON on an 80×20 screen without styling
+func headlessJson(t *testing.T, content string) *Headless {
+	t.Helper()
+	useColor = false
+	h, err := NewHeadless([]byte(content), 80, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+// checkGolden compares the screen with testdata/<name>.golden
+// the help line is left out so that adding a key binding does not change
+// every golden file
+func checkGolden(t *testing.T, h *Headless, name string) {
+	t.Helper()
+	lines := strings.Split(h.View(), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "Quit: ") {
+			lines = lines[:i]
+			break
+		}
+	}
+	got := strings.Join(lines, "\n")
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s, run go test -update to create it", err)
+	}
+	if got != string(want) {
+		t.Errorf("screen does not match %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestListing(t *testing.T) {
+	h := headless(t, "simple.json")
+	checkGolden(t, h, "listing")
+}
+
+func TestNavigation(t *testing.T) {
+	h := headless(t, "arrays.json")
+	// enter opens the value the cursor has been moved onto
+	h.Press("down", "right", "enter")
+	if got := h.Selection(); got != ".key2[0]" {
+		t.Errorf("selection after entering key2 is %s, want .key2[0]", got)
+	}
+	h.Press("right", "enter")
+	checkGolden(t, h, "navigation")
+	h.Press("x")
+	if got := h.Selection(); got != ".key2[0]" {
+		t.Errorf("selection after going back is %s, want .key2[0]", got)
+	}
+}
+
+func TestGoTo(t *testing.T) {
+	h := headless(t, "arrays.json")
+	if err := h.GoTo(".key2[0].key21"); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, h, "goto")
+	if err := h.GoTo(".key3"); err == nil {
+		t.Error("going to a missing key did not fail")
+	}
+	// a scalar opens the level holding it with the cursor on it
+	if err := h.GoTo(".key2[1].key22"); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Selection(); got != ".key2[1].key22" {
+		t.Errorf("selection is %s, want .key2[1].key22", got)
+	}
+}
+
+func TestGoToListItems(t *testing.T) {
+	// the items of a List at the root are entered before the List's kind
+	// can be read from the level above them
+	h := headlessJson(t, `{"items":[{"a":1}]}`)
+	if err := h.GoTo(".items[0]"); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Selection(); got != ".items[0].a" {
+		t.Errorf("selection is %s, want .items[0].a", got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	h := headless(t, "arrays.json")
+	if err := h.Search("value2"); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, h, "search")
+}
+
+func TestLoadMore(t *testing.T) {
+	defer func(size int) { arrayPageSize = size }(arrayPageSize)
+	arrayPageSize = 2
+	h := headlessJson(t, `[1, 2, 3, 4, 5]`)
+	h.Press("down", "down", "enter")
+	if got := h.Selection(); got != ".[2]" {
+		t.Errorf("selection after loading more is %s, want .[2]", got)
+	}
+	checkGolden(t, h, "load_more")
+}
+
+func TestScalar(t *testing.T) {
+	h := headlessJson(t, `"just a string"`)
+	checkGolden(t, h, "scalar")
+}
@@ -0,0 +1,64 @@
+package jv
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// hexDumpWidth is how many bytes a line of a hex dump shows
+const hexDumpWidth = 16
+
+// isBinary is a utility function that reports whether b is not text: not
+// UTF-8 or with control characters other than line breaks and tabs, which
+// would be drawn as escape sequences
+func isBinary(b []byte) bool {
+	if !utf8.Valid(b) {
+		return true
+	}
+	for _, r := range string(b) {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' && r != '\r' {
+			return true
+		}
+	}
+	return false
+}
+
+// hexDumpLine is a utility function that lays out the bytes of b from
+// offset as a line of hex.Dump: the offset, the bytes in hex and the bytes
+// that are printable as ASCII between bars
+func hexDumpLine(b []byte, offset int) string {
+	end := offset + hexDumpWidth
+	if end > len(b) {
+		end = len(b)
+	}
+	line := strings.TrimSuffix(hex.Dump(b[offset:end]), "\n")
+	return fmt.Sprintf("%08x", offset) + line[8:]
+}
+
+// openHexDump shows bytes that are not text in the detail pane as a hex
+// dump, laying out only the lines scrolled to
+// the dump is what is written and paged so that no escape sequence reaches
+// the terminal
+func (m *Model) openHexDump(title string, b []byte) {
+	offset := 0
+	next := func() (string, bool) {
+		if offset >= len(b) {
+			return "", false
+		}
+		line := hexDumpLine(b, offset)
+		offset += hexDumpWidth
+		return line, true
+	}
+	m.Detail = &Detail{
+		Title:    title,
+		Content:  strings.TrimSuffix(hex.Dump(b), "\n"),
+		Lines:    []string{},
+		FileName: "decoded.hex",
+		Status:   fmt.Sprintf("%s of binary, offsets in hex", humanBytes(int64(len(b)))),
+		next:     next,
+	}
+	m.Detail.fill(m.detailHeight())
+}
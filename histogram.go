@@ -0,0 +1,226 @@
+package jv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// histogramBars is the most values a histogram has a bar for, numbers with
+// more distinct values than that are grouped into ranges
+const histogramBars = 20
+
+// histogramWidth is how wide the longest bar is
+const histogramWidth = 40
+
+// histogramMsg carries the counts of the values once they are done
+type histogramMsg struct {
+	Title string
+	Bins  []bin
+	Total int
+}
+
+// bin is a bar of a histogram
+type bin struct {
+	Label string
+	Count int
+}
+
+// histogramLabel is a utility function that names the value a bar counts
+func histogramLabel(o any) string {
+	switch v := o.(type) {
+	case nil:
+		return "null"
+	case string:
+		if v == "" {
+			return `""`
+		}
+		return truncate(sanitize(v), tableMaxWidth)
+	}
+	// containers are counted by their kind, parsed or not
+	switch s := getVal(o); s {
+	case "{}":
+		return "(object)"
+	case "[]":
+		return "(array)"
+	default:
+		return s
+	}
+}
+
+// countValues is a utility function that counts how often each value
+// appears, most frequent first, stopping early once ctx is cancelled
+// numbers with too many distinct values to have a bar each are counted in
+// ranges of the same width instead
+func countValues(ctx context.Context, values []any) []bin {
+	counts := map[string]int{}
+	numbers := []float64{}
+	for _, v := range values {
+		if ctx.Err() != nil {
+			return nil
+		}
+		counts[histogramLabel(v)]++
+		if n, ok := v.(json.Number); ok && numbers != nil {
+			if f, err := n.Float64(); err == nil {
+				numbers = append(numbers, f)
+				continue
+			}
+		}
+		numbers = nil
+	}
+	if len(counts) > histogramBars && len(numbers) > 0 {
+		return numberRanges(numbers)
+	}
+	bins := []bin{}
+	for label, n := range counts {
+		bins = append(bins, bin{Label: label, Count: n})
+	}
+	sort.Slice(bins, func(i, j int) bool {
+		if bins[i].Count != bins[j].Count {
+			return bins[i].Count > bins[j].Count
+		}
+		return bins[i].Label < bins[j].Label
+	})
+	if len(bins) > histogramBars {
+		other := bin{Label: fmt.Sprintf("(%d other values)", len(bins)-histogramBars+1)}
+		for _, b := range bins[histogramBars-1:] {
+			other.Count += b.Count
+		}
+		bins = append(bins[:histogramBars-1], other)
+	}
+	return bins
+}
+
+// numberRanges is a utility function that counts numbers in ranges of the
+// same width from the smallest to the largest, in order
+func numberRanges(numbers []float64) []bin {
+	lo, hi := numbers[0], numbers[0]
+	for _, f := range numbers {
+		if f < lo {
+			lo = f
+		}
+		if f > hi {
+			hi = f
+		}
+	}
+	width := (hi - lo) / histogramBars
+	bins := make([]bin, histogramBars)
+	for i := range bins {
+		from := lo + float64(i)*width
+		bins[i].Label = fmt.Sprintf("%s – %s", strconv.FormatFloat(from, 'g', 6, 64), strconv.FormatFloat(from+width, 'g', 6, 64))
+	}
+	for _, f := range numbers {
+		i := int((f - lo) / width)
+		// the largest number is counted in the last range
+		if i >= histogramBars {
+			i = histogramBars - 1
+		}
+		bins[i].Count++
+	}
+	return bins
+}
+
+// histogramBar is a utility function that draws a bar of n out of most with
+// unicode blocks, down to eighths of a character
+func histogramBar(n, most int) string {
+	eighths := n * histogramWidth * 8 / most
+	bar := strings.Repeat("█", eighths/8)
+	if partial := eighths % 8; partial > 0 {
+		bar += string([]rune("▏▎▍▌▋▊▉")[partial-1])
+	}
+	return bar
+}
+
+// histogramString is a utility function that draws the bins as a bar chart
+// with the count and share of each
+func histogramString(bins []bin, total int) string {
+	if total == 0 {
+		return "(no values)"
+	}
+	labelWidth, most := 0, 0
+	for _, b := range bins {
+		if w := utf8.RuneCountInString(b.Label); w > labelWidth {
+			labelWidth = w
+		}
+		if b.Count > most {
+			most = b.Count
+		}
+	}
+	lines := []string{}
+	for _, b := range bins {
+		lines = append(lines, fmt.Sprintf("%s%s  %-*s %d (%.1f%%)",
+			b.Label, strings.Repeat(" ", labelWidth-utf8.RuneCountInString(b.Label)),
+			histogramWidth, histogramBar(b.Count, most), b.Count, float64(b.Count)*100/float64(total)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// openHistogram shows how the values of the array under the cursor are
+// spread, or those of the field under the cursor across the array of
+// objects it is in, counting them in the background
+func (m *Model) openHistogram() tea.Cmd {
+	if m.Flat || len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return nil
+	}
+	path := m.currentPath()
+	// secrets are counted without showing what they are
+	masked := m.masked(path)
+	value := func(k string, o any) any {
+		o = materializeScalar(o)
+		if masked && isSecret(k, o) {
+			return secretMask
+		}
+		return o
+	}
+	var title string
+	var collect func() []any
+	switch {
+	case isArray(m.valueAt(path)):
+		array := m.valueAt(path)
+		title = selectorPath(m.Data, path)
+		collect = func() []any {
+			values := []any{}
+			children := getKAny(array)
+			for _, k := range childKeys(array) {
+				values = append(values, value(k, children[k]))
+			}
+			return values
+		}
+	case len(path) >= 2 && isArray(m.valueAt(path[:len(path)-2])):
+		array, field := m.valueAt(path[:len(path)-2]), path[len(path)-1]
+		title = selectorPath(m.Data, path[:len(path)-2]) + "[]" + gronKey(field)
+		collect = func() []any {
+			values := []any{}
+			children := getKAny(array)
+			for _, k := range childKeys(array) {
+				if v, ok := getKAny(children[k])[field]; ok {
+					values = append(values, value(field, v))
+				}
+			}
+			return values
+		}
+	default:
+		m.Status = "a histogram needs an array, or a field of the objects in one"
+		return nil
+	}
+	return m.startTask("histogram", "counting values", func(ctx context.Context, _ func(int)) tea.Msg {
+		values := collect()
+		bins := countValues(ctx, values)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return histogramMsg{Title: sanitize(title), Bins: bins, Total: len(values)}
+	})
+}
+
+// finishHistogram shows the histogram in the detail pane
+func (m *Model) finishHistogram(msg histogramMsg) {
+	m.openDetail(fmt.Sprintf("Histogram of %s, %d values", msg.Title, msg.Total),
+		histogramString(msg.Bins, msg.Total), "histogram.txt")
+}
@@ -0,0 +1,42 @@
+package jv
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// indexMsg carries every leaf in the document once indexing is done
+type indexMsg struct {
+	Leaves []KVPair
+}
+
+// startIndex starts indexing every key and value in the data in the background
+// so searching doesn't have to walk the document on every keystroke
+// streamed inputs are not indexed since every leaf of them would not fit in
+// memory, searching walks them instead
+func (m *Model) startIndex() tea.Cmd {
+	data := m.Data
+	if isStreamed(data) {
+		debugLog.Printf("not indexing the streamed input")
+		return nil
+	}
+	return m.startTask("index", "", func(ctx context.Context, progress func(int)) tea.Msg {
+		leaves := flatLeaves(ctx, data, progress)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return indexMsg{Leaves: leaves}
+	})
+}
+
+// updateIndex records the leaves found by the indexer and shows them if
+// the flattened view is waiting for them
+func (m *Model) updateIndex(msg indexMsg) {
+	m.Index = msg.Leaves
+	debugLog.Printf("indexed %d leaves", len(msg.Leaves))
+	if m.Flat && m.Filter == "" {
+		m.updateKV()
+		m.syncPage()
+	}
+}
@@ -0,0 +1,125 @@
+package jv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// docStats counts what is in the document
+type docStats struct {
+	Nodes  int // every value, containers included
+	Leaves int // strings, numbers, booleans, nulls and empty containers
+	Depth  int // deepest nesting level, 0 for a lone scalar
+}
+
+// infoMsg carries the counts of the document once they are done
+type infoMsg struct {
+	Stats docStats
+}
+
+// countNodes is a utility function that counts the values in o and how
+// deeply they are nested, stopping early once ctx is cancelled
+func countNodes(ctx context.Context, o any, depth int, stats *docStats, progress func(int)) {
+	if ctx.Err() != nil {
+		return
+	}
+	stats.Nodes++
+	if progress != nil && stats.Nodes%1000 == 0 {
+		progress(stats.Nodes)
+	}
+	if depth > stats.Depth {
+		stats.Depth = depth
+	}
+	children := getKAny(o)
+	if len(children) == 0 {
+		stats.Leaves++
+		return
+	}
+	for _, child := range children {
+		countNodes(ctx, child, depth+1, stats, progress)
+	}
+}
+
+// inputKind is a utility function that describes the format the input was
+// read in, which is told from how it was parsed
+func inputKind(data any, relaxed map[string]string) string {
+	switch v := data.(type) {
+	case fileSpan:
+		return "JSON, streamed from disk"
+	case json.RawMessage:
+		if len(relaxed) > 0 {
+			return fmt.Sprintf("almost JSON, %d values rewritten", len(relaxed))
+		}
+		return "JSON"
+	case []any:
+		docs := true
+		for _, doc := range v {
+			if _, ok := doc.(json.RawMessage); !ok {
+				docs = false
+				break
+			}
+		}
+		if docs {
+			return fmt.Sprintf("%d JSON documents back to back", len(v))
+		}
+	}
+	return "gron"
+}
+
+// openInfo shows what the document is made of, counting its values in the
+// background the first time
+func (m *Model) openInfo() tea.Cmd {
+	if m.Stats != nil {
+		m.showInfo()
+		return nil
+	}
+	data := m.Data
+	return m.startTask("info", "counting values", func(ctx context.Context, progress func(int)) tea.Msg {
+		stats := docStats{}
+		countNodes(ctx, data, 0, &stats, progress)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return infoMsg{Stats: stats}
+	})
+}
+
+// finishInfo keeps the counts and shows them
+func (m *Model) finishInfo(msg infoMsg) {
+	m.Stats = &msg.Stats
+	m.showInfo()
+}
+
+// showInfo shows the file, its size, how long it took to load and what is
+// in it in the detail pane
+func (m *Model) showInfo() {
+	source := m.Source
+	if source == "" {
+		source = "stdin"
+	}
+	size := humanBytes(atomic.LoadInt64(&m.Progress.Bytes)) + " read"
+	if fi, err := os.Stat(m.Source); err == nil && !m.Git && fi.Mode().IsRegular() {
+		size = fmt.Sprintf("%s on disk", humanBytes(fi.Size()))
+	}
+	lines := [][2]string{
+		{"File", sanitize(source)},
+		{"Size", size},
+		{"Parse time", m.LoadTime.Round(time.Microsecond).String()},
+		{"Format", inputKind(m.Data, m.Relaxed)},
+		{"Values", fmt.Sprint(m.Stats.Nodes)},
+		{"Leaves", fmt.Sprint(m.Stats.Leaves)},
+		{"Max depth", fmt.Sprint(m.Stats.Depth)},
+	}
+	s := []string{}
+	for _, l := range lines {
+		s = append(s, fmt.Sprintf("%-12s%s", l[0], l[1]))
+	}
+	m.openDetail("Document info", strings.Join(s, "\n"), "info.txt")
+}
@@ -0,0 +1,72 @@
+package jv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// jqFilter is a jq program the input is run through every time it is read,
+// such as .[] | select(.level == "error"), so that a watched input is shown
+// filtered as it changes
+// jv does not evaluate jq itself, the filter needs jq on PATH
+// it can be set in the config file, for a profile or from the command line
+var jqFilter = ""
+
+// jqCommand is the jq executable filters are run with
+const jqCommand = "jq"
+
+// documentJson is a utility function that returns the input as JSON for jq
+// to read, back to back documents as an array like the listing shows them
+func documentJson(o any) []byte {
+	arr, ok := o.([]any)
+	if !ok {
+		return rawJson(o)
+	}
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for i, v := range arr {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.Write(documentJson(v))
+	}
+	b.WriteByte(']')
+	return b.Bytes()
+}
+
+// runJq runs the jq filter on the input and parses what it prints, which is
+// shown as it is when it is one value and as an array when it is several,
+// like back to back documents, or none
+// a filter wrapped in [ ] always gives an array, for inputs that change
+func runJq(ctx context.Context, filter string, data any) (any, error) {
+	cmd := exec.CommandContext(ctx, jqCommand, "-c", filter)
+	cmd.Stdin = bytes.NewReader(documentJson(data))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("cannot run the jq filter: %s is not on PATH", jqCommand)
+		}
+		// jq says what is wrong on its first line
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			return nil, fmt.Errorf("cannot run the jq filter: %s", msg)
+		}
+		return nil, fmt.Errorf("cannot run the jq filter: %w", err)
+	}
+	out := bytes.TrimSpace(stdout.Bytes())
+	switch {
+	case len(out) == 0:
+		return []any{}, nil
+	case json.Valid(out):
+		return json.RawMessage(out), nil
+	}
+	if docs := splitDocuments(out); docs != nil {
+		return docs, nil
+	}
+	return nil, fmt.Errorf("cannot read what the jq filter printed")
+}
@@ -0,0 +1,131 @@
+package jv
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// k8sObject holds the fields that identify a Kubernetes object
+type k8sObject struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Created   time.Time // zero if the object has no creationTimestamp
+}
+
+// stringAt returns the string value of a key, or "" if it is not a string
+func stringAt(children map[string]any, key string) string {
+	s, _ := materializeScalar(children[key]).(string)
+	return s
+}
+
+// k8sInfo is a utility function that reads the identifying fields of o if
+// it is a Kubernetes object, one with apiVersion, kind and metadata
+// items of a List often leave out their kind so listKind, the kind of the
+// List holding o if there is one, stands in for it
+func k8sInfo(o any, listKind string) (k8sObject, bool) {
+	if isArray(o) {
+		return k8sObject{}, false
+	}
+	children := getKAny(o)
+	meta := getKAny(children["metadata"])
+	if children == nil || meta == nil || isArray(children["metadata"]) {
+		return k8sObject{}, false
+	}
+	obj := k8sObject{
+		Kind:      stringAt(children, "kind"),
+		Name:      stringAt(meta, "name"),
+		Namespace: stringAt(meta, "namespace"),
+	}
+	if obj.Kind == "" || stringAt(children, "apiVersion") == "" {
+		if !strings.HasSuffix(listKind, "List") || obj.Name == "" {
+			return k8sObject{}, false
+		}
+		obj.Kind = strings.TrimSuffix(listKind, "List")
+	}
+	if created, err := time.Parse(time.RFC3339, stringAt(meta, "creationTimestamp")); err == nil {
+		obj.Created = created
+	}
+	return obj, true
+}
+
+// k8sAge is a utility function that formats how old an object is like kubectl
+func k8sAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+}
+
+// summary formats the object as a row like Pod/web-1  namespace: default  age: 3d
+func (obj k8sObject) summary() string {
+	s := obj.Kind + "/" + obj.Name
+	if obj.Namespace != "" {
+		s += "  namespace: " + obj.Namespace
+	}
+	if !obj.Created.IsZero() {
+		s += "  age: " + k8sAge(time.Since(obj.Created))
+	}
+	return s
+}
+
+// listKind returns the kind of the List whose items are shown, or "" if
+// the current level is not the items of a List
+func (m *Model) listKind() string {
+	if len(m.Path) == 0 || m.Path[len(m.Path)-1] != "items" {
+		return ""
+	}
+	return stringAt(getKAny(m.Nodes[len(m.Nodes)-2].value), "kind")
+}
+
+// k8sSummary returns the summary row of a Kubernetes object in the listing
+func (m *Model) k8sSummary(kv KVPair) (string, bool) {
+	if kv.More || kv.Path != nil || kv.Value != "{}" {
+		return "", false
+	}
+	lvl := m.currentLevel()
+	obj, ok := k8sInfo(lvl.children[kv.Key], lvl.listKind)
+	if !ok {
+		return "", false
+	}
+	return obj.summary(), true
+}
+
+// jumpSpecStatus goes to the spec of the Kubernetes object under the cursor
+// or around it, or to its status when already in the spec
+func (m *Model) jumpSpecStatus() {
+	path := m.Path
+	if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+		path = m.currentPath()
+	}
+	// the object is the deepest one on the way to the cursor
+	for n := len(path); n >= 0; n-- {
+		listKind := ""
+		if n > 1 && path[n-2] == "items" {
+			listKind = stringAt(getKAny(m.valueAt(path[:n-2])), "kind")
+		}
+		if _, ok := k8sInfo(m.valueAt(path[:n]), listKind); !ok {
+			continue
+		}
+		obj := append([]string{}, path[:n]...)
+		target := append(obj, "spec")
+		if len(m.Path) > n && m.Path[n] == "spec" {
+			target = append(obj, "status")
+		}
+		if m.checkPath(target) != nil {
+			m.Status = fmt.Sprintf("%s has no %s", selectorPath(m.Data, obj), target[len(target)-1])
+			return
+		}
+		m.goTo(target)
+		return
+	}
+	m.Status = "not inside a Kubernetes object"
+}
@@ -0,0 +1,498 @@
+package jv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds the key bindings for every action
+// the bindings can be changed in the [keys] table of the config file
+type KeyMap struct {
+	Quit             key.Binding
+	Up               key.Binding
+	Down             key.Binding
+	Left             key.Binding
+	Right            key.Binding
+	Expand           key.Binding
+	Back             key.Binding
+	Schema           key.Binding
+	Go               key.Binding
+	TypeScript       key.Binding
+	TypeScriptStrict key.Binding
+	Gron             key.Binding
+	Flatten          key.Binding
+	Filter           key.Binding
+	Duplicates       key.Binding
+	Bytes            key.Binding
+	Sort             key.Binding
+	Pager            key.Binding
+	CopyPath         key.Binding
+	CopyValue        key.Binding
+	Plugins          key.Binding
+	Follow           key.Binding
+	Return           key.Binding
+	SpecStatus       key.Binding
+	Diff             key.Binding
+	Trace            key.Binding
+	Map              key.Binding
+	Packages         key.Binding
+	Cancel           key.Binding
+	Recent           key.Binding
+	Reload           key.Binding
+	Record           key.Binding
+	Replay           key.Binding
+	Mark             key.Binding
+	Compare          key.Binding
+	Overlap          key.Binding
+	Sample           key.Binding
+	Slice            key.Binding
+	Mask             key.Binding
+	Reveal           key.Binding
+	Table            key.Binding
+	GoToPage         key.Binding
+	FullPath         key.Binding
+	Info             key.Binding
+	About            key.Binding
+	Outline          key.Binding
+	GoTo             key.Binding
+	CopyCurl         key.Binding
+	CopyEscaped      key.Binding
+	CopyKeys         key.Binding
+	CopyValues       key.Binding
+	CopyHTTPie       key.Binding
+	ExportMasked     key.Binding
+	LineNumbers      key.Binding
+	Wrap             key.Binding
+	Tag              key.Binding
+	ExportTags       key.Binding
+	NextKey          key.Binding
+	NextChange       key.Binding
+	Changes          key.Binding
+	Export           key.Binding
+	TimeFormat       key.Binding
+	Histogram        key.Binding
+	Pin              key.Binding
+	Conceal          key.Binding
+	ExportText       key.Binding
+	ExportHTML       key.Binding
+	FindValue        key.Binding
+	PinElement       key.Binding
+	Decode           key.Binding
+	PlainText        key.Binding
+	HideColumn       key.Binding
+	Columns          key.Binding
+	MoveLeft         key.Binding
+	MoveRight        key.Binding
+	ToggleColumn     key.Binding
+	AddColumn        key.Binding
+	Cheatsheet       key.Binding
+	// the tabs of the workspace
+	NextTab  key.Binding
+	PrevTab  key.Binding
+	CloseTab key.Binding
+	OpenTab  key.Binding
+	// the error screen
+	Retry key.Binding
+	Open  key.Binding
+	// the detail pane
+	Close    key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Write    key.Binding
+}
+
+// defaultKeyMap returns the key bindings used when the config file
+// does not change them
+func defaultKeyMap() KeyMap {
+	return KeyMap{
+		Quit:             key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "Quit")),
+		Up:               key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "Up")),
+		Down:             key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "Down")),
+		Left:             key.NewBinding(key.WithKeys("left"), key.WithHelp("←", "Left")),
+		Right:            key.NewBinding(key.WithKeys("right"), key.WithHelp("→", "Right")),
+		Expand:           key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "Expand")),
+		Back:             key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "Back")),
+		Schema:           key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "Schema")),
+		Go:               key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "Go")),
+		TypeScript:       key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "TypeScript")),
+		TypeScriptStrict: key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "Strict TypeScript")),
+		Gron:             key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "gron")),
+		Flatten:          key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "Flatten")),
+		Filter:           key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "Filter")),
+		Duplicates:       key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "Duplicates")),
+		Bytes:            key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "Bytes")),
+		Sort:             key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "Sort")),
+		Pager:            key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "Pager")),
+		CopyPath:         key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "Copy path")),
+		CopyValue:        key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "Copy value")),
+		Plugins:          key.NewBinding(key.WithKeys(":"), key.WithHelp(":", "Plugins")),
+		Follow:           key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "Follow")),
+		Return:           key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "Return")),
+		SpecStatus:       key.NewBinding(key.WithKeys("K"), key.WithHelp("K", "Spec/status")),
+		Diff:             key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "Diff")),
+		Trace:            key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "Trace")),
+		Map:              key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "Map")),
+		Packages:         key.NewBinding(key.WithKeys("B"), key.WithHelp("B", "Packages")),
+		Cancel:           key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "Cancel")),
+		Recent:           key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "Open recent")),
+		Reload:           key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "Reload")),
+		Record:           key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "Record macro")),
+		Replay:           key.NewBinding(key.WithKeys("@"), key.WithHelp("@", "Replay macro")),
+		Mark:             key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "Mark to compare")),
+		Compare:          key.NewBinding(key.WithKeys("="), key.WithHelp("=", "Compare with mark")),
+		Overlap:          key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "Overlap with mark")),
+		Sample:           key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "Sample")),
+		Slice:            key.NewBinding(key.WithKeys("["), key.WithHelp("[", "Slice")),
+		Mask:             key.NewBinding(key.WithKeys("*"), key.WithHelp("*", "Mask secrets")),
+		Reveal:           key.NewBinding(key.WithKeys("V"), key.WithHelp("V", "Reveal")),
+		Table:            key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "Table")),
+		GoToPage:         key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "Go to page")),
+		FullPath:         key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "Full path")),
+		Info:             key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "Info")),
+		About:            key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "About")),
+		Outline:          key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "Outline")),
+		GoTo:             key.NewBinding(key.WithKeys("."), key.WithHelp(".", "Go to path")),
+		CopyCurl:         key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "Copy as curl")),
+		CopyEscaped:      key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "Copy as string")),
+		CopyKeys:         key.NewBinding(key.WithKeys("k"), key.WithHelp("k", "Copy keys")),
+		CopyValues:       key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "Copy values")),
+		Cheatsheet:       key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "Keys")),
+		CopyHTTPie:       key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "Copy as HTTPie")),
+		ExportMasked:     key.NewBinding(key.WithKeys("E"), key.WithHelp("E", "Export masked")),
+		LineNumbers:      key.NewBinding(key.WithKeys("#"), key.WithHelp("#", "Row indices")),
+		Wrap:             key.NewBinding(key.WithKeys("ctrl+w"), key.WithHelp("ctrl+w", "Wrap")),
+		Tag:              key.NewBinding(key.WithKeys("+"), key.WithHelp("+", "Tag")),
+		ExportTags:       key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "Export tagged")),
+		NextKey:          key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "Next of key")),
+		NextChange:       key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "Next change")),
+		Changes:          key.NewBinding(key.WithKeys("}"), key.WithHelp("}", "Changes")),
+		Export:           key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "Export")),
+		TimeFormat:       key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "Time format")),
+		Histogram:        key.NewBinding(key.WithKeys("%"), key.WithHelp("%", "Histogram")),
+		Pin:              key.NewBinding(key.WithKeys("!"), key.WithHelp("!", "Pin key")),
+		PinElement:       key.NewBinding(key.WithKeys("|"), key.WithHelp("|", "Pin element")),
+		Conceal:          key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "Conceal")),
+		ExportText:       key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "Export text")),
+		ExportHTML:       key.NewBinding(key.WithKeys("W"), key.WithHelp("W", "Export HTML")),
+		FindValue:        key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "Find value")),
+		Decode:           key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "Decode")),
+		PlainText:        key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "Strip tags")),
+		HideColumn:       key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "Hide column")),
+		Columns:          key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "Columns")),
+		MoveLeft:         key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "Move left")),
+		MoveRight:        key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "Move right")),
+		ToggleColumn:     key.NewBinding(key.WithKeys(" ", "enter"), key.WithHelp("space", "Show/hide")),
+		AddColumn:        key.NewBinding(key.WithKeys("+"), key.WithHelp("+", "Add column")),
+		NextTab:          key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "Next tab")),
+		PrevTab:          key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "Previous tab")),
+		CloseTab:         key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "Close tab")),
+		OpenTab:          key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "Open in new tab")),
+		Retry:            key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "Retry")),
+		Open:             key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "Open file")),
+		Close:            key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc", "Close")),
+		PageUp:           key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "Page up")),
+		PageDown:         key.NewBinding(key.WithKeys("pgdown", " "), key.WithHelp("pgdown", "Page down")),
+		Write:            key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "Write to file")),
+	}
+}
+
+// actions maps the names used in the config file to their bindings
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":              &k.Quit,
+		"up":                &k.Up,
+		"down":              &k.Down,
+		"left":              &k.Left,
+		"right":             &k.Right,
+		"expand":            &k.Expand,
+		"back":              &k.Back,
+		"schema":            &k.Schema,
+		"go":                &k.Go,
+		"typescript":        &k.TypeScript,
+		"typescript_strict": &k.TypeScriptStrict,
+		"gron":              &k.Gron,
+		"flatten":           &k.Flatten,
+		"filter":            &k.Filter,
+		"duplicates":        &k.Duplicates,
+		"bytes":             &k.Bytes,
+		"sort":              &k.Sort,
+		"pager":             &k.Pager,
+		"copy_path":         &k.CopyPath,
+		"copy_value":        &k.CopyValue,
+		"plugins":           &k.Plugins,
+		"follow":            &k.Follow,
+		"return":            &k.Return,
+		"spec_status":       &k.SpecStatus,
+		"diff":              &k.Diff,
+		"trace":             &k.Trace,
+		"map":               &k.Map,
+		"packages":          &k.Packages,
+		"cancel":            &k.Cancel,
+		"recent":            &k.Recent,
+		"reload":            &k.Reload,
+		"record":            &k.Record,
+		"replay":            &k.Replay,
+		"mark":              &k.Mark,
+		"compare":           &k.Compare,
+		"overlap":           &k.Overlap,
+		"sample":            &k.Sample,
+		"slice":             &k.Slice,
+		"mask":              &k.Mask,
+		"reveal":            &k.Reveal,
+		"table":             &k.Table,
+		"go_to_page":        &k.GoToPage,
+		"full_path":         &k.FullPath,
+		"info":              &k.Info,
+		"about":             &k.About,
+		"outline":           &k.Outline,
+		"go_to":             &k.GoTo,
+		"copy_curl":         &k.CopyCurl,
+		"copy_escaped":      &k.CopyEscaped,
+		"copy_keys":         &k.CopyKeys,
+		"copy_values":       &k.CopyValues,
+		"copy_httpie":       &k.CopyHTTPie,
+		"export_masked":     &k.ExportMasked,
+		"line_numbers":      &k.LineNumbers,
+		"wrap":              &k.Wrap,
+		"tag":               &k.Tag,
+		"export_tags":       &k.ExportTags,
+		"next_key":          &k.NextKey,
+		"next_change":       &k.NextChange,
+		"changes":           &k.Changes,
+		"export":            &k.Export,
+		"time_format":       &k.TimeFormat,
+		"histogram":         &k.Histogram,
+		"pin":               &k.Pin,
+		"pin_element":       &k.PinElement,
+		"conceal":           &k.Conceal,
+		"export_text":       &k.ExportText,
+		"export_html":       &k.ExportHTML,
+		"find_value":        &k.FindValue,
+		"decode":            &k.Decode,
+		"plain_text":        &k.PlainText,
+		"hide_column":       &k.HideColumn,
+		"columns":           &k.Columns,
+		"move_left":         &k.MoveLeft,
+		"move_right":        &k.MoveRight,
+		"toggle_column":     &k.ToggleColumn,
+		"add_column":        &k.AddColumn,
+		"cheatsheet":        &k.Cheatsheet,
+		"next_tab":          &k.NextTab,
+		"prev_tab":          &k.PrevTab,
+		"close_tab":         &k.CloseTab,
+		"open_tab":          &k.OpenTab,
+		"retry":             &k.Retry,
+		"open":              &k.Open,
+		"close":             &k.Close,
+		"page_up":           &k.PageUp,
+		"page_down":         &k.PageDown,
+		"write":             &k.Write,
+	}
+}
+
+// rebind changes the keys of the named action
+// keys is a comma separated list like "k, up"
+func (k *KeyMap) rebind(action, keys string) error {
+	b, ok := k.actions()[action]
+	if !ok {
+		names := []string{}
+		for name := range k.actions() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown action %s, expected one of %s", action, strings.Join(names, ", "))
+	}
+	list := []string{}
+	for _, s := range strings.Split(keys, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("no keys given for %s", action)
+	}
+	b.SetKeys(list...)
+	b.SetHelp(strings.Join(list, "/"), b.Help().Desc)
+	return nil
+}
+
+// helpLine is a utility function that lists bindings as Desc: key pairs
+func helpLine(bindings ...key.Binding) string {
+	s := ""
+	for _, b := range bindings {
+		s += fmt.Sprintf("%s: %s  ", b.Help().Desc, b.Help().Key)
+	}
+	return strings.TrimSuffix(s, " ")
+}
+
+// listingBindings are the bindings of the listing
+func (k KeyMap) listingBindings() []key.Binding {
+	return []key.Binding{k.Quit, k.Up, k.Down, k.Left, k.Right, k.Expand, k.Back,
+		k.Schema, k.Go, k.TypeScript, k.TypeScriptStrict, k.Gron, k.Flatten,
+		k.Filter, k.Duplicates, k.Bytes, k.Sort, k.Pin, k.Conceal, k.Pager, k.CopyPath, k.CopyValue, k.CopyCurl, k.CopyHTTPie, k.CopyEscaped, k.CopyKeys, k.CopyValues, k.Decode, k.Plugins, k.Follow, k.Return, k.SpecStatus, k.Diff, k.Trace, k.Map, k.Packages, k.Recent, k.Reload, k.NextChange, k.Changes, k.Record, k.Replay, k.Mark, k.Compare, k.Overlap, k.PinElement, k.Sample, k.Histogram, k.Slice, k.Mask, k.Reveal, k.Export, k.ExportMasked, k.ExportText, k.ExportHTML, k.Tag, k.ExportTags, k.Table, k.GoToPage, k.FullPath, k.LineNumbers, k.Wrap, k.TimeFormat, k.Info, k.About, k.Outline, k.GoTo, k.NextKey, k.FindValue, k.Cheatsheet, k.Cancel}
+}
+
+// errorBindings are the bindings of the error loading the input
+// retrying is left out when it cannot work
+func (k KeyMap) errorBindings(retry bool) []key.Binding {
+	if !retry {
+		return []key.Binding{k.Quit, k.Open}
+	}
+	return []key.Binding{k.Quit, k.Retry, k.Open}
+}
+
+// detailBindings are the bindings of the detail pane
+// stripping tags is left out when the content has no plain text form
+func (k KeyMap) detailBindings(text bool) []key.Binding {
+	if text {
+		return []key.Binding{k.Close, k.Up, k.Down, k.PageUp, k.PageDown, k.Write, k.Pager, k.PlainText}
+	}
+	return []key.Binding{k.Close, k.Up, k.Down, k.PageUp, k.PageDown, k.Write, k.Pager}
+}
+
+// outlineBindings are the bindings while the outline is focused
+func (k KeyMap) outlineBindings() []key.Binding {
+	return []key.Binding{k.Close, k.Up, k.Down, k.PageUp, k.PageDown, k.Expand, k.Outline}
+}
+
+// tableBindings are the bindings of the table
+// moving up and down pages the rows and left and right focus a column
+func (k KeyMap) tableBindings() []key.Binding {
+	return []key.Binding{k.Close, k.Up, k.Down, k.Left, k.Right, k.PageUp, k.PageDown, k.Sort, k.HideColumn, k.Columns, k.AddColumn, k.MoveLeft, k.MoveRight, k.Expand}
+}
+
+// chooserBindings are the bindings of the column chooser
+func (k KeyMap) chooserBindings() []key.Binding {
+	return []key.Binding{k.Close, k.Up, k.Down, k.ToggleColumn, k.MoveLeft, k.MoveRight}
+}
+
+// tabBindings are the bindings of the tabs, which work everywhere
+// opening a tab is done from the recent inputs
+func (k KeyMap) tabBindings() []key.Binding {
+	return []key.Binding{k.NextTab, k.PrevTab, k.CloseTab, k.OpenTab}
+}
+
+// listingHelp is the help line shown below the listing
+// there are too many bindings to fit on one line so it shows the ones to
+// get around with and the key of the cheatsheet listing the rest
+func (k KeyMap) listingHelp() string {
+	more := fmt.Sprintf("More keys: %s", k.Cheatsheet.Help().Key)
+	return helpLine(k.Quit, k.Back, k.Filter, k.GoTo) + " " + more
+}
+
+// errorHelp is the help line shown below an error loading the input
+func (k KeyMap) errorHelp(retry bool) string {
+	return helpLine(k.errorBindings(retry)...)
+}
+
+// detailHelp is the help line shown below the detail pane
+func (k KeyMap) detailHelp(text bool) string {
+	return helpLine(k.detailBindings(text)...)
+}
+
+// outlineHelp is the help line shown while the outline is focused
+func (k KeyMap) outlineHelp() string {
+	return helpLine(k.outlineBindings()...)
+}
+
+// tableHelp is the help line shown below the table
+func (k KeyMap) tableHelp() string {
+	return helpLine(k.tableBindings()...)
+}
+
+// chooserHelp is the help line shown below the column chooser
+func (k KeyMap) chooserHelp() string {
+	return helpLine(k.chooserBindings()...)
+}
+
+// keyGroup is bindings that work in the same place, listed together in the
+// cheatsheet
+type keyGroup struct {
+	name     string
+	bindings []key.Binding
+}
+
+// bindingKeys is a utility function that lists the keys of a binding, with
+// the space bar named so that it can be seen
+func bindingKeys(b key.Binding) string {
+	list := []string{}
+	for _, k := range b.Keys() {
+		if k == " " {
+			k = "space"
+		}
+		list = append(list, k)
+	}
+	return strings.Join(list, ", ")
+}
+
+// cheatsheet lays out every binding as a table of its keys, the name of its
+// action in the config file and what it does, grouped by where it works
+// it is made from the same lists as the help lines so that it shows the
+// keys as they are bound, with the changes in the config file
+func (k KeyMap) cheatsheet() string {
+	groups := []keyGroup{
+		{"Listing", k.listingBindings()},
+		{"Outline", k.outlineBindings()},
+		{"Table", k.tableBindings()},
+		{"Columns", k.chooserBindings()},
+		{"Tabs", k.tabBindings()},
+		{"Detail pane", k.detailBindings(true)},
+		{"Error", k.errorBindings(true)},
+	}
+	// bindings are told apart by their keys and help since the lists hold
+	// copies of them
+	names := map[string]string{}
+	id := func(b key.Binding) string {
+		return strings.Join(b.Keys(), "\x00") + "\x00" + b.Help().Desc
+	}
+	for name, b := range k.actions() {
+		names[id(*b)] = name
+	}
+	listed := map[string]bool{}
+	for _, g := range groups {
+		for _, b := range g.bindings {
+			listed[id(b)] = true
+		}
+	}
+	// actions missing from every list are still shown
+	other := []string{}
+	for name, b := range k.actions() {
+		if !listed[id(*b)] {
+			other = append(other, name)
+		}
+	}
+	sort.Strings(other)
+	if len(other) > 0 {
+		bindings := []key.Binding{}
+		for _, name := range other {
+			bindings = append(bindings, *k.actions()[name])
+		}
+		groups = append(groups, keyGroup{"Other", bindings})
+	}
+	keysWidth, nameWidth := len("Keys"), len("Action")
+	for _, g := range groups {
+		for _, b := range g.bindings {
+			if n := len(bindingKeys(b)); n > keysWidth {
+				keysWidth = n
+			}
+			if n := len(names[id(b)]); n > nameWidth {
+				nameWidth = n
+			}
+		}
+	}
+	var s strings.Builder
+	for i, g := range groups {
+		if i > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(g.name + "\n")
+		fmt.Fprintf(&s, "  %-*s  %-*s  %s\n", keysWidth, "Keys", nameWidth, "Action", "Description")
+		for _, b := range g.bindings {
+			fmt.Fprintf(&s, "  %-*s  %-*s  %s\n", keysWidth, bindingKeys(b), nameWidth, names[id(b)], b.Help().Desc)
+		}
+	}
+	return strings.TrimSuffix(s.String(), "\n")
+}
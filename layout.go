@@ -0,0 +1,85 @@
+package jv
+
+import "fmt"
+
+// the smallest terminal the listing can be drawn in
+const (
+	minWidth  = 20
+	minHeight = 8
+)
+
+// resize lays the listing out again for a new terminal size
+func (m *Model) resize(width, height int) {
+	m.Width = width
+	m.Height = height
+	m.syncPage()
+	if m.Detail != nil {
+		m.scrollDetail(0)
+	}
+}
+
+// syncPage recomputes the number of pages, keeps the cursor on a row
+// that exists and moves to the page the cursor is on
+func (m *Model) syncPage() {
+	if m.Height > 0 {
+		m.Page.PerPage = m.Height - m.chromeHeight()
+		if m.Page.PerPage < 1 {
+			m.Page.PerPage = 1
+		}
+	}
+	m.Page.SetTotalPages(len(m.CurrKV))
+	if m.CurrC.RowNo >= len(m.CurrKV) {
+		m.CurrC.RowNo = len(m.CurrKV) - 1
+	}
+	if m.CurrC.RowNo < 0 {
+		m.CurrC.RowNo = 0
+	}
+	if m.Page.PerPage > 0 {
+		m.Page.Page = m.CurrC.RowNo / m.Page.PerPage
+	}
+	if m.wrapping() && len(m.CurrKV) > 0 {
+		m.syncWrap()
+	}
+}
+
+// chromeHeight is the number of lines around the rows of the listing
+// for the header, the paginator and the help line
+func (m *Model) chromeHeight() int {
+	n := 5
+	if m.Metrics != nil {
+		n++
+	}
+	if m.dupKeyCount() > 0 {
+		n++
+	}
+	if len(m.Relaxed) > 0 {
+		n++
+	}
+	if len(m.Violations) > 0 {
+		n++
+	}
+	if m.Status != "" {
+		n++
+	}
+	if len(m.Completions) > 0 {
+		n++
+	}
+	if m.tasksLine() != "" {
+		n++
+	}
+	if accessible {
+		n++
+	}
+	return n
+}
+
+// tooSmall checks if the terminal is too small to draw the listing in
+// the size is unknown until the first resize so it is not too small then
+func (m *Model) tooSmall() bool {
+	return m.Width > 0 && (m.Width < minWidth || m.Height < minHeight)
+}
+
+// viewTooSmall asks for a bigger terminal
+func (m *Model) viewTooSmall() string {
+	return fmt.Sprintf("Terminal too small\nneeds %d×%d\nhas %d×%d\n", minWidth, minHeight, m.Width, m.Height)
+}
@@ -0,0 +1,150 @@
+package jv
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// The input is kept as a json.RawMessage and containers are only split into
+// their children when they get expanded. The children are slices of the
+// original input so untouched subtrees are never parsed or copied.
+
+// skipSpace returns the index of the first non-whitespace byte at or after i
+func skipSpace(raw []byte, i int) int {
+	for i < len(raw) && (raw[i] == ' ' || raw[i] == '\t' || raw[i] == '\n' || raw[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// skipValue returns the index just past the JSON value starting at i
+// the input is expected to have been validated already
+func skipValue(raw []byte, i int) int {
+	switch raw[i] {
+	case '"':
+		// skip over the string including escaped quotes
+		i++
+		for i < len(raw) && raw[i] != '"' {
+			if raw[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		return i + 1
+	case '{', '[':
+		depth := 0
+		for i < len(raw) {
+			switch raw[i] {
+			case '"':
+				i = skipValue(raw, i)
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return i
+	}
+	// numbers, true, false and null run until the next delimiter
+	for i < len(raw) && !strings.ContainsRune(",}] \t\n\r", rune(raw[i])) {
+		i++
+	}
+	return i
+}
+
+// rawKind returns the first byte of a raw value which tells what kind of value it is
+func rawKind(raw json.RawMessage) byte {
+	i := skipSpace(raw, 0)
+	if i < len(raw) {
+		return raw[i]
+	}
+	return 0
+}
+
+// splitRaw splits a raw object or array into a map of its children
+// without parsing the children themselves
+// array elements are keyed by their index
+func splitRaw(raw json.RawMessage) map[string]any {
+	_, children := splitRawOrdered(raw)
+	return children
+}
+
+// splitRawOrdered splits a raw object or array like splitRaw and also
+// returns the keys in the order they first appear in the input
+func splitRawOrdered(raw json.RawMessage) ([]string, map[string]any) {
+	kind := rawKind(raw)
+	if kind != '{' && kind != '[' {
+		return nil, nil
+	}
+	keys := []string{}
+	children := make(map[string]any)
+	i := skipSpace(raw, 0) + 1
+	for n := 0; ; n++ {
+		i = skipSpace(raw, i)
+		if i >= len(raw) || raw[i] == '}' || raw[i] == ']' {
+			return keys, children
+		}
+		key := strconv.Itoa(n)
+		if kind == '{' {
+			end := skipValue(raw, i)
+			if err := json.Unmarshal(raw[i:end], &key); err != nil {
+				return keys, children
+			}
+			// step over the colon
+			i = skipSpace(raw, end) + 1
+			i = skipSpace(raw, i)
+		}
+		end := skipValue(raw, i)
+		if _, ok := children[key]; !ok {
+			keys = append(keys, key)
+		}
+		children[key] = raw[i:end]
+		// step over the comma
+		i = skipSpace(raw, end)
+		if i < len(raw) && raw[i] == ',' {
+			i++
+		}
+	}
+}
+
+// materialize is a utility function that fully parses any raw values
+// so the result only contains maps, slices and scalars
+func materialize(o any) any {
+	if span, ok := o.(fileSpan); ok {
+		return materialize(span.bytes())
+	}
+	if raw, ok := o.(json.RawMessage); ok {
+		data, err := decodeJson(raw)
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+	// back to back documents are an array of raw values
+	if arr, ok := o.([]any); ok {
+		parsed := make([]any, len(arr))
+		for i, v := range arr {
+			parsed[i] = materialize(v)
+		}
+		return parsed
+	}
+	return o
+}
+
+// isArray checks if the given any is an array, parsed or not
+func isArray(o any) bool {
+	if span, ok := o.(fileSpan); ok {
+		return span.kind == '['
+	}
+	if raw, ok := o.(json.RawMessage); ok {
+		return rawKind(raw) == '['
+	}
+	_, ok := o.([]any)
+	return ok
+}
@@ -0,0 +1,160 @@
+package jv
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// lenient accepts input that is almost JSON, it can be turned on from the command line
+var lenient = false
+
+// relaxFrame is an object or array being rewritten by relaxJson
+type relaxFrame struct {
+	isObj     bool
+	path      []string
+	key       string // key of the value being read in an object
+	expectKey bool   // the next token in an object is a key
+	index     int    // index of the value being read in an array
+}
+
+// isIdentStart checks if a byte can start an unquoted key or bare word
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// relaxJson is a utility function that rewrites input produced by tools that
+// are not strict about JSON into valid JSON
+// single quoted strings and unquoted keys get double quotes, and NaN and
+// Infinity become strings since JSON numbers cannot hold them
+// it returns the rewritten input and why each rewritten value was changed
+// keyed by the path of the value
+func relaxJson(content []byte) ([]byte, map[string]string) {
+	out := make([]byte, 0, len(content))
+	fixes := map[string]string{}
+	stack := []*relaxFrame{}
+	// valuePath returns the path of the value about to be read
+	valuePath := func() []string {
+		if len(stack) == 0 {
+			return []string{}
+		}
+		top := stack[len(stack)-1]
+		path := append([]string{}, top.path...)
+		if top.isObj {
+			return append(path, top.key)
+		}
+		return append(path, strconv.Itoa(top.index))
+	}
+	// fix records why the value about to be read was rewritten
+	fix := func(why string) {
+		key := pathKey(valuePath())
+		if fixes[key] != "" {
+			why = fixes[key] + ", " + why
+		}
+		fixes[key] = why
+	}
+	// atKey checks if the next token is an object key
+	atKey := func() bool {
+		return len(stack) > 0 && stack[len(stack)-1].isObj && stack[len(stack)-1].expectKey
+	}
+	// readKey records a key once it has been written out
+	readKey := func(quoted []byte) {
+		top := stack[len(stack)-1]
+		top.key = ""
+		json.Unmarshal(quoted, &top.key)
+		top.expectKey = false
+	}
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '"':
+			end := skipValue(content, i)
+			if end > len(content) {
+				end = len(content)
+			}
+			if atKey() {
+				readKey(content[i:end])
+			}
+			out = append(out, content[i:end]...)
+			i = end
+		case c == '\'':
+			// swap the quotes escaping any double quotes inside
+			s := []byte{'"'}
+			i++
+			for i < len(content) && content[i] != '\'' {
+				switch {
+				case content[i] == '\\' && i+1 < len(content) && content[i+1] == '\'':
+					s = append(s, '\'')
+					i += 2
+					continue
+				case content[i] == '\\' && i+1 < len(content):
+					s = append(s, content[i], content[i+1])
+					i += 2
+					continue
+				case content[i] == '"':
+					s = append(s, '\\')
+				}
+				s = append(s, content[i])
+				i++
+			}
+			i++
+			s = append(s, '"')
+			if atKey() {
+				readKey(s)
+				fix("single quoted key")
+			} else {
+				fix("single quoted string")
+			}
+			out = append(out, s...)
+		case isIdentStart(c) || (c == '-' && i+1 < len(content) && content[i+1] == 'I'):
+			end := i + 1
+			for end < len(content) && (isIdentStart(content[end]) || (content[end] >= '0' && content[end] <= '9')) {
+				end++
+			}
+			word := string(content[i:end])
+			i = end
+			switch {
+			case atKey():
+				quoted := strconv.Quote(word)
+				readKey([]byte(quoted))
+				fix("unquoted key")
+				out = append(out, quoted...)
+			case word == "NaN" || word == "Infinity" || word == "-Infinity":
+				fix(word)
+				out = append(out, strconv.Quote(word)...)
+			default:
+				// true, false and null
+				out = append(out, word...)
+			}
+		case c == '{' || c == '[':
+			stack = append(stack, &relaxFrame{isObj: c == '{', path: valuePath(), expectKey: true})
+			out = append(out, c)
+			i++
+		case c == '}' || c == ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			out = append(out, c)
+			i++
+		case c == ',':
+			if len(stack) > 0 {
+				top := stack[len(stack)-1]
+				top.expectKey = true
+				top.index++
+			}
+			out = append(out, c)
+			i++
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return out, fixes
+}
+
+// relaxedNote returns why the value at a path had to be rewritten, if it was
+func (m *Model) relaxedNote(path []string) string {
+	if len(m.Relaxed) == 0 {
+		return ""
+	}
+	return m.Relaxed[pathKey(path)]
+}
@@ -0,0 +1,71 @@
+package jv
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRelaxJson(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		fixes map[string]string
+	}{
+		{
+			name:  "valid JSON is left as it is",
+			input: `{"a": [1, "x", true, null]}`,
+			want:  `{"a": [1, "x", true, null]}`,
+			fixes: map[string]string{},
+		},
+		{
+			name:  "unquoted keys",
+			input: `{a: 1, $b_2: {c: 2}}`,
+			want:  `{"a": 1, "$b_2": {"c": 2}}`,
+			fixes: map[string]string{
+				`["a"]`:        "unquoted key",
+				`["$b_2"]`:     "unquoted key",
+				`["$b_2","c"]`: "unquoted key",
+			},
+		},
+		{
+			name:  "single quoted keys and strings",
+			input: `{'a': 'it\'s "x"'}`,
+			want:  `{"a": "it's \"x\""}`,
+			fixes: map[string]string{`["a"]`: "single quoted key, single quoted string"},
+		},
+		{
+			name:  "NaN and infinities in an array",
+			input: `[1, NaN, Infinity, -Infinity]`,
+			want:  `[1, "NaN", "Infinity", "-Infinity"]`,
+			fixes: map[string]string{`["1"]`: "NaN", `["2"]`: "Infinity", `["3"]`: "-Infinity"},
+		},
+		{
+			name:  "words inside strings are left alone",
+			input: `{"k": "NaN and 'quotes'"}`,
+			want:  `{"k": "NaN and 'quotes'"}`,
+			fixes: map[string]string{},
+		},
+		{
+			name:  "negative numbers are left alone",
+			input: `{n: -1}`,
+			want:  `{"n": -1}`,
+			fixes: map[string]string{`["n"]`: "unquoted key"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fixes := relaxJson([]byte(tt.input))
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if !json.Valid(got) {
+				t.Errorf("%s is not valid JSON", got)
+			}
+			if !reflect.DeepEqual(fixes, tt.fixes) {
+				t.Errorf("fixes %v, want %v", fixes, tt.fixes)
+			}
+		})
+	}
+}
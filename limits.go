@@ -0,0 +1,36 @@
+package jv
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Limits keep documents that are nested very deeply or have huge keys or
+// values from blowing the stack or freezing rendering
+type Limits struct {
+	Depth    int // deepest level that gets walked or expanded
+	KeyLen   int // longest key shown in full
+	ValueLen int // longest value shown in full
+}
+
+// limits are the limits in use, they can be changed from the command line
+var limits = Limits{Depth: 1000, KeyLen: 256, ValueLen: 4096}
+
+// truncate is a utility function that shortens s to at most n bytes
+// without splitting a character and says how much was left out
+// a limit of 0 or less means no limit
+func truncate(s string, n int) string {
+	if n <= 0 || len(s) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s… (+%d bytes)", s[:cut], len(s)-cut)
+}
+
+// tooDeep checks if a path has reached the nesting limit
+func tooDeep(path []string) bool {
+	return limits.Depth > 0 && len(path) >= limits.Depth
+}
@@ -0,0 +1,93 @@
+package jv
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// linkMsg is sent once a linked resource has been fetched
+type linkMsg struct {
+	Href string // the link that was followed
+	Data any    // the resource, redacted like the input
+	Err  error
+}
+
+// linkAt returns the hyperlink of the value under the cursor, which is
+// either a URL string like JSON:API's links.next or a HAL link object
+// like _links.self with an href
+func (m *Model) linkAt() string {
+	node := m.currentNode()
+	if href, ok := materializeScalar(node).(string); ok && isURL(href) {
+		return href
+	}
+	if children := getKAny(node); children != nil && !isArray(node) {
+		if href, ok := materializeScalar(children["href"]).(string); ok {
+			return href
+		}
+	}
+	return ""
+}
+
+// follow follows the $ref or hyperlink under the cursor
+func (m *Model) follow() tea.Cmd {
+	if m.refAt() != "" {
+		return m.followRef()
+	}
+	href := m.linkAt()
+	if href == "" {
+		m.Status = "no $ref or link under the cursor"
+		return nil
+	}
+	// links relative to a fetched document are relative to its URL
+	if !isURL(href) && isURL(m.Source) {
+		base, err := url.Parse(m.Source)
+		rel, rerr := url.Parse(href)
+		if err != nil || rerr != nil {
+			m.Status = fmt.Sprintf("cannot follow %s: not a URL", href)
+			return nil
+		}
+		href = base.ResolveReference(rel).String()
+	}
+	if !isURL(href) {
+		m.Status = fmt.Sprintf("cannot follow %s: not a URL", href)
+		return nil
+	}
+	// the headers and credentials of the input are only sent to where the
+	// input came from, never to wherever the document points
+	anonymous := !sameOrigin(href, m.Source)
+	return m.startTask("fetch", "fetching "+sanitize(href), func(ctx context.Context, _ func(int)) tea.Msg {
+		return fetchLink(ctx, href, anonymous)
+	})
+}
+
+// fetchLink fetches a linked resource, with the same headers and
+// credentials as the input unless anonymous is set
+// the resource is redacted like the input since it is shown and can be
+// copied and exported the same way
+func fetchLink(ctx context.Context, href string, anonymous bool) tea.Msg {
+	debugLog.Printf("following link %s", href)
+	data, _, err := readSource(ctx, &loadProgress{}, urlSource{Anonymous: anonymous}, href)
+	if err != nil {
+		return linkMsg{Href: href, Err: err}
+	}
+	data, _ = redactInput(data, nil)
+	return linkMsg{Href: href, Data: data}
+}
+
+// finishLink opens a fetched resource in a new tab
+// the tab shows the resource as it was fetched rather than fetching it
+// again, since loading it as an input would send the input's credentials
+// wherever it is
+func (m *Model) finishLink(msg linkMsg) tea.Cmd {
+	if msg.Err != nil {
+		m.Status = fmt.Sprintf("cannot follow %s: %s", msg.Href, msg.Err)
+		return nil
+	}
+	m.Status = ""
+	doc := m.sibling(msg.Href, false)
+	doc.loaded = &loadedMsg{Data: msg.Data}
+	return openDoc(doc)
+}
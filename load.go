@@ -0,0 +1,219 @@
+package jv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errLoadCancelled is shown once loading the input has been cancelled
+var errLoadCancelled = errors.New("loading was cancelled")
+
+// spinnerFrames are shown in turn while the input is loading
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// loadProgress is updated by the loader and read by the UI
+// the fields are only accessed atomically
+type loadProgress struct {
+	Bytes    int64 // bytes read so far
+	Total    int64 // total size of the input, 0 if unknown
+	Elements int64 // JSON elements parsed so far
+}
+
+// progressReader counts the bytes read through it and stops
+// reading once its context is cancelled
+type progressReader struct {
+	ctx   context.Context
+	r     io.Reader
+	count *int64
+}
+
+// Read reads from the underlying reader unless loading has been cancelled
+func (pr progressReader) Read(b []byte) (int, error) {
+	if err := pr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := pr.r.Read(b)
+	atomic.AddInt64(pr.count, int64(n))
+	return n, err
+}
+
+// loadedMsg is sent once the input has been loaded
+type loadedMsg struct {
+	Data    any
+	Relaxed map[string]string // values rewritten in lenient mode keyed by their path
+	Err     error
+	Elapsed time.Duration // time taken to load the input
+	Key     string        // hash the session of the input is kept under
+}
+
+// spinnerMsg advances the loading spinner
+type spinnerMsg struct{}
+
+// loadCmd loads the input in the background
+// path is a git object such as HEAD~1:config.json if git is set
+func loadCmd(ctx context.Context, path string, git bool, progress *loadProgress) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		if path == "" {
+			debugLog.Printf("loading standard input")
+		} else {
+			debugLog.Printf("loading %s", path)
+		}
+		data, relaxed, err := readJsonSource(ctx, progress, path, git)
+		// a cancelled load has been given up on already
+		if ctx.Err() != nil {
+			debugLog.Printf("loading cancelled after %s", time.Since(start))
+			return nil
+		}
+		if err != nil {
+			debugLog.Printf("loading failed after %s: %s", time.Since(start), err)
+			return loadedMsg{Err: err, Elapsed: time.Since(start)}
+		}
+		debugLog.Printf("loaded in %s", time.Since(start))
+		elapsed := time.Since(start)
+		addRecent(path, git)
+		return loadedMsg{Data: data, Relaxed: relaxed, Elapsed: elapsed, Key: sessionKey(path, data)}
+	}
+}
+
+// spinnerTick schedules the next frame of the loading spinner
+// in accessible mode the loading screen is only redrawn once a second
+func spinnerTick() tea.Cmd {
+	interval := 100 * time.Millisecond
+	if accessible {
+		interval = time.Second
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return spinnerMsg{}
+	})
+}
+
+// finishLoad sets up the model with the loaded data
+func (m *Model) finishLoad(msg loadedMsg) tea.Cmd {
+	m.Loading = false
+	m.LoadTime = msg.Elapsed
+	if m.Metrics != nil {
+		m.Metrics.ParseTime = msg.Elapsed
+	}
+	// the error is shown until it is retried or another file is opened,
+	// or the input changes when it is watched
+	if msg.Err != nil {
+		m.Err = msg.Err
+		return m.startWatch()
+	}
+	m.Data = msg.Data
+	m.Relaxed = msg.Relaxed
+	m.Nodes, m.level = []*Node{NewNode(msg.Data)}, nil
+	m.refreshOutline()
+	// strings, numbers, booleans and null have no keys to navigate
+	if getKAny(msg.Data) == nil {
+		m.Scalar = true
+		return nil
+	}
+	m.sessionKey = msg.Key
+	m.applyDetected()
+	// streamed inputs cannot be redacted, so an input the detected profile
+	// has redaction rules for is read into memory again
+	if isStreamed(m.Data) && len(redactRules) > 0 {
+		debugLog.Printf("reading the streamed input again to redact it")
+		return m.reload(m.Source, m.Git)
+	}
+	m.updateKV()
+	// the listing opens where it was before reloading, at the start path or
+	// where it was left last time
+	cmds := []tea.Cmd{}
+	reloading := m.spot != nil
+	if reloading {
+		cmds = append(cmds, m.returnToSpot(m.spot))
+		if m.spot.Data != nil {
+			cmds = append(cmds, m.diffReload(m.spot.Data))
+		}
+		m.spot = nil
+	} else if len(m.Start) > 0 {
+		if missing := m.goToNearest(m.Start); missing != "" {
+			m.Status = "the start path is not in the input, " + missing
+		}
+	} else {
+		cmds = append(cmds, m.restoreSession())
+	}
+	// reloading keeps the view that was open
+	if !reloading {
+		m.openStartView()
+	}
+	m.syncPage()
+	// inputs that can be reloaded are compared with what they held now
+	if m.canRetry() {
+		m.previous = snapshot(m.Data)
+	}
+	cmds = append(cmds, m.startIndex(), dupKeysCmd(m.Data), m.startWatch())
+	if m.Schema != nil {
+		cmds = append(cmds, validateCmd(m.Schema, m.Data))
+	}
+	return tea.Batch(cmds...)
+}
+
+// viewLoading renders the progress of loading the input
+func (m *Model) viewLoading() string {
+	source := m.Source
+	if source == "" {
+		source = "stdin"
+	}
+	s := fmt.Sprintf("%s Loading %s  %s read",
+		spinnerFrames[m.Frame%len(spinnerFrames)], sanitize(source), humanBytes(atomic.LoadInt64(&m.Progress.Bytes)))
+	if total := atomic.LoadInt64(&m.Progress.Total); total > 0 {
+		s += fmt.Sprintf(" of %s", humanBytes(total))
+	}
+	if elements := atomic.LoadInt64(&m.Progress.Elements); elements > 0 {
+		s += fmt.Sprintf("  %d elements parsed", elements)
+	}
+	if !m.loadStart.IsZero() {
+		s += fmt.Sprintf("  %s", time.Since(m.loadStart).Round(100*time.Millisecond))
+	}
+	s += fmt.Sprintf("\n\n%s: %s  %s: %s \n", m.Keys.Cancel.Help().Desc, m.Keys.Cancel.Help().Key,
+		m.Keys.Quit.Help().Desc, m.Keys.Quit.Help().Key)
+	return s
+}
+
+// cancelLoading gives up on loading the input, leaving the error screen to
+// load it again or to open another input
+func (m *Model) cancelLoading() {
+	if m.cancelLoad != nil {
+		m.cancelLoad()
+	}
+	debugLog.Printf("cancelled loading %s", m.Source)
+	m.Loading = false
+	m.Err = errLoadCancelled
+}
+
+// humanBytes is a utility function that formats a byte count for display
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// viewScalar renders a document that is a single string, number, boolean or null
+func (m *Model) viewScalar() string {
+	content, err := json.Marshal(materialize(m.Data))
+	if err != nil {
+		content = []byte(err.Error())
+	}
+	s := "You are here: (top-level value)\n\n"
+	s += fmt.Sprintf("%s\n", content)
+	s += "\n\nQuit: ctrl+c \n"
+	return s
+}
@@ -0,0 +1,121 @@
+package jv
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// macroMaxRuns is the most times a macro is replayed in one go, so that a
+// mistyped count does not hang the interface
+const macroMaxRuns = 1000
+
+// macro is a recorded sequence of key presses that can be replayed
+type macro struct {
+	Recording bool         // key presses are being recorded
+	Keys      []tea.KeyMsg // keys of the last macro recorded
+	Count     string       // digits typed before replaying, how many times to replay
+	recording []tea.KeyMsg // keys recorded so far
+	replaying bool         // the macro is being replayed, which is not recorded
+}
+
+// recordKey adds a key press to the macro being recorded
+// the keys that start and stop recording and replay are left out, a macro
+// replaying itself would never end
+func (m *Model) recordKey(msg tea.KeyMsg) {
+	if !m.Macro.Recording || m.Macro.replaying {
+		return
+	}
+	if m.mode() == modeNormal && (key.Matches(msg, m.Keys.Record) || key.Matches(msg, m.Keys.Replay) || m.countDigitKey(msg)) {
+		return
+	}
+	m.Macro.recording = append(m.Macro.recording, msg)
+}
+
+// toggleRecording starts recording a macro or stops and keeps it
+func (m *Model) toggleRecording() {
+	mc := &m.Macro
+	if !mc.Recording {
+		mc.Recording, mc.recording = true, nil
+		m.Status = fmt.Sprintf("recording a macro, %s stops", m.Keys.Record.Help().Key)
+		return
+	}
+	mc.Recording = false
+	if len(mc.recording) == 0 {
+		m.Status = "no keys recorded, the last macro is kept"
+		return
+	}
+	mc.Keys = mc.recording
+	keys := "keys"
+	if len(mc.Keys) == 1 {
+		keys = "key"
+	}
+	m.Status = fmt.Sprintf("recorded %d %s, %s replays the macro", len(mc.Keys), keys, m.Keys.Replay.Help().Key)
+}
+
+// countDigitKey reports whether a key is a digit of the count of times to
+// replay the macro, which only counts once there is a macro
+func (m *Model) countDigitKey(msg tea.KeyMsg) bool {
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 || len(m.Macro.Keys) == 0 {
+		return false
+	}
+	r := msg.Runes[0]
+	return r >= '1' && r <= '9' || (r == '0' && m.Macro.Count != "")
+}
+
+// countDigit adds a digit to the count of times to replay the macro and
+// reports whether the key was one
+func (m *Model) countDigit(msg tea.KeyMsg) bool {
+	if !m.countDigitKey(msg) {
+		return false
+	}
+	m.Macro.Count += string(msg.Runes)
+	m.Status = fmt.Sprintf("%s× %s replays the macro", m.Macro.Count, m.Keys.Replay.Help().Key)
+	return true
+}
+
+// macroMsg replays the keys of a macro
+type macroMsg struct {
+	Keys []tea.KeyMsg
+	Runs int
+}
+
+// replayMacro replays the last macro as many times as the count typed
+// before it, or once
+// the keys are pressed once the key that replays them has been handled
+func (m *Model) replayMacro(count string) tea.Cmd {
+	if len(m.Macro.Keys) == 0 {
+		m.Status = fmt.Sprintf("no macro recorded, %s starts recording one", m.Keys.Record.Help().Key)
+		return nil
+	}
+	runs, err := strconv.Atoi(count)
+	if err != nil {
+		runs = 1
+	}
+	if runs > macroMaxRuns {
+		runs = macroMaxRuns
+	}
+	msg := macroMsg{Keys: m.Macro.Keys, Runs: runs}
+	return func() tea.Msg { return msg }
+}
+
+// playMacro presses the keys of a macro
+// the commands they start run once the replay is done
+func (m *Model) playMacro(msg macroMsg) tea.Cmd {
+	debugLog.Printf("replaying %d keys %d times", len(msg.Keys), msg.Runs)
+	m.Macro.replaying = true
+	defer func() { m.Macro.replaying = false }()
+	cmds := []tea.Cmd{}
+	for i := 0; i < msg.Runs; i++ {
+		for _, k := range msg.Keys {
+			_, cmd := m.Update(k)
+			cmds = append(cmds, cmd)
+		}
+	}
+	if m.Status == "" && msg.Runs > 1 {
+		m.Status = fmt.Sprintf("replayed the macro %d times", msg.Runs)
+	}
+	return tea.Batch(cmds...)
+}
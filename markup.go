@@ -0,0 +1,202 @@
+package jv
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseMarkup is a utility function that reads the tokens of an XML or HTML
+// document held in a string, and false if it is not one
+// HTML is read leniently, with its unclosed elements and named entities
+// the tokens keep the namespace prefixes as they are written, and elements
+// such as <br> that HTML leaves unclosed have no end
+func parseMarkup(s string) ([]xml.Token, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "<") || !strings.HasSuffix(s, ">") {
+		return nil, false
+	}
+	decoder := func() *xml.Decoder {
+		d := xml.NewDecoder(strings.NewReader(s))
+		d.Strict = false
+		d.AutoClose = xml.HTMLAutoClose
+		d.Entity = xml.HTMLEntity
+		return d
+	}
+	// the elements are checked to match first, raw tokens are not
+	d, elements := decoder(), 0
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+		if _, ok := t.(xml.StartElement); ok {
+			elements++
+		}
+	}
+	if elements == 0 {
+		return nil, false
+	}
+	d, tokens := decoder(), []xml.Token{}
+	for {
+		t, err := d.RawToken()
+		if err != nil {
+			break
+		}
+		tokens = append(tokens, xml.CopyToken(t))
+	}
+	return tokens, true
+}
+
+// voidElement is a utility function that reports whether an HTML element
+// is one that is never closed, such as <br>
+func voidElement(name xml.Name) bool {
+	for _, v := range xml.HTMLAutoClose {
+		if strings.EqualFold(name.Local, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// markupKind is a utility function that names the kind of a document from
+// its tokens, HTML or the root element of XML
+func markupKind(tokens []xml.Token) string {
+	for _, t := range tokens {
+		switch t := t.(type) {
+		case xml.Directive:
+			if strings.HasPrefix(strings.ToLower(string(t)), "doctype html") {
+				return "HTML"
+			}
+		case xml.StartElement:
+			if strings.EqualFold(t.Name.Local, "html") {
+				return "HTML"
+			}
+			return fmt.Sprintf("XML <%s>", t.Name.Local)
+		}
+	}
+	return "XML"
+}
+
+// renderMarkup says that a string holds an XML or HTML document
+func renderMarkup(value any) (string, bool) {
+	s, _ := value.(string)
+	tokens, ok := parseMarkup(s)
+	if !ok {
+		return "", false
+	}
+	return markupKind(tokens) + " document", true
+}
+
+// markupName is a utility function that writes the name of an element or
+// attribute with its namespace prefix
+func markupName(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+// startTag is a utility function that writes the start tag of an element,
+// ending it with end such as > or />
+func startTag(e xml.StartElement, end string) string {
+	s := "<" + markupName(e.Name)
+	for _, a := range e.Attr {
+		s += fmt.Sprintf(" %s=%q", markupName(a.Name), a.Value)
+	}
+	return s + end
+}
+
+// showMarkup indents an XML or HTML document one element per line,
+// elements holding only a short text stay on one line
+func showMarkup(s string) (string, bool) {
+	tokens, ok := parseMarkup(s)
+	if !ok {
+		return "", false
+	}
+	lines, depth := []string{}, 0
+	add := func(line string) {
+		lines = append(lines, strings.Repeat("  ", depth)+sanitize(line))
+	}
+	for i := 0; i < len(tokens); i++ {
+		switch t := tokens[i].(type) {
+		case xml.StartElement:
+			// <a/> and <a>text</a> are kept together
+			if i+1 < len(tokens) {
+				if end, ok := tokens[i+1].(xml.EndElement); ok && end.Name == t.Name {
+					add(startTag(t, "/>"))
+					i++
+					continue
+				}
+			}
+			if i+2 < len(tokens) {
+				text, isText := tokens[i+1].(xml.CharData)
+				end, isEnd := tokens[i+2].(xml.EndElement)
+				if isText && isEnd && end.Name == t.Name && !strings.Contains(strings.TrimSpace(string(text)), "\n") {
+					add(startTag(t, ">") + strings.TrimSpace(string(text)) + "</" + markupName(end.Name) + ">")
+					i += 2
+					continue
+				}
+			}
+			add(startTag(t, ">"))
+			if !voidElement(t.Name) {
+				depth++
+			}
+		case xml.EndElement:
+			if depth > 0 {
+				depth--
+			}
+			add("</" + markupName(t.Name) + ">")
+		case xml.CharData:
+			for _, line := range strings.Split(strings.TrimSpace(string(t)), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					add(line)
+				}
+			}
+		case xml.Comment:
+			add("<!--" + string(t) + "-->")
+		case xml.ProcInst:
+			add(fmt.Sprintf("<?%s %s?>", t.Target, t.Inst))
+		case xml.Directive:
+			add("<!" + string(t) + ">")
+		}
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// markupText is a utility function that returns the text of an XML or HTML
+// document without its tags, one line for every run of text
+// scripts and styles are left out since they are not for reading
+func markupText(s string) (string, bool) {
+	tokens, ok := parseMarkup(s)
+	if !ok {
+		return "", false
+	}
+	lines, skip := []string{}, 0
+	for _, t := range tokens {
+		switch t := t.(type) {
+		case xml.StartElement:
+			if name := strings.ToLower(t.Name.Local); name == "script" || name == "style" {
+				skip++
+			}
+		case xml.EndElement:
+			if name := strings.ToLower(t.Name.Local); (name == "script" || name == "style") && skip > 0 {
+				skip--
+			}
+		case xml.CharData:
+			if skip > 0 {
+				continue
+			}
+			for _, line := range strings.Split(string(t), "\n") {
+				if line = strings.Join(strings.Fields(line), " "); line != "" {
+					lines = append(lines, sanitize(line))
+				}
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), true
+}
@@ -0,0 +1,30 @@
+package jv
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Metrics are shown in the top right corner to keep an eye on performance
+type Metrics struct {
+	ParseTime    time.Duration // time taken to load the input
+	UpdateTime   time.Duration // time taken by the last update
+	RowsRendered int           // rows formatted for the last frame
+}
+
+// viewMetrics renders the metrics right aligned on a line of their own
+func (m *Model) viewMetrics() string {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	s := fmt.Sprintf("[parse %s  heap %s  rows %d  update %s]",
+		m.Metrics.ParseTime.Round(time.Microsecond),
+		humanBytes(int64(mem.HeapAlloc)),
+		m.Metrics.RowsRendered,
+		m.Metrics.UpdateTime.Round(time.Microsecond))
+	if pad := m.Width - len([]rune(s)); pad > 0 {
+		s = strings.Repeat(" ", pad) + s
+	}
+	return s + "\n"
+}
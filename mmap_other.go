@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package jv
+
+import (
+	"errors"
+	"os"
+)
+
+// mmapFile is a utility function that fails on platforms where files
+// cannot be mapped, so that they are read into memory instead
+func mmapFile(f *os.File, size int64) ([]byte, error) {
+	return nil, errors.New("files cannot be mapped on this platform")
+}
@@ -0,0 +1,14 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package jv
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile is a utility function that maps an open file into memory
+// read-only, the mapping is kept for the lifetime of the program
+func mmapFile(f *os.File, size int64) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+}
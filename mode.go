@@ -0,0 +1,115 @@
+package jv
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mode is what the keys typed are for at the moment
+type mode int
+
+// modes of the interface
+const (
+	modeLoading   mode = iota // the input is still loading and can only be cancelled
+	modeError                 // the input could not be loaded
+	modeScalar                // the document is a single value that can only be looked at
+	modeNormal                // moving around the listing
+	modeFilter                // typing the filter of the flattened view
+	modeCommand               // picking a plugin in the palette
+	modeDetail                // reading the detail pane
+	modeRecent                // picking an input opened before
+	modeSlice                 // typing the range of an array to list
+	modeTable                 // looking at an array of objects as a table
+	modePage                  // typing the number of the page to go to
+	modeGoTo                  // typing the path to go to
+	modeTransform             // decoding a string step by step
+	modeExport                // picking a format to export the value in
+	modeOutline               // picking a key in the outline to go to
+	modeTag                   // typing the name of the value being tagged
+)
+
+// String names the mode for the debug log
+func (md mode) String() string {
+	return [...]string{"loading", "error", "scalar", "normal", "filter", "command", "detail", "recent", "slice", "table", "page", "goto", "transform", "export", "outline", "tag"}[md]
+}
+
+// modeUpdates handle the key presses of each mode
+// the detail pane and the table are left out since they take the mouse as well
+var modeUpdates = map[mode]func(*Model, tea.KeyMsg) (tea.Model, tea.Cmd){
+	modeLoading:   (*Model).updateLoading,
+	modeError:     (*Model).updateError,
+	modeScalar:    (*Model).updateScalar,
+	modeNormal:    (*Model).updateNormal,
+	modeFilter:    (*Model).updateFilter,
+	modeCommand:   (*Model).updatePalette,
+	modeRecent:    (*Model).updateRecent,
+	modeSlice:     (*Model).updateSlice,
+	modePage:      (*Model).updatePagePrompt,
+	modeGoTo:      (*Model).updateGoTo,
+	modeTransform: (*Model).updateTransform,
+	modeExport:    (*Model).updateExportMenu,
+	modeOutline:   (*Model).updateOutline,
+	modeTag:       (*Model).updateTag,
+}
+
+// mode returns the mode the model is in
+// it is worked out from what is open so that it cannot disagree with it,
+// the pane or prompt opened last comes first
+func (m *Model) mode() mode {
+	switch {
+	case m.Loading:
+		return modeLoading
+	case m.Err != nil:
+		return modeError
+	case m.Recent != nil:
+		return modeRecent
+	case m.Scalar:
+		return modeScalar
+	case m.Detail != nil:
+		return modeDetail
+	case m.Table != nil:
+		return modeTable
+	case m.Palette != nil:
+		return modeCommand
+	case m.Transform != nil:
+		return modeTransform
+	case m.Exporting != nil:
+		return modeExport
+	case m.Outline != nil && m.Outline.Focused:
+		return modeOutline
+	case m.Filtering:
+		return modeFilter
+	case m.Slicing:
+		return modeSlice
+	case m.PagePrompt:
+		return modePage
+	case m.GoingTo:
+		return modeGoTo
+	case m.Tagging:
+		return modeTag
+	}
+	return modeNormal
+}
+
+// updateLoading handles the keys while the input is loading, which can
+// only be cancelled or quit
+func (m *Model) updateLoading(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.Keys.Quit):
+		if m.cancelLoad != nil {
+			m.cancelLoad()
+		}
+		return m, tea.Quit
+	case key.Matches(msg, m.Keys.Cancel):
+		m.cancelLoading()
+	}
+	return m, nil
+}
+
+// updateScalar handles the keys of a document that is a single value
+func (m *Model) updateScalar(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.Keys.Quit) {
+		return m, tea.Quit
+	}
+	return m, nil
+}
@@ -1,8 +1,14 @@
-package main
+package jv
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/key"
 	page "github.com/charmbracelet/bubbles/paginator"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -11,6 +17,9 @@ import (
 type KVPair struct {
 	Key   string
 	Value string
+	Path  []string // full path to the value, only set in the flattened view
+	More  bool     // pseudo-row that loads more elements of a large array
+	Index bool     // the key is an array index rather than an object key
 }
 
 // Cursor contains the cursor's horizontal and vertical position
@@ -23,25 +32,108 @@ type Cursor struct {
 
 // Model contains the data and its visual representation
 type Model struct {
-	Data   any        // contains the parsed JSON data
-	CurrC  Cursor     // the cursor position
-	CurrKV []KVPair   // current list of key-value pairs
-	Path   []string   // current path location
-	Page   page.Model // paginator
+	Data        any                 // contains the parsed JSON data
+	CurrC       Cursor              // the cursor position
+	CurrKV      []KVPair            // current list of key-value pairs
+	Path        []string            // current path location
+	Nodes       []*Node             // node at every level of the path starting with the root
+	Page        page.Model          // paginator
+	Width       int                 // terminal width
+	Height      int                 // terminal height
+	Detail      *Detail             // detail pane, nil when it is not shown
+	Flat        bool                // show every leaf in the document instead of the current level
+	Filter      string              // only leaves matching this are shown in the flattened view
+	Filtering   bool                // the filter is being typed
+	KVCache     map[string][]KVPair // key-value pairs of every visited level keyed by path
+	Cursors     map[string]Cursor   // cursor of every level left by going into a value keyed by path
+	Shown       map[string]int      // number of elements loaded of each large array keyed by path
+	Samples     map[string][]int    // random elements listed of each sampled array keyed by path
+	Slices      map[string][2]int   // range of elements listed of each sliced array keyed by path
+	Slicing     bool                // the range of the current array is being typed
+	SliceInput  string              // range of the current array being typed, such as 100:200
+	Masking     bool                // likely credentials are masked in the listing and in copies
+	LineNumbers bool                // the index of every row is shown in a gutter
+	Wrap        bool                // long rows are wrapped onto more lines rather than cut short
+	Revealed    map[string]bool     // masked values shown anyway keyed by path
+	TimeFormats map[string]string   // formats of timestamps shown otherwise than the rest keyed by path
+	Pinned      []string            // keys listed first in every object, in order
+	Occurrence  string              // value whose occurrences the flattened view lists, "" when it lists every leaf
+	Concealing  bool                // keys matching the conceal patterns are left out of the listing
+	Profile     string              // profile detected from the document, if any
+	Beside      []string            // array element the other elements are shown beside, nil if none
+	Table       *Table              // array of objects shown as a table, nil when it is not shown
+	PagePrompt  bool                // the number of the page to go to is being typed
+	PageInput   string              // number of the page to go to being typed
+	FullPath    bool                // the whole path is shown however deep it is
+	GoingTo     bool                // the path to go to is being typed
+	GoToInput   string              // path to go to being typed, such as .spec.template
+	Tags        []tag               // values tagged to be exported together, in the order they were tagged
+	Tagging     bool                // the name of the value being tagged is being typed
+	TagInput    string              // name of the value being tagged
+	Completions []string            // keys the path or filter being typed can be completed with
+	Stats       *docStats           // counts of what is in the document, nil until they are asked for
+	Changes     []change            // differences from the document before the last reload, nil until reloaded
+	LoadTime    time.Duration       // time taken to load the input
+	Index       []KVPair            // every leaf in the document, nil until indexing is done
+	Source      string              // file the JSON is read from, empty for stdin
+	Git         bool                // Source is a git object such as HEAD~1:config.json
+	Loading     bool                // the input is still being loaded
+	Scalar      bool                // the document is a single value without keys
+	Progress    *loadProgress       // how far loading has got
+	Frame       int                 // frame of the loading spinner
+	Err         error               // error that stopped the input from loading
+	Opening     bool                // the path of a file to open instead is being typed
+	OpenPath    pathInput           // path of the file to open instead
+	Metrics     *Metrics            // performance metrics, nil unless they are shown
+	DupKeys     map[string][]string // keys that appear more than once keyed by the path of their object
+	Relaxed     map[string]string   // values rewritten in lenient mode keyed by their path
+	Sort        string              // order that object keys are listed in
+	Start       []string            // path to open the listing at
+	Keys        KeyMap              // key bindings for every action
+	Schema      any                 // JSON Schema the input is checked against, nil if there is none
+	Violations  []violation         // places where the input does not match the schema
+	Status      string              // result of the last action, cleared by the next key press
+	Palette     *Palette            // plugin palette, nil when it is not shown
+	Transform   *Transform          // string being decoded step by step, nil when it is not shown
+	Exporting   *ExportMenu         // formats to export the value under the cursor in, nil when it is not shown
+	Outline     *Outline            // sidebar listing the top levels of the document, nil when it is not shown
+	Recent      *Recent             // picker of inputs opened before, nil when it is not shown
+	Jumps       []jump              // places that $refs were followed from, the latest last
+	Macro       macro               // key presses recorded to replay
+
+	rowCache      map[int]string     // formatted rows by index, reset when CurrKV changes
+	level         *level             // what the rows of the current level share, nil until it is needed
+	subscribers   []chan string      // control connections told when the selection changes
+	lastSelection string             // selection the subscribers were last told about
+	broadcast     *broadcaster       // hands selection changes to a command or FIFO, nil if there is neither
+	tasks         map[string]*task   // background tasks running by name
+	taskID        int                // id of the task started last
+	cancelLoad    context.CancelFunc // stops loading the input
+	loadStart     time.Time          // when loading the input started, zero if it is handed over
+	loaded        *loadedMsg         // input handed over already parsed, nil if it is loaded from the source
+	sessionKey    string             // hash the session of the input is kept under, empty if it has none
+	spot          *spot              // where to go back to once the input has reloaded, nil if it is not reloading
+	missing       *missingPath       // keys of the path gone to last that are not in the input, nil if it was all there
+	shared        *shared            // what the documents of the workspace the model is in share
+	inferred      map[string]any     // schema inferred from the input to complete keys from, nil until it is needed
+	previous      any                // copy of the document to mark what changed once it reloads, nil unless the input can be reloaded
+	occurrences   []KVPair           // leaves holding the value whose occurrences are listed
+	visited       [][]string         // paths listed in the order they were first visited, only kept when they are traced
+	seen          map[string]bool    // paths visited keyed by path
+	concealCounts map[string]int     // keys concealed of every object listed keyed by path
+	changed       map[string]byte    // how the values changed since the last reload keyed by path, 0 for containers holding changes
+	changeAt      int                // change last gone to
+	found         *searchFound       // leaves the search in progress has found so far, nil if none is running
+	listedQuery   string             // query whose results are listed in the flattened view
+	wrapTop       int                // first row shown while rows are wrapped
+	wrapEnd       int                // row after the last one shown while rows are wrapped
+	wrapLines     []int              // row drawn on each line while rows are wrapped
 }
 
 // NewModel gets the initial model
-func NewModel() *Model {
-	// we will read the JSON from Stdin
-	data, err := readJsonStdin()
-	if err != nil {
-		return nil
-	}
-	kvpairs := getInitialKV(data)
-	// if there are no key-value pairs there is nothing to do
-	if len(kvpairs) == 0 {
-		return nil
-	}
+// the JSON is loaded from the file at path, or from stdin if path is empty,
+// once the program starts
+func NewModel(path string) *Model {
 	c := Cursor{
 		RowNo:         0,     // first row is always 0
 		IsKey:         true,  // first thing the cursor points to is a key
@@ -52,99 +144,557 @@ func NewModel() *Model {
 	// unbind the default key bindings of the paginator
 	p.KeyMap.PrevPage.Unbind()
 	p.KeyMap.NextPage.Unbind()
-	p.SetTotalPages(len(kvpairs))
 	return &Model{
-		Data:   data,
-		CurrC:  c,
-		CurrKV: kvpairs,
-		Path:   []string{}, // path is empty in the beginning
-		Page:   p,
+		CurrC:       c,
+		Path:        []string{}, // path is empty in the beginning
+		Page:        p,
+		KVCache:     map[string][]KVPair{},
+		Cursors:     map[string]Cursor{},
+		Shown:       map[string]int{},
+		Source:      path,
+		Loading:     true,
+		Progress:    &loadProgress{},
+		Sort:        orderSource,
+		Keys:        defaultKeyMap(),
+		shared:      &shared{},
+		Masking:     maskSecrets,
+		LineNumbers: lineNumbers,
+		Wrap:        wrapRows,
+		Pinned:      pinnedKeys,
+		Concealing:  true,
+	}
+}
+
+// NewModelFromJson returns a model showing the given JSON rather than
+// loading it from a path, for bubbletea programs embedding the viewer
+func NewModelFromJson(content []byte) (*Model, error) {
+	data, relaxed, err := parseInput(content)
+	if err != nil {
+		return nil, err
 	}
+	m := NewModel("")
+	m.loaded = &loadedMsg{Data: data, Relaxed: relaxed}
+	return m, nil
+}
+
+// sibling returns a model for another input to open next to this one,
+// with the same key bindings and order
+func (m *Model) sibling(path string, git bool) *Model {
+	s := NewModel(path)
+	s.Git, s.Keys, s.Sort = git, m.Keys, m.Sort
+	return s
 }
 
 // TODO: ask for a path to a file if no stdin data
+// Init starts loading the input
+// a model that is not loading has nothing to start, such as one opened at
+// the recent inputs with -recent
 func (m *Model) Init() tea.Cmd {
-	return nil
+	if !m.Loading {
+		return nil
+	}
+	if m.loaded != nil {
+		loaded := *m.loaded
+		return func() tea.Msg { return loaded }
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelLoad, m.loadStart = cancel, time.Now()
+	return tea.Batch(loadCmd(ctx, m.Source, m.Git, m.Progress), spinnerTick())
 }
 
 // Update updates the model based on tea.KeyMsg
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
+	if m.Metrics != nil {
+		start := time.Now()
+		defer func() {
+			m.Metrics.UpdateTime = time.Since(start)
+		}()
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		debugLog.Printf("key %s in %s mode", msg, m.mode())
+		m.recordKey(msg)
+	}
+	defer m.notifySelection()
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		debugLog.Printf("resized to %d×%d", msg.Width, msg.Height)
+		m.resize(msg.Width, msg.Height)
+		return m, nil
+	}
 	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		m.Page.PerPage = msg.Height - 5
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c":
-			return m, tea.Quit
-		// cursor moving up and down changes the RowNo
-		// this action means we are moving through keys
-		case "up":
-			if m.CurrC.RowNo > 0 {
-				m.CurrC.RowNo--
+	case loadedMsg:
+		return m, m.finishLoad(msg)
+	case spinnerMsg:
+		if m.Loading {
+			m.Frame++
+			return m, spinnerTick()
+		}
+		return m, nil
+	// the indexer keeps running whatever is on screen
+	case indexMsg:
+		m.updateIndex(msg)
+		return m, nil
+	case taskMsg:
+		return m, m.updateTask(msg)
+	case watchMsg:
+		return m, m.updateWatch(msg)
+	case macroMsg:
+		cmd := m.playMacro(msg)
+		m.syncPage()
+		return m, cmd
+	case dupKeysMsg:
+		m.DupKeys = msg.DupKeys
+		m.rowCache = nil
+		m.syncPage()
+		return m, nil
+	case violationsMsg:
+		m.Violations = msg.Violations
+		m.syncPage()
+		return m, nil
+	case clipboardMsg:
+		debugLog.Printf("%s", msg.Status)
+		if m.Detail != nil {
+			m.Detail.Status = msg.Status
+		} else {
+			m.Status = msg.Status
+		}
+		m.syncPage()
+		return m, nil
+	case controlMsg:
+		cmd := m.control(msg)
+		m.syncPage()
+		return m, cmd
+	case subscribeMsg:
+		m.subscribers = append(m.subscribers, msg.Events)
+		return m, nil
+	case unsubscribeMsg:
+		m.unsubscribe(msg.Events)
+		return m, nil
+	case diffMsg:
+		m.finishDiff(msg)
+		m.syncPage()
+		return m, nil
+	case openDocMsg:
+		m.showDoc(msg.Model)
+		m.syncPage()
+		return m, nil
+	case linkMsg:
+		cmd := m.finishLink(msg)
+		m.syncPage()
+		return m, cmd
+	case infoMsg:
+		m.finishInfo(msg)
+		m.syncPage()
+		return m, nil
+	case exportMsg:
+		m.finishExport(msg)
+		m.syncPage()
+		return m, nil
+	case updateMsg:
+		m.finishUpdateCheck(msg)
+		m.syncPage()
+		return m, nil
+	case changesMsg:
+		cmd := m.finishDiffReload(msg)
+		m.syncPage()
+		return m, cmd
+	case summaryMsg:
+		m.clearSummary(msg)
+		m.syncPage()
+		return m, nil
+	case histogramMsg:
+		m.finishHistogram(msg)
+		m.syncPage()
+		return m, nil
+	case occurrencesMsg:
+		m.showOccurrences(msg)
+		return m, nil
+	case occurrenceMsg:
+		m.finishNextKey(msg)
+		return m, nil
+	case refMsg:
+		m.finishRef(msg)
+		m.syncPage()
+		return m, nil
+	case pluginMsg:
+		m.finishPlugin(msg)
+		m.syncPage()
+		return m, nil
+	case pagerDoneMsg:
+		if msg.Err != nil {
+			debugLog.Printf("pager failed: %s", msg.Err)
+			if m.Detail != nil {
+				m.Detail.Status = msg.Err.Error()
+			} else {
+				m.Status = msg.Err.Error()
 			}
-			m.CurrC.IsKey = true
-			m.CurrC.IsEnd = false
-			m.CurrC.CursorDisplay = "→"
-		case "down":
-			if m.CurrC.RowNo < len(m.CurrKV)-1 {
-				m.CurrC.RowNo++
+		}
+		m.syncPage()
+		return m, nil
+	}
+	// the detail pane and the table take all input while they are open,
+	// key presses go to whatever mode the keys are typed in
+	current := m.mode()
+	if current == modeDetail {
+		return m.updateDetail(msg)
+	}
+	if current == modeTable {
+		return m.updateTable(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		return modeUpdates[current](m, msg)
+	}
+	if msg, ok := msg.(tea.MouseMsg); ok && current == modeNormal {
+		m.updateMouse(msg)
+	}
+	if msg, ok := msg.(searchMsg); ok {
+		m.updateSearch(msg)
+	}
+	m.syncPage()
+	m.Page, cmd = m.Page.Update(msg)
+	return m, cmd
+}
+
+// updateNormal handles the keys of the listing
+func (m *Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.Status = ""
+	full := m.FullPath
+	m.FullPath = false
+	// digits count how many times the macro is replayed
+	if m.countDigit(msg) {
+		return m, nil
+	}
+	count := m.Macro.Count
+	m.Macro.Count = ""
+	switch {
+	case key.Matches(msg, m.Keys.Quit):
+		return m, tea.Quit
+	// q starts and stops recording a macro and @ replays it
+	case key.Matches(msg, m.Keys.Record):
+		m.toggleRecording()
+	case key.Matches(msg, m.Keys.Replay):
+		return m, m.replayMacro(count)
+	// p hands the value under the cursor to the pager
+	case key.Matches(msg, m.Keys.Pager):
+		if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+			return m, m.pageNode()
+		}
+	// ctrl+r loads the input again and goes back to the same place in it
+	case key.Matches(msg, m.Keys.Reload):
+		return m, m.reloadInPlace()
+	// O picks an input opened before to open instead
+	case key.Matches(msg, m.Keys.Recent):
+		m.openRecent()
+	// : picks a plugin to run on the value under the cursor
+	case key.Matches(msg, m.Keys.Plugins):
+		if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+			m.openPalette()
+		}
+	// r follows the $ref or link under the cursor and R returns from a $ref
+	case key.Matches(msg, m.Keys.Follow):
+		if len(m.CurrKV) > 0 && !m.Flat && !m.CurrKV[m.CurrC.RowNo].More {
+			cmd := m.follow()
+			m.syncPage()
+			return m, cmd
+		}
+	case key.Matches(msg, m.Keys.Return):
+		if !m.Flat {
+			m.returnFromRef()
+		}
+	// K goes to the spec of the Kubernetes object and then its status
+	case key.Matches(msg, m.Keys.SpecStatus):
+		if !m.Flat {
+			m.jumpSpecStatus()
+		}
+	// d compares a document from git with the working tree
+	case key.Matches(msg, m.Keys.Diff):
+		if !m.Git {
+			m.Status = "only documents loaded with -git can be compared with the working tree"
+			break
+		}
+		m.Status = "comparing with the working tree…"
+		return m, diffWorkingTree(m.Source, m.Data)
+	// v shows the spans of a trace export as a tree
+	case key.Matches(msg, m.Keys.Trace):
+		spans, ok := traceSpans(m.Data)
+		if !ok {
+			m.Status = "not an OTLP or Jaeger trace"
+			break
+		}
+		m.openDetail("Span tree", spanTreeString(spans), "spans.txt")
+	// M plots the bounding boxes of GeoJSON features
+	case key.Matches(msg, m.Keys.Map):
+		features := m.geoFeatures()
+		if features == nil {
+			m.Status = "not a GeoJSON FeatureCollection or Feature"
+			break
+		}
+		m.openDetail("Bounding boxes", plotString(features), "bbox.txt")
+	// B lists the packages of an SBOM as a table
+	case key.Matches(msg, m.Keys.Packages):
+		format := sbomFormat(m.Data)
+		if format == "" {
+			m.Status = "not an SPDX or CycloneDX SBOM"
+			break
+		}
+		packages := sbomPackages(m.Data)
+		m.openDetail(fmt.Sprintf("%d packages in the %s SBOM", len(packages), format),
+			sbomTableString(packages), "packages.txt")
+	// esc stops the background tasks that are shown, such as a search
+	case key.Matches(msg, m.Keys.Cancel):
+		cancelled := m.cancelTasks(false)
+		if len(cancelled) == 0 {
+			break
+		}
+		m.Status = "cancelled " + strings.Join(cancelled, ", ")
+	// c copies the path of the value under the cursor and C the value itself
+	// on the last row of a sliced array c copies the slice
+	case key.Matches(msg, m.Keys.CopyPath):
+		if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+			path := selectorPath(m.Data, m.currentPath())
+			return m, m.copyValue(m.currentPath(), path, "the path "+path)
+		}
+		if _, ok := m.Slices[pathKey(m.Path)]; ok && !m.Flat {
+			path := m.listingSelector()
+			return m, m.copyValue(m.Path, path, "the path "+path)
+		}
+	case key.Matches(msg, m.Keys.CopyValue):
+		if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+			return m, m.copyValue(m.currentPath(), clipboardValue(m.exportValue(m.currentPath())),
+				"the value of "+selectorPath(m.Data, m.currentPath()))
+		}
+	// u copies the value under the cursor as the body of a curl command and
+	// U as the body of an HTTPie one
+	case key.Matches(msg, m.Keys.CopyCurl, m.Keys.CopyHTTPie):
+		if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+			tool := "curl"
+			if key.Matches(msg, m.Keys.CopyHTTPie) {
+				tool = "HTTPie"
 			}
-			m.CurrC.IsKey = true
-			m.CurrC.IsEnd = false
-			m.CurrC.CursorDisplay = "→"
-		// left and right keys moves the cursor from key to value
-		// if the cursor is at the end of a path it can only go left
-		case "right":
-			if m.CurrC.IsKey {
-				// always pointing at a value
-				m.CurrC.IsKey = false
-				// Check if this is an end value
-				if m.CurrKV[m.CurrC.RowNo].Value != "{}" && m.CurrKV[m.CurrC.RowNo].Value != "[]" {
-					m.CurrC.IsEnd = true
-					// update CursorDisplay
-					m.CurrC.CursorDisplay = "←"
-				} else {
-					m.CurrC.IsEnd = false
-					m.CurrC.CursorDisplay = "→"
-				}
+			command, err := requestCommand(tool, m.exportValue(m.currentPath()))
+			if err != nil {
+				m.Status = err.Error()
+				break
 			}
-		case "left":
-			// always pointing at a key
-			m.CurrC.IsKey = true
-			// no longer at the end
-			m.CurrC.IsEnd = false
-			m.CurrC.CursorDisplay = "→"
-
-		// enter expands a {} or [] value which turns into a new list of key-value pairs
-		// enter does nothing if it is at a key or if it is at a value that cannot expand
-		case "enter":
-			if !m.CurrC.IsKey && !m.CurrC.IsEnd {
-				// append the current Key to the Path
-				m.Path = append(m.Path, m.CurrKV[m.CurrC.RowNo].Key)
-				// update the model
-				m.CurrC.IsKey = true
-				m.CurrC.RowNo = 0
-				m.CurrC.IsEnd = false
-				m.CurrC.CursorDisplay = "→"
-				m.updateKV()
-				m.Page.SetTotalPages(len(m.CurrKV))
+			return m, m.copyValue(m.currentPath(), command, fmt.Sprintf("a %s command sending %s", tool, selectorPath(m.Data, m.currentPath())))
+		}
+	// k copies the keys of the level one per line and y the values of it
+	// that are not objects or arrays
+	case key.Matches(msg, m.Keys.CopyKeys):
+		return m, m.copyLevelKeys()
+	case key.Matches(msg, m.Keys.CopyValues):
+		return m, m.copyLevelValues()
+	// Y copies the value under the cursor escaped as a JSON string
+	case key.Matches(msg, m.Keys.CopyEscaped):
+		if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+			escaped, err := escapedValue(m.exportValue(m.currentPath()))
+			if err != nil {
+				m.Status = err.Error()
+				break
 			}
-		// x goes back one key and reloads the previous key-value pairs
-		case "x":
+			return m, m.copyValue(m.currentPath(), escaped, "the value of "+selectorPath(m.Data, m.currentPath())+" as a JSON string")
+		}
+	// cursor moving up and down changes the RowNo
+	// this action means we are moving through keys
+	case key.Matches(msg, m.Keys.Up):
+		if m.CurrC.RowNo > 0 {
+			m.CurrC.RowNo--
+		}
+		m.CurrC.IsKey = true
+		m.CurrC.IsEnd = false
+		m.CurrC.CursorDisplay = "→"
+	case key.Matches(msg, m.Keys.Down):
+		if m.CurrC.RowNo < len(m.CurrKV)-1 {
+			m.CurrC.RowNo++
+		}
+		m.CurrC.IsKey = true
+		m.CurrC.IsEnd = false
+		m.CurrC.CursorDisplay = "→"
+	// left and right keys moves the cursor from key to value
+	// if the cursor is at the end of a path it can only go left
+	case key.Matches(msg, m.Keys.Right):
+		m.pointAtValue()
+	case key.Matches(msg, m.Keys.Left):
+		// always pointing at a key
+		m.CurrC.IsKey = true
+		// no longer at the end
+		m.CurrC.IsEnd = false
+		m.CurrC.CursorDisplay = "→"
+
+	// enter expands a {} or [] value which turns into a new list of key-value pairs
+	// enter does nothing if it is at a key or if it is at a value that cannot expand
+	// unless the value is a string holding a format such as a table
+	// in the flattened view enter goes to the location of the leaf
+	case key.Matches(msg, m.Keys.Expand):
+		m.expand()
+	// back goes back one key and reloads the previous key-value pairs
+	// in the flattened view it goes back to the normal listing
+	case key.Matches(msg, m.Keys.Back):
+		if m.Flat || len(m.Path) == 0 {
+			m.Flat = false
+			m.Filter = ""
+			m.resetCursor()
+			m.updateKV()
+		} else {
+			left := m.Path[len(m.Path)-1]
 			// remove the last selected key and update the current map
-			if len(m.Path) > 0 {
-				m.Path = m.Path[:len(m.Path)-1]
+			m.back()
+			m.restoreCursor(left)
+		}
+		m.syncPage()
+	// D lists values and subtrees that occur at more than one path
+	case key.Matches(msg, m.Keys.Duplicates):
+		m.openDetail("Duplicated values", duplicatesString(m.exportValue(nil)), "duplicates.txt")
+	// o switches the order that object keys are listed in
+	case key.Matches(msg, m.Keys.Sort):
+		if !m.Flat {
+			m.nextOrder()
+		}
+	// b shows the bytes of the value under the cursor as they are in the input
+	case key.Matches(msg, m.Keys.Bytes):
+		if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+			title := fmt.Sprintf("Raw bytes of %s", sanitize(selectorPath(m.Data, m.currentPath())))
+			if n := m.Nodes[0].Find(m.currentPath()); n != nil && n.Offset >= 0 {
+				title += fmt.Sprintf(" at offset %d", n.Offset)
 			}
-			// update the model
-			m.CurrC.IsKey = true
-			m.CurrC.RowNo = 0
-			m.CurrC.IsEnd = false
-			m.CurrC.CursorDisplay = "→"
-			m.updateKV()
-			m.Page.SetTotalPages(len(m.CurrKV))
+			if m.Masking {
+				m.Status = fmt.Sprintf("raw bytes are not shown while secrets are masked, %s shows them", m.Keys.Mask.Help().Key)
+				break
+			}
+			m.openDetail(title, rawBytesString(m.currentNode()), "bytes.txt")
+		}
+	// f switches between the normal listing and the flattened view of every leaf
+	case key.Matches(msg, m.Keys.Flatten):
+		m.Flat = !m.Flat
+		m.Filter = ""
+		m.resetCursor()
+		m.updateKV()
+		m.syncPage()
+	// . starts typing a path to go to
+	case key.Matches(msg, m.Keys.GoTo):
+		m.openGoTo()
+	// / starts typing a filter for the flattened view
+	case key.Matches(msg, m.Keys.Filter):
+		if m.Flat {
+			m.Filtering = true
 		}
+	// S lists random elements of the current array, drawing new ones every time
+	case key.Matches(msg, m.Keys.Sample):
+		m.sampleArray()
+	// [ lists a range of the current array
+	case key.Matches(msg, m.Keys.Slice):
+		m.openSlice()
+	// P goes to a page of the listing by its number
+	case key.Matches(msg, m.Keys.GoToPage):
+		m.openPagePrompt()
+	// e shows the whole path of the listing until the next key press
+	case key.Matches(msg, m.Keys.FullPath):
+		m.FullPath = !full
+	// i shows what the document is made of
+	case key.Matches(msg, m.Keys.Info):
+		return m, m.openInfo()
+	// A shows the version and looks for a newer release if that is on
+	case key.Matches(msg, m.Keys.About):
+		return m, m.openVersion()
+	// ? shows every key binding and what it does
+	case key.Matches(msg, m.Keys.Cheatsheet):
+		m.openDetail("Keys", m.Keys.cheatsheet(), "keys.txt")
+	// a shows the current array of objects as a table
+	case key.Matches(msg, m.Keys.Table):
+		m.openTable()
+	// ] goes to the next value that changed when the input was reloaded
+	case key.Matches(msg, m.Keys.NextChange):
+		m.nextChange()
+	// } lists every value that changed when the input was reloaded
+	case key.Matches(msg, m.Keys.Changes):
+		m.listChanges()
+	// X decodes the string under the cursor step by step
+	case key.Matches(msg, m.Keys.Decode):
+		m.openTransform()
+	// F lists every place the value under the cursor appears
+	case key.Matches(msg, m.Keys.FindValue):
+		return m, m.findOccurrences()
+	// n goes to the next key named like the one under the cursor
+	case key.Matches(msg, m.Keys.NextKey):
+		return m, m.nextKey()
+	// h shows the keys hidden by the conceal patterns or hides them again
+	case key.Matches(msg, m.Keys.Conceal):
+		m.toggleConceal()
+	// ! pins the key under the cursor to the top of every object
+	case key.Matches(msg, m.Keys.Pin):
+		m.togglePin()
+	// % shows how the values of an array, or of a field across one, are spread
+	case key.Matches(msg, m.Keys.Histogram):
+		return m, m.openHistogram()
+	// z shows the timestamp under the cursor in the next time format
+	case key.Matches(msg, m.Keys.TimeFormat):
+		m.cycleTimeFormat()
+	// # shows the index of every row within its container
+	case key.Matches(msg, m.Keys.LineNumbers):
+		m.toggleLineNumbers()
+	// + tags the value under the cursor to be exported along with the others
+	// tagged by ctrl+t
+	case key.Matches(msg, m.Keys.Tag):
+		m.openTag()
+	case key.Matches(msg, m.Keys.ExportTags):
+		m.exportTags()
+	// ctrl+w wraps long rows onto more lines or cuts them short
+	case key.Matches(msg, m.Keys.Wrap):
+		m.toggleWrap()
+	// w writes the listing to a text file and W to an HTML file
+	case key.Matches(msg, m.Keys.ExportText):
+		m.exportListing(false)
+	case key.Matches(msg, m.Keys.ExportHTML):
+		m.exportListing(true)
+	// E writes a copy of the document with its secrets masked
+	case key.Matches(msg, m.Keys.ExportMasked):
+		return m, m.exportMasked()
+	// * masks likely credentials and V reveals the value under the cursor
+	case key.Matches(msg, m.Keys.Mask):
+		m.toggleMasking()
+	case key.Matches(msg, m.Keys.Reveal):
+		m.toggleReveal()
+	// m marks the value under the cursor and = compares another value with it
+	case key.Matches(msg, m.Keys.Mark):
+		m.markSubtree()
+	case key.Matches(msg, m.Keys.Compare):
+		m.compareWithMark()
+	// I shows what the marked value and the value under the cursor share
+	// and what only one of them has
+	case key.Matches(msg, m.Keys.Overlap):
+		m.overlapWithMark()
+	// L shows the outline of the document beside the listing to go to a
+	// key of its top levels
+	case key.Matches(msg, m.Keys.Outline):
+		m.toggleOutline()
+	// | pins the array element under the cursor to show the others beside it
+	case key.Matches(msg, m.Keys.PinElement):
+		m.togglePinElement()
+	// s infers a JSON Schema for the value under the cursor
+	case key.Matches(msg, m.Keys.Schema):
+		if len(m.CurrKV) > 0 {
+			m.openDetail(
+				fmt.Sprintf("Schema of %s", selectorPath(m.Data, m.currentPath())),
+				schemaString(m.currentNode()),
+				"schema.json")
+		}
+	// g generates Go structs for the value under the cursor
+	case key.Matches(msg, m.Keys.Go):
+		m.showExport(goExporter{})
+	// t generates TypeScript interfaces for the value under the cursor
+	// marking keys that only some array elements have as optional
+	// T generates them with every key required
+	case key.Matches(msg, m.Keys.TypeScript, m.Keys.TypeScriptStrict):
+		m.showExport(tsExporter{Strict: key.Matches(msg, m.Keys.TypeScriptStrict)})
+	// G shows the value under the cursor as gron assignment statements
+	case key.Matches(msg, m.Keys.Gron):
+		m.showExport(gronExporter{})
+	// > picks a format to export the value under the cursor in
+	case key.Matches(msg, m.Keys.Export):
+		m.openExportMenu()
 	}
+	m.syncPage()
 	m.Page, cmd = m.Page.Update(msg)
 	return m, cmd
 }
@@ -153,63 +703,561 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m *Model) updateKV() {
 	// remove everything from the current key-value pair list
 	m.CurrKV = nil
-	// if there is nothing in the path just fill the first set of key-value pairs
-	if len(m.Path) == 0 {
-		m.CurrKV = getInitialKV(m.Data)
+	m.rowCache = nil
+	m.level = nil
+	// the flattened view lists every leaf regardless of the path, once
+	// the indexer has found them all
+	if m.Flat && m.Occurrence != "" {
+		m.CurrKV = m.occurrences
+		return
+	}
+	if m.Flat {
+		m.CurrKV = filterKV(m.Index, m.Filter)
+		return
+	}
+	m.Occurrence, m.occurrences = "", nil
+	m.recordVisit()
+	// levels that have been visited before don't need to be walked again
+	key := pathKey(m.Path)
+	if kvpairs, ok := m.KVCache[key]; ok {
+		m.CurrKV = kvpairs
+		return
+	}
+	// the node at the end of the path holds the key-value pairs
+	// large arrays are only loaded a page at a time
+	if indices, ok := m.Samples[key]; ok {
+		m.CurrKV = m.sampleKV(m.node(), indices)
+	} else if isArray(m.node()) {
+		m.CurrKV = m.arrayKV(m.node(), key)
 	} else {
-		// iterate through the Path to get the final key-pair
-		tempMap := getKAny(m.Data)
-		if tempMap != nil {
-			for _, k := range m.Path {
-				o := tempMap[k]      // gets an any object
-				tempMap = getKAny(o) // converts it into a map of string and any
+		m.CurrKV = m.concealKV(getInitialKV(m.node()), m.Path)
+		sortKV(m.CurrKV, m.Sort)
+		pinKV(m.CurrKV, m.Pinned)
+	}
+	m.KVCache[key] = m.CurrKV
+}
 
-			}
-			// we now have a key-value pair which we can fill out
-			for k, v := range tempMap {
-				m.CurrKV = append(m.CurrKV, KVPair{Key: k, Value: getVal(v)})
-			}
+// resetCursor puts the cursor back on the first key
+func (m *Model) resetCursor() {
+	m.CurrC.IsKey = true
+	m.CurrC.RowNo = 0
+	m.CurrC.IsEnd = false
+	m.CurrC.CursorDisplay = "→"
+}
+
+// currentPath returns the full path to the key-value pair under the cursor
+func (m *Model) currentPath() []string {
+	return m.currentPathOf(m.CurrKV[m.CurrC.RowNo])
+}
+
+// currentPathOf returns the full path to a key-value pair in the current listing
+func (m *Model) currentPathOf(kv KVPair) []string {
+	if kv.Path != nil {
+		return kv.Path
+	}
+	return append(append([]string{}, m.Path...), kv.Key)
+}
+
+// currentNode returns the value of the key-value pair under the cursor
+func (m *Model) currentNode() any {
+	kv := m.CurrKV[m.CurrC.RowNo]
+	if kv.Path == nil {
+		return m.children()[kv.Key]
+	}
+	// rows in the flattened view are found from the root
+	return m.valueAt(kv.Path)
+}
+
+// valueAt returns the value at a path from the root
+func (m *Model) valueAt(path []string) any {
+	// the rows of the current level are found from its children, split once
+	if n := len(m.Path); len(path) == n+1 && len(m.Nodes) == n+1 && pathKey(path[:n]) == pathKey(m.Path) {
+		return m.children()[path[n]]
+	}
+	o := m.Data
+	for _, k := range path {
+		o = getKAny(o)[k]
+	}
+	return o
+}
+
+// node returns the value at the end of the current path
+func (m *Model) node() any {
+	return m.Nodes[len(m.Nodes)-1].value
+}
+
+// level holds what the rows of the current level share, worked out once
+// for the level rather than for every row of every frame
+type level struct {
+	children map[string]any // children of the node at the end of the path
+	listKind string         // kind of the List whose items are listed, "" if none
+	sbom     string         // format of the SBOM whose packages are listed, "" if none
+}
+
+// currentLevel returns what the rows of the current level share
+func (m *Model) currentLevel() *level {
+	if m.level == nil {
+		m.level = &level{
+			children: m.Nodes[len(m.Nodes)-1].childValues(),
+			listKind: m.listKind(),
+			sbom:     m.sbomList(),
 		}
 	}
+	return m.level
 }
 
-// getPageItems is a utility function that returns the list of key-value pairs in string form
-func (m *Model) getPageItems() []string {
+// children returns the children of the node at the end of the current path
+func (m *Model) children() map[string]any {
+	return m.currentLevel().children
+}
+
+// enter goes into the value of the given key of the current node
+func (m *Model) enter(key string) {
+	// the child is found before the path grows, what the level shares is
+	// worked out from the path and the nodes together
+	child := m.Nodes[len(m.Nodes)-1].Child(key)
+	if child == nil {
+		child = &Node{Key: key, Parent: m.Nodes[len(m.Nodes)-1], Offset: -1}
+	}
+	m.Path = append(m.Path, key)
+	m.Nodes = append(m.Nodes, child)
+	m.level = nil
+}
+
+// pointAtValue moves the cursor from the key to the value of its row
+func (m *Model) pointAtValue() {
+	if m.CurrC.IsKey && len(m.CurrKV) > 0 {
+		// always pointing at a value
+		m.CurrC.IsKey = false
+		// Check if this is an end value
+		if m.CurrKV[m.CurrC.RowNo].Value != "{}" && m.CurrKV[m.CurrC.RowNo].Value != "[]" {
+			m.CurrC.IsEnd = true
+			// update CursorDisplay
+			m.CurrC.CursorDisplay = "←"
+		} else {
+			m.CurrC.IsEnd = false
+			m.CurrC.CursorDisplay = "→"
+		}
+	}
+}
+
+// expand goes into the value under the cursor, loads more elements or
+// lays out a string, depending on what the cursor is on
+func (m *Model) expand() {
+	if len(m.CurrKV) > 0 && m.CurrKV[m.CurrC.RowNo].More {
+		m.loadMore()
+	} else if m.Flat {
+		if len(m.CurrKV) > 0 {
+			m.jumpToLeaf()
+		}
+	} else if !m.CurrC.IsKey && !m.CurrC.IsEnd && !tooDeep(m.currentPath()) {
+		// go into the value of the current Key, remembering where the
+		// cursor was to come back to it
+		m.Cursors[pathKey(m.Path)] = m.CurrC
+		m.enter(m.CurrKV[m.CurrC.RowNo].Key)
+		// update the model
+		m.resetCursor()
+		m.updateKV()
+		m.syncPage()
+	} else if m.CurrC.IsEnd {
+		m.openEmbedded()
+	}
+}
+
+// back goes back to the parent of the current node
+func (m *Model) back() {
+	if len(m.Path) > 0 {
+		m.Path = m.Path[:len(m.Path)-1]
+		m.Nodes = m.Nodes[:len(m.Nodes)-1]
+		m.level = nil
+	}
+}
+
+// restoreCursor puts the cursor back where it was on the level gone back
+// to, so the same row and page are shown as before going in
+// the cursor goes to the row of the key that was left when the level has
+// changed since, or was gone into some other way such as a search
+func (m *Model) restoreCursor(left string) {
+	m.updateKV()
+	c, ok := m.Cursors[pathKey(m.Path)]
+	if ok && c.RowNo < len(m.CurrKV) && m.CurrKV[c.RowNo].Key == left && !m.CurrKV[c.RowNo].More {
+		m.CurrC = c
+		return
+	}
+	m.resetCursor()
+	for i, kv := range m.CurrKV {
+		if kv.Key == left && !kv.More {
+			m.CurrC.RowNo = i
+			return
+		}
+	}
+}
+
+// setPath goes to the node at the given path starting from the root
+func (m *Model) setPath(path []string) {
+	m.Path = []string{}
+	m.Nodes = []*Node{NewNode(m.Data)}
+	m.level = nil
+	for _, k := range path {
+		m.enter(k)
+	}
+}
+
+// showKey opens the level holding the value at path with the cursor on it
+// array elements past the ones loaded so far get loaded
+func (m *Model) showKey(path []string) {
+	parent, last := path[:len(path)-1], path[len(path)-1]
+	m.setPath(parent)
+	if i, err := strconv.Atoi(last); err == nil && isArray(m.node()) {
+		key := pathKey(parent)
+		if shown, ok := m.Shown[key]; i >= shown || (!ok && i >= arrayPageSize) {
+			m.Shown[key] = (i/arrayPageSize + 1) * arrayPageSize
+			delete(m.KVCache, key)
+		}
+	}
+	m.resetCursor()
+	m.updateKV()
+	for i, kv := range m.CurrKV {
+		if kv.Key == last && !kv.More {
+			m.CurrC.RowNo = i
+		}
+	}
+	m.syncPage()
+}
+
+// goTo opens the level at path if it is an object or array, or the level
+// holding it if it is not
+func (m *Model) goTo(path []string) {
+	if len(path) > 0 && getKAny(m.valueAt(path)) == nil {
+		m.showKey(path)
+		return
+	}
+	m.setPath(path)
+	m.resetCursor()
+	m.updateKV()
+	m.syncPage()
+}
+
+// checkPath checks that a path leads to a value in the data
+func (m *Model) checkPath(path []string) error {
+	o := m.Data
+	for i, k := range path {
+		children := getKAny(o)
+		child, ok := children[k]
+		if !ok {
+			return fmt.Errorf("cannot find %s in the input", selectorPath(m.Data, path[:i+1]))
+		}
+		o = child
+	}
+	return nil
+}
+
+// getPageItems is a utility function that returns the key-value pairs
+// between start and end in string form
+// only the rows that are visible get formatted and rows without the cursor
+// are remembered until the list of key-value pairs changes
+func (m *Model) getPageItems(start, end int) []string {
+	if m.rowCache == nil {
+		m.rowCache = map[int]string{}
+	}
 	items := []string{}
-	for index, kv := range m.CurrKV {
+	rendered := 0
+	// the index of every row is shown in a gutter outside the cached rows
+	gutter, width := "", 0
+	if m.LineNumbers {
+		width = m.gutterWidth(start, end)
+	}
+	base, beside := m.besideBase()
+	for index := start; index < end; index++ {
+		kv := m.CurrKV[index]
+		if m.LineNumbers {
+			gutter = m.gutter(index, width)
+		}
+		// rows without the cursor are only formatted once
+		if row, ok := m.rowCache[index]; ok && !beside && m.CurrC.RowNo != index {
+			items = append(items, gutter+row)
+			continue
+		}
+		path := m.currentPathOf(kv)
+		// the value is found by the key as it is in the data
+		value := m.displayValue(kv)
+		kv.Key = m.displayKey(kv)
+		kv.Value = value
+		// the pinned element is shown in a second column, left out of the cache
+		if beside {
+			rendered++
+			column := (m.Width - utf8.RuneCountInString(unstyle(gutter))) / 2
+			items = append(items, gutter+m.besideRow(kv, path, m.CurrC.RowNo == index, base, column))
+			continue
+		}
 		if m.CurrC.RowNo == index {
+			rendered++
 			if m.CurrC.IsKey {
-				items = append(items, fmt.Sprintf("%s %s: %s", m.CurrC.CursorDisplay, kv.Key, kv.Value))
+				items = append(items, gutter+style(fmt.Sprintf("%s %s: %s", m.CurrC.CursorDisplay, kv.Key, kv.Value), styleBold))
 			} else {
-				items = append(items, fmt.Sprintf("%s: %s %s", kv.Key, m.CurrC.CursorDisplay, kv.Value))
+				items = append(items, gutter+style(fmt.Sprintf("%s: %s %s", kv.Key, m.CurrC.CursorDisplay, kv.Value), styleBold))
 			}
-		} else {
-			items = append(items, fmt.Sprintf("%s: %s", kv.Key, kv.Value))
+			continue
 		}
+		row := fmt.Sprintf("%s: %s", style(kv.Key, styleKey), kv.Value)
+		m.rowCache[index] = row
+		rendered++
+		items = append(items, gutter+row)
+	}
+	if m.Metrics != nil {
+		m.Metrics.RowsRendered = rendered
 	}
 	return items
 }
 
+// displayKey returns the key of a row as it is shown
+// array indices are shown in brackets and object keys that look like
+// indices are quoted so the two can be told apart
+// duplicated keys are flagged since only their last value is shown
+func (m *Model) displayKey(kv KVPair) string {
+	if kv.Path != nil || kv.More {
+		return sanitize(kv.Key)
+	}
+	key := sanitize(kv.Key)
+	if kv.Index {
+		key = "[" + key + "]"
+	} else if _, err := strconv.Atoi(key); err == nil {
+		key = strconv.Quote(key)
+	}
+	if m.isDupKey(kv.Key) {
+		key = "⚠ " + key
+	}
+	if mark := m.changeMark(m.currentPathOf(kv)); mark != "" {
+		key = mark + " " + key
+	}
+	return truncate(key, limits.KeyLen)
+}
+
+// displayValue returns the value of a row as it is shown
+// huge values are cut short and containers past the nesting limit
+// are marked since they cannot be expanded
+func (m *Model) displayValue(kv KVPair) string {
+	if !kv.More && m.maskedAt(m.currentPathOf(kv)) {
+		return style(secretMask, styleFaint) + m.tagNote(m.currentPathOf(kv))
+	}
+	if !kv.More && (kv.Value == "{}" || kv.Value == "[]") && tooDeep(m.currentPathOf(kv)) {
+		return fmt.Sprintf("%s (nested deeper than %d levels)", kv.Value, limits.Depth)
+	}
+	value := kv.Value
+	// UUIDs and digests are shortened but on the row under the cursor
+	if !kv.More && len(m.CurrKV) > 0 && pathKey(m.currentPathOf(kv)) != pathKey(m.currentPath()) {
+		if s, ok := materializeScalar(m.valueAt(m.currentPathOf(kv))).(string); ok {
+			if short, ok := shortID(s); ok {
+				value = short
+			}
+		}
+	}
+	// Kubernetes objects, GeoJSON features and SBOM packages are summed
+	// up in one line
+	if summary, ok := m.k8sSummary(kv); ok {
+		value = summary
+	} else if summary, ok := m.geoSummary(kv); ok {
+		value = summary
+	} else if summary, ok := m.sbomSummary(kv); ok {
+		value = summary
+	} else if previewDepth > 0 && !kv.More && (value == "{}" || value == "[]") {
+		var secret func(string, any) bool
+		if m.masked(m.currentPathOf(kv)) {
+			secret = func(k string, o any) bool { return isSecret(k, materializeScalar(o)) }
+		}
+		value = shortPreview(preview(m.valueAt(m.currentPathOf(kv)), previewDepth, secret))
+	}
+	value = truncate(sanitize(value), limits.ValueLen)
+	// strings and numbers of a known kind get a readable form alongside
+	if !kv.More {
+		if o := m.valueAt(m.currentPathOf(kv)); getKAny(o) == nil {
+			s, ok := formatKeyValue(formatKey(m.currentPathOf(kv), kv.Index), materialize(o))
+			if !ok {
+				s = renderValue(materialize(o))
+			}
+			// timestamps can be shown in another format than the others
+			if format, ok := m.TimeFormats[pathKey(m.currentPathOf(kv))]; ok && renderValues {
+				if t, ok := parseTimestamp(materialize(o)); ok {
+					s = formatTimestamp(t, format)
+				}
+			}
+			if s != "" {
+				value += "  " + style("("+s+")", styleFaint)
+			}
+		}
+	}
+	if !kv.More {
+		value += m.tagNote(m.currentPathOf(kv))
+	}
+	if note := m.relaxedNote(m.currentPathOf(kv)); note != "" {
+		value += fmt.Sprintf(" (lenient: %s)", note)
+	}
+	return value
+}
+
+// emptyPlaceholder says why the listing has no rows
+func (m *Model) emptyPlaceholder() string {
+	switch {
+	case m.Flat && m.Filter != "":
+		if _, ok := m.taskProgress("search"); ok {
+			return "(searching…)"
+		}
+		return "(no leaves match the filter)"
+	case m.Flat && m.Index == nil && isStreamed(m.Data):
+		return "(streamed input is not indexed, type a filter to search it)"
+	case m.Flat && m.Index == nil:
+		return "(indexing…)"
+	case m.Flat:
+		return "(no leaves)"
+	case isArray(m.node()):
+		return "(empty array)"
+	}
+	return "(empty object)"
+}
+
+// View renders the model, spelling out its glyphs in accessible mode
 func (m *Model) View() string {
-	s := "You are here: "
-	if len(m.Path) > 0 {
-		for _, p := range m.Path {
-			s += fmt.Sprintf("%s: ", p)
+	if accessible {
+		return plainView(m.view())
+	}
+	return m.view()
+}
+
+// view renders whatever is open, the listing otherwise
+func (m *Model) view() string {
+	if m.Loading {
+		return m.viewLoading()
+	}
+	if m.Err != nil {
+		return m.viewError()
+	}
+	if m.tooSmall() {
+		return m.viewTooSmall()
+	}
+	if m.Recent != nil {
+		return m.viewRecent()
+	}
+	if m.Scalar {
+		return m.viewScalar()
+	}
+	if m.Detail != nil {
+		return m.viewDetail()
+	}
+	if m.Table != nil {
+		return m.viewTable()
+	}
+	if m.Palette != nil {
+		return m.viewPalette()
+	}
+	if m.Transform != nil {
+		return m.viewTransform()
+	}
+	if m.Exporting != nil {
+		return m.viewExportMenu()
+	}
+	s := ""
+	if m.Metrics != nil {
+		s += m.viewMetrics()
+	}
+	// notes shown after the header, along with how wide they are without styling
+	after, afterWidth := "", 0
+	if m.Masking {
+		after += "  (secrets masked)"
+		afterWidth += utf8.RuneCountInString("  (secrets masked)")
+	}
+	if m.Macro.Recording {
+		note := fmt.Sprintf("● recording (%d keys)", len(m.Macro.recording))
+		after += "  " + style(note, styleWarn)
+		afterWidth += utf8.RuneCountInString(note) + 2
+	}
+	if m.Flat && m.Occurrence != "" {
+		s += fmt.Sprintf("Occurrences of %s  (%d)", sanitize(m.Occurrence), len(m.CurrKV))
+	} else if m.Flat {
+		s += fmt.Sprintf("Flattened leaves  Filter: %s", m.Filter)
+		if m.Filtering {
+			s += "_"
+		}
+		if n, ok := m.taskProgress("index"); ok {
+			s += fmt.Sprintf("  (indexing… %d leaves)", n)
+		}
+	} else {
+		notes := ""
+		if m.Slicing {
+			notes += fmt.Sprintf("  Slice: %s_", m.SliceInput)
+		}
+		if m.GoingTo {
+			notes += fmt.Sprintf("  Go to: %s_", sanitize(m.GoToInput))
 		}
+		if m.Tagging {
+			notes += fmt.Sprintf("  Tag as: %s_", sanitize(m.TagInput))
+		}
+		if m.Sort != orderSource {
+			notes += fmt.Sprintf("  (sorted by %s)", m.Sort)
+		}
+		if jqFilter != "" {
+			notes += fmt.Sprintf("  (jq %s)", sanitize(jqFilter))
+		}
+		notes += m.concealNote()
+		notes += m.besideNote()
+		notes += m.missingNote()
+		// deep paths are cut short so that the header stays on one line
+		width := m.Width - utf8.RuneCountInString("You are here: "+notes) - afterWidth
+		s += "You are here: " + style(m.breadcrumb(width), styleBold) + notes
+	}
+	s += after
+	// the keys the path being typed can be completed with
+	if len(m.Completions) > 0 {
+		s += "\n" + style(truncate(sanitize(strings.Join(m.Completions, "  ")), m.Width), styleFaint)
+	}
+	switch n := m.dupKeyCount(); {
+	case n == 1:
+		s += "\n" + style("⚠ 1 duplicate key in the input, only its last value is shown", styleWarn)
+	case n > 1:
+		s += "\n" + style(fmt.Sprintf("⚠ %d duplicate keys in the input, only the last value of each is shown", n), styleWarn)
+	}
+	if n := len(m.Relaxed); n > 0 {
+		s += "\n" + style(fmt.Sprintf("≈ %d values in the input are not strict JSON", n), styleWarn)
+	}
+	if n := len(m.Violations); n > 0 {
+		first := m.Violations[0]
+		s += "\n" + style(truncate(sanitize(fmt.Sprintf("✗ %d schema violations, first at %s: %s",
+			n, selectorPath(m.Data, first.Path), first.Message)), m.Width), styleWarn)
+	}
+	if m.Status != "" {
+		s += "\n" + truncate(sanitize(m.Status), m.Width)
+	}
+	if line := m.tasksLine(); line != "" {
+		s += "\n" + truncate(sanitize(line), m.Width)
+	}
+	// screen readers read the row under the cursor from a line of its own
+	if accessible {
+		s += "\n" + truncate(m.announcement(), m.Width)
 	}
 	s += "\n\n"
-	items := m.getPageItems()
-	start, end := m.Page.GetSliceBounds(len(items))
-	if m.CurrC.RowNo < start {
-		m.Page.PrevPage()
+	start, end := m.Page.GetSliceBounds(len(m.CurrKV))
+	var rows []string
+	if m.wrapping() {
+		rows = m.wrappedPage()
+	} else {
+		rows = m.getPageItems(start, end)
+	}
+	if len(m.CurrKV) == 0 {
+		rows = []string{m.emptyPlaceholder()}
+	}
+	if m.Outline != nil {
+		rows = m.withOutline(rows)
 	}
-	if m.CurrC.RowNo > end {
-		m.Page.NextPage()
+	var b strings.Builder
+	for _, row := range rows {
+		b.WriteString(row)
+		b.WriteString("\n")
 	}
-	for _, item := range items[start:end] {
-		s += fmt.Sprintf("%s\n", item)
+	s += b.String()
+	s += m.pageIndicator()
+	help := m.Keys.listingHelp()
+	if m.Outline != nil && m.Outline.Focused {
+		help = m.Keys.outlineHelp()
 	}
-	s += m.Page.View()
-	s += "\n\nQuit: ctrl+c  Up: ↑  Down: ↓  Left: ←  Right: →  Expand: enter  Back: x \n"
+	s += "\n\n" + style(help, styleFaint) + "\n"
 	return s
 }
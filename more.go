@@ -0,0 +1,68 @@
+package jv
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// arrayPageSize is the number of array elements loaded at a time
+var arrayPageSize = 1000
+
+// arrayKV returns the key-value pairs of an array at the given path key in order,
+// within its slice if it has one,
+// only going as far as the number of elements loaded so far
+// a pseudo-row is added at the end if there are more elements to load
+func (m *Model) arrayKV(o any, key string) []KVPair {
+	children := getKAny(o)
+	start, end := 0, len(children)
+	if r, ok := m.Slices[key]; ok {
+		start, end = r[0], r[1]
+	}
+	shown, ok := m.Shown[key]
+	if !ok {
+		shown = arrayPageSize
+	}
+	kvpairs := []KVPair{}
+	for i := start; i < end && i < start+shown; i++ {
+		k := strconv.Itoa(i)
+		kvpairs = append(kvpairs, KVPair{Key: k, Value: getVal(children[k]), Index: true})
+	}
+	if remaining := end - start - shown; remaining > 0 {
+		next := arrayPageSize
+		if remaining < next {
+			next = remaining
+		}
+		kvpairs = append(kvpairs, KVPair{
+			Key:   "…",
+			Value: fmt.Sprintf("load next %d of %d remaining", next, remaining),
+			More:  true,
+		})
+	}
+	return kvpairs
+}
+
+// loadMore loads the next page of elements of the current array
+// keeping the cursor on the first newly loaded element
+// a sampled array is listed in order again instead
+func (m *Model) loadMore() {
+	key := pathKey(m.Path)
+	if _, ok := m.Samples[key]; ok {
+		delete(m.Samples, key)
+		delete(m.KVCache, key)
+		m.resetCursor()
+		m.updateKV()
+		m.syncPage()
+		return
+	}
+	shown, ok := m.Shown[key]
+	if !ok {
+		shown = arrayPageSize
+	}
+	m.Shown[key] = shown + arrayPageSize
+	delete(m.KVCache, key)
+	row := m.CurrC.RowNo
+	m.resetCursor()
+	m.updateKV()
+	m.syncPage()
+	m.CurrC.RowNo = row
+}
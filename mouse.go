@@ -0,0 +1,88 @@
+package jv
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// what clicking a row of the listing does
+const (
+	clickSelect = "select" // moves the cursor onto the row
+	clickExpand = "expand" // moves the cursor onto the row and expands its value
+)
+
+// mouseEnabled takes mouse input, which keeps the terminal from selecting
+// text, it can be turned off from the command line
+var mouseEnabled = true
+
+// mouseClick is what clicking a row does, select or expand
+var mouseClick = clickSelect
+
+// wheelRows is how many rows a turn of the mouse wheel moves
+var wheelRows = 1
+
+// checkMouseClick is a utility function that checks if clicking a row can
+// do what is asked
+func checkMouseClick(click string) error {
+	if click != clickSelect && click != clickExpand {
+		return fmt.Errorf("unknown mouse click %q, expected select or expand", click)
+	}
+	return nil
+}
+
+// updateMouse handles the mouse in the listing
+// the wheel moves the cursor and a click moves it onto the row clicked
+func (m *Model) updateMouse(msg tea.MouseMsg) {
+	if msg.Action != tea.MouseActionPress || len(m.CurrKV) == 0 {
+		return
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.moveRow(-wheelRows)
+	case tea.MouseButtonWheelDown:
+		m.moveRow(wheelRows)
+	case tea.MouseButtonLeft:
+		row, ok := m.rowAt(msg.Y)
+		if !ok {
+			return
+		}
+		m.moveRow(row - m.CurrC.RowNo)
+		if mouseClick == clickExpand {
+			m.pointAtValue()
+			m.expand()
+		}
+	}
+}
+
+// moveRow moves the cursor n rows down, or up if n is negative, onto the
+// key of the row it stops at
+func (m *Model) moveRow(n int) {
+	row := m.CurrC.RowNo + n
+	if row > len(m.CurrKV)-1 {
+		row = len(m.CurrKV) - 1
+	}
+	if row < 0 {
+		row = 0
+	}
+	m.CurrC = Cursor{RowNo: row, IsKey: true, CursorDisplay: "→"}
+}
+
+// rowAt returns the row of the listing drawn on line y of the screen
+// the rows come after the header lines and before the paginator and the
+// help line
+func (m *Model) rowAt(y int) (int, bool) {
+	if m.wrapping() {
+		line := y - (m.chromeHeight() - 3)
+		if line < 0 || line >= len(m.wrapLines) {
+			return 0, false
+		}
+		return m.wrapLines[line], true
+	}
+	start, end := m.Page.GetSliceBounds(len(m.CurrKV))
+	row := start + y - (m.chromeHeight() - 3)
+	if row < start || row >= end {
+		return 0, false
+	}
+	return row, true
+}
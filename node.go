@@ -0,0 +1,177 @@
+package jv
+
+import "encoding/json"
+
+// NodeKind is the kind of JSON value a node holds
+type NodeKind byte
+
+// kinds of nodes
+const (
+	KindNull NodeKind = iota
+	KindBool
+	KindNumber
+	KindString
+	KindObject
+	KindArray
+)
+
+// String names the kind of node
+func (k NodeKind) String() string {
+	return [...]string{"null", "boolean", "number", "string", "object", "array"}[k]
+}
+
+// Node is a value in the document along with where it sits in it
+// the listing goes through the document node by node, so a value is only
+// parsed as far as it is explored and its children are remembered once
+// they have been split
+type Node struct {
+	Key    string // key or index of the node in its parent, empty for the root
+	Parent *Node  // nil for the root
+	Offset int64  // offset of the value in the input in bytes, -1 if it is not known
+
+	value    any              // the raw, streamed or parsed value
+	children []*Node          // nil until they are first asked for
+	byKey    map[string]*Node // children by their key, the last one of keys given twice
+}
+
+// NewNode returns the root node of loaded data
+func NewNode(data any) *Node {
+	n := &Node{value: data, Offset: -1}
+	switch v := data.(type) {
+	case json.RawMessage:
+		n.Offset = int64(skipSpace(v, 0))
+	case fileSpan:
+		n.Offset = v.off
+	}
+	return n
+}
+
+// Kind returns the kind of value the node holds
+func (n *Node) Kind() NodeKind {
+	switch v := n.value.(type) {
+	case fileSpan:
+		if v.kind == '[' {
+			return KindArray
+		}
+		return KindObject
+	case json.RawMessage:
+		switch rawKind(v) {
+		case '{':
+			return KindObject
+		case '[':
+			return KindArray
+		case '"':
+			return KindString
+		case 't', 'f':
+			return KindBool
+		case 'n':
+			return KindNull
+		}
+		return KindNumber
+	case map[string]any:
+		return KindObject
+	case []any:
+		return KindArray
+	case string:
+		return KindString
+	case bool:
+		return KindBool
+	case nil:
+		return KindNull
+	}
+	return KindNumber
+}
+
+// Children returns the children of an object or array in the order they
+// appear in the input, or nil if the node has none
+func (n *Node) Children() []*Node {
+	if n.children != nil {
+		return n.children
+	}
+	keys, values := orderedChildren(n.value)
+	if keys == nil {
+		return nil
+	}
+	n.children = make([]*Node, len(keys))
+	n.byKey = make(map[string]*Node, len(keys))
+	parent, _ := n.value.(json.RawMessage)
+	for i, k := range keys {
+		child := &Node{Key: k, Parent: n, Offset: -1, value: values[k]}
+		switch v := child.value.(type) {
+		case fileSpan:
+			child.Offset = v.off
+		case json.RawMessage:
+			// the children of raw values are slices of it so how far into
+			// it they start is how much less room they have after them
+			if parent != nil && n.Offset >= 0 {
+				child.Offset = n.Offset - int64(skipSpace(parent, 0)) + int64(cap(parent)-cap(v))
+			}
+		}
+		n.children[i] = child
+		n.byKey[k] = child
+	}
+	return n.children
+}
+
+// Child returns the child with the given key, or nil if there is none
+func (n *Node) Child(key string) *Node {
+	n.Children()
+	return n.byKey[key]
+}
+
+// childValues returns the values of the children by their key, nil if the
+// node is not an object or array
+func (n *Node) childValues() map[string]any {
+	kids := n.Children()
+	if kids == nil {
+		// empty objects and arrays still have children, none of them
+		return getKAny(n.value)
+	}
+	values := make(map[string]any, len(kids))
+	for _, c := range kids {
+		values[c.Key] = c.value
+	}
+	return values
+}
+
+// Find returns the node at path below this one, or nil if there is none
+func (n *Node) Find(path []string) *Node {
+	for _, k := range path {
+		if n = n.Child(k); n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// Path returns the keys on the way from the root to the node
+func (n *Node) Path() []string {
+	if n.Parent == nil {
+		return []string{}
+	}
+	return append(n.Parent.Path(), n.Key)
+}
+
+// Root returns the root of the document the node is in
+func (n *Node) Root() *Node {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return n
+}
+
+// Selector returns the path to the node as a selector such as .a[0]
+func (n *Node) Selector() string {
+	return selectorPath(n.Root().value, n.Path())
+}
+
+// Value returns the value of the node fully parsed into maps, slices
+// and scalars
+func (n *Node) Value() any {
+	return materialize(n.value)
+}
+
+// Raw returns the bytes of the value as they are in the input
+func (n *Node) Raw() []byte {
+	return rawBytes(n.value)
+}
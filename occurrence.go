@@ -0,0 +1,89 @@
+package jv
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// occurrenceMsg carries where the next key with the same name was found
+type occurrenceMsg struct {
+	Name string
+	Path []string // nil if the key appears nowhere else
+	From []string // path the search started from
+}
+
+// nextOccurrence is a utility function that finds the next key named name
+// after the path from, in the order of the input and at any depth, starting
+// over from the top once the end is reached
+// it returns nil if the key appears nowhere else or once ctx is cancelled
+func nextOccurrence(ctx context.Context, data any, from []string, name string) []string {
+	var first []string
+	passed := false
+	var walk func(o any, path []string) []string
+	walk = func(o any, path []string) []string {
+		if ctx.Err() != nil {
+			return nil
+		}
+		keys, values := orderedChildren(o)
+		arr := isArray(o)
+		for _, k := range keys {
+			child := append(append([]string{}, path...), k)
+			if !arr && k == name {
+				switch {
+				case passed:
+					return child
+				case first == nil && pathKey(child) != pathKey(from):
+					first = child
+				}
+			}
+			if pathKey(child) == pathKey(from) {
+				passed = true
+			}
+			if found := walk(values[k], child); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	if found := walk(data, nil); found != nil || ctx.Err() != nil {
+		return found
+	}
+	return first
+}
+
+// nextKey looks for the next key named like the one under the cursor in
+// the background, across array elements and nesting levels
+func (m *Model) nextKey() tea.Cmd {
+	if m.Flat || len(m.CurrKV) == 0 {
+		return nil
+	}
+	kv := m.CurrKV[m.CurrC.RowNo]
+	if kv.More || kv.Index {
+		m.Status = "cannot look for other occurrences of an array index"
+		return nil
+	}
+	data, from, name := m.Data, m.currentPath(), kv.Key
+	return m.startTask("occurrence", fmt.Sprintf("looking for %s", sanitize(name)), func(ctx context.Context, _ func(int)) tea.Msg {
+		path := nextOccurrence(ctx, data, from, name)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return occurrenceMsg{Name: name, Path: path, From: from}
+	})
+}
+
+// finishNextKey shows the next occurrence of the key, if the cursor has not
+// moved away from the key it was looked for from in the meantime
+func (m *Model) finishNextKey(msg occurrenceMsg) {
+	if m.Flat || len(m.CurrKV) == 0 || pathKey(m.currentPath()) != pathKey(msg.From) {
+		return
+	}
+	if msg.Path == nil {
+		m.Status = fmt.Sprintf("%s appears nowhere else", msg.Name)
+		return
+	}
+	m.showKey(msg.Path)
+	m.Status = fmt.Sprintf("%s at %s", msg.Name, selectorPath(m.Data, msg.Path))
+}
@@ -0,0 +1,141 @@
+package jv
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// openAPIMethods are the operations a path item of an OpenAPI spec can have
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// loadOpenAPI is a utility function that reads an OpenAPI spec in JSON or
+// YAML and returns the schema of the JSON response of the operation with
+// the given operationId, to check the input against like a -schema
+// the first success response is taken, or the default one if there is none
+func loadOpenAPI(path, operation string) (any, error) {
+	content, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read OpenAPI spec: %w", err)
+	}
+	var spec any
+	if filepath.Ext(path) == ".json" || bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		spec, err = decodeJson(content)
+	} else {
+		spec, err = parseYaml(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read OpenAPI spec %s: %w", path, err)
+	}
+	root, ok := spec.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot read OpenAPI spec %s: expected an object", path)
+	}
+	op, where, err := findOperation(root, operation)
+	if err != nil {
+		return nil, fmt.Errorf("cannot use OpenAPI spec %s: %w", path, err)
+	}
+	schema, err := responseSchema(root, op)
+	if err != nil {
+		return nil, fmt.Errorf("cannot use %s in OpenAPI spec %s: %w", where, path, err)
+	}
+	debugLog.Printf("checking against the response schema of %s in %s", where, path)
+	// the $refs of the schema point into the spec so the components are
+	// carried along for them, the validator ignores keys it does not know
+	s := map[string]any{}
+	for k, v := range schema {
+		s[k] = v
+	}
+	for _, k := range []string{"components", "definitions"} {
+		if _, ok := s[k]; !ok && root[k] != nil {
+			s[k] = root[k]
+		}
+	}
+	return s, nil
+}
+
+// findOperation is a utility function that finds the operation with the
+// given operationId in a spec, along with its method and path
+func findOperation(spec map[string]any, operation string) (map[string]any, string, error) {
+	paths, _ := spec["paths"].(map[string]any)
+	names := []string{}
+	for p := range paths {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	ids := []string{}
+	for _, p := range names {
+		item, _ := paths[p].(map[string]any)
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			id, _ := op["operationId"].(string)
+			if id == operation {
+				return op, fmt.Sprintf("%s %s", strings.ToUpper(method), p), nil
+			}
+			if id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, "", fmt.Errorf("no operation %s, the spec has no operationIds", operation)
+	}
+	sort.Strings(ids)
+	return nil, "", fmt.Errorf("no operation %s, expected one of %s", operation, strings.Join(ids, ", "))
+}
+
+// responseSchema is a utility function that finds the schema of the JSON
+// response of an operation, from its content in OpenAPI 3 or its schema in
+// Swagger 2
+func responseSchema(spec map[string]any, op map[string]any) (map[string]any, error) {
+	responses, _ := op["responses"].(map[string]any)
+	codes := []string{}
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	codes = append(codes, "default")
+	for _, code := range codes {
+		response, ok := responses[code].(map[string]any)
+		if !ok {
+			continue
+		}
+		// shared responses are referred to from components
+		if ref, ok := response["$ref"].(string); ok {
+			target, err := resolveRef(spec, ref)
+			if err != nil {
+				return nil, err
+			}
+			if response, ok = target.(map[string]any); !ok {
+				return nil, fmt.Errorf("%s is not a response", ref)
+			}
+		}
+		if schema, ok := response["schema"].(map[string]any); ok {
+			return schema, nil
+		}
+		content, _ := response["content"].(map[string]any)
+		// application/json is taken over the other JSON media types
+		types := []string{}
+		for t := range content {
+			if t != "application/json" && strings.Contains(t, "json") {
+				types = append(types, t)
+			}
+		}
+		sort.Strings(types)
+		for _, t := range append([]string{"application/json"}, types...) {
+			media, _ := content[t].(map[string]any)
+			if schema, ok := media["schema"].(map[string]any); ok {
+				return schema, nil
+			}
+		}
+		return nil, fmt.Errorf("the %s response has no JSON schema", code)
+	}
+	return nil, fmt.Errorf("no success or default response")
+}
@@ -0,0 +1,168 @@
+package jv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// orders that object keys can be listed in
+const (
+	orderSource  = "source"  // keys in the order they are in the input
+	orderNatural = "natural" // keys sorted with the numbers in them compared by value
+	orderKey     = "key"     // keys sorted by their bytes
+	orderCase    = "case"    // keys sorted alphabetically ignoring case
+)
+
+// orders lists the orders in the order they are cycled through
+var orders = []string{orderSource, orderNatural, orderKey, orderCase}
+
+// checkOrder is a utility function that checks if an order is known
+func checkOrder(order string) error {
+	for _, o := range orders {
+		if o == order {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown sort order %q, expected one of %v", order, orders)
+}
+
+// sortKV is a utility function that puts the key-value pairs of an object
+// in the given order
+// they come in the order of the input so nothing needs doing for that
+func sortKV(kvpairs []KVPair, order string) {
+	var less func(a, b string) bool
+	switch order {
+	case orderNatural:
+		less = naturalLess
+	case orderKey:
+		less = func(a, b string) bool { return a < b }
+	case orderCase:
+		less = func(a, b string) bool {
+			if la, lb := strings.ToLower(a), strings.ToLower(b); la != lb {
+				return la < lb
+			}
+			return a < b
+		}
+	default:
+		return
+	}
+	sort.SliceStable(kvpairs, func(i, j int) bool {
+		return less(kvpairs[i].Key, kvpairs[j].Key)
+	})
+}
+
+// naturalLess is a utility function that compares two keys with the runs
+// of digits in them compared by value, so item2 comes before item10
+// keys that only differ in leading zeros are told apart by their bytes
+func naturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			ei, ej := digitsEnd(a, i), digitsEnd(b, j)
+			na, nb := strings.TrimLeft(a[i:ei], "0"), strings.TrimLeft(b[j:ej], "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			i, j = ei, ej
+			continue
+		}
+		if a[i] != b[j] {
+			return a[i] < b[j]
+		}
+		i++
+		j++
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	return a < b
+}
+
+// isDigit is a utility function that reports whether a byte is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// digitsEnd is a utility function that returns where the run of digits
+// starting at i ends
+func digitsEnd(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+// nextOrder switches to the next order and lists the current level again
+func (m *Model) nextOrder() {
+	for i, o := range orders {
+		if o == m.Sort {
+			m.Sort = orders[(i+1)%len(orders)]
+			break
+		}
+	}
+	m.KVCache = map[string][]KVPair{}
+	m.resetCursor()
+	m.updateKV()
+}
+
+// pinnedKeys are listed first in every object in the order they are given,
+// whatever order the other keys are in, they can be set from the config file
+// such as for a profile of a kind of document
+var pinnedKeys []string
+
+// pinKV is a utility function that moves the pinned keys of an object to
+// the top in the order they are pinned, leaving the others in their order
+func pinKV(kvpairs []KVPair, pinned []string) {
+	if len(pinned) == 0 {
+		return
+	}
+	rank := map[string]int{}
+	for i, k := range pinned {
+		rank[k] = i
+	}
+	sort.SliceStable(kvpairs, func(i, j int) bool {
+		ri, pi := rank[kvpairs[i].Key]
+		rj, pj := rank[kvpairs[j].Key]
+		if pi && pj {
+			return ri < rj
+		}
+		return pi && !pj
+	})
+}
+
+// togglePin pins the key under the cursor to the top of every object, or
+// unpins it, keeping the cursor on it
+func (m *Model) togglePin() {
+	if m.Flat || len(m.CurrKV) == 0 {
+		return
+	}
+	kv := m.CurrKV[m.CurrC.RowNo]
+	if kv.More || kv.Index {
+		m.Status = "only object keys can be pinned"
+		return
+	}
+	pinned := []string{}
+	for _, k := range m.Pinned {
+		if k != kv.Key {
+			pinned = append(pinned, k)
+		}
+	}
+	verb := "unpinned"
+	if len(pinned) == len(m.Pinned) {
+		pinned, verb = append(pinned, kv.Key), "pinned"
+	}
+	m.Pinned = pinned
+	m.KVCache = map[string][]KVPair{}
+	m.updateKV()
+	for i, row := range m.CurrKV {
+		if row.Key == kv.Key {
+			m.CurrC.RowNo = i
+		}
+	}
+	m.syncPage()
+	m.Status = fmt.Sprintf("%s %s, pin = %q in the config file keeps these pinned", verb, sanitize(kv.Key), strings.Join(m.Pinned, ", "))
+}
@@ -0,0 +1,84 @@
+package jv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortKV(t *testing.T) {
+	input := `{"b": 1, "B": 2, "a10": 3, "a2": 4, "_": 5}`
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{orderSource, []string{"b", "B", "a10", "a2", "_"}},
+		{orderNatural, []string{"B", "_", "a2", "a10", "b"}},
+		{orderKey, []string{"B", "_", "a10", "a2", "b"}},
+		{orderCase, []string{"_", "a10", "a2", "B", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			data, err := parseJson([]byte(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the same input lists the same way every time
+			for run := 0; run < 5; run++ {
+				kvpairs := getInitialKV(data)
+				sortKV(kvpairs, tt.order)
+				got := []string{}
+				for _, kv := range kvpairs {
+					got = append(got, kv.Key)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	for _, order := range orders {
+		if err := checkOrder(order); err != nil {
+			t.Errorf("%s: %s", order, err)
+		}
+	}
+	if err := checkOrder("random"); err == nil {
+		t.Error("an unknown order is accepted")
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"item2", "item10", true},
+		{"item10", "item2", false},
+		{"2", "10", true},
+		{"a", "a1", true},
+		{"a1", "a", false},
+		{"a1b", "a1c", true},
+		{"v1.9", "v1.10", true},
+		// leading zeros do not change the value of a number
+		{"item002", "item10", true},
+		{"item010", "item9", false},
+		{"007", "8", true},
+		// numbers that only differ in leading zeros are told apart by
+		// their bytes so that the order is total
+		{"item01", "item1", true},
+		{"item1", "item01", false},
+		{"0", "00", true},
+		{"00", "0", false},
+		{"item01x", "item1y", true},
+		{"same", "same", false},
+		{"", "a", true},
+		{"a", "", false},
+	}
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package jv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// outlineDepth is how many levels of the document the outline lists, 1 for
+// the top-level keys or 2 for their children as well
+// it can be set in the config file or from the command line
+var outlineDepth = 1
+
+// outlineLimit is the most entries the outline lists, the rest of a huge
+// document is left out
+const outlineLimit = 500
+
+// outlineEntry is a key listed in the outline
+type outlineEntry struct {
+	Path  []string // path of the value from the root
+	Label string   // key of the value, or its index in brackets
+	Count int      // children of the value, -1 if it is not an object or array
+}
+
+// Outline is a sidebar listing the top levels of the document beside the
+// listing, it stays while moving around so that where the listing is in
+// the document can always be seen
+type Outline struct {
+	Entries []outlineEntry // keys listed, in the order of the document
+	Cut     bool           // whether entries were left out past the limit
+	Cursor  int            // entry picked while the outline is focused
+	Offset  int            // first entry shown
+	Focused bool           // whether the keys move the cursor of the outline rather than the listing
+}
+
+// checkOutlineDepth is a utility function that checks the outline lists
+// one or two levels
+func checkOutlineDepth(depth int) error {
+	if depth != 1 && depth != 2 {
+		return fmt.Errorf("expected an outline depth of 1 or 2 but got %d", depth)
+	}
+	return nil
+}
+
+// outlineEntries is a utility function that lists the keys of data down to
+// the outline depth, reporting whether some were left out past the limit
+func outlineEntries(data any) ([]outlineEntry, bool) {
+	entries := []outlineEntry{}
+	var add func(o any, path []string) bool
+	add = func(o any, path []string) bool {
+		keys, children := orderedChildren(o)
+		arr := isArray(o)
+		for _, k := range keys {
+			if len(entries) == outlineLimit {
+				return true
+			}
+			p := append(append([]string{}, path...), k)
+			label := sanitize(k)
+			if arr {
+				label = "[" + k + "]"
+			}
+			count := -1
+			if childKeys, _ := orderedChildren(children[k]); childKeys != nil {
+				count = len(childKeys)
+			}
+			entries = append(entries, outlineEntry{Path: p, Label: label, Count: count})
+			if len(p) < outlineDepth && count > 0 && add(children[k], p) {
+				return true
+			}
+		}
+		return false
+	}
+	cut := add(data, []string{})
+	return entries, cut
+}
+
+// toggleOutline shows the outline and moves the keys to it, or hides it
+// when the keys are already in it
+func (m *Model) toggleOutline() {
+	if m.Outline != nil && m.Outline.Focused {
+		m.Outline = nil
+		return
+	}
+	if m.Outline == nil {
+		m.Outline = &Outline{}
+		m.refreshOutline()
+	}
+	m.Outline.Cursor, m.Outline.Focused = m.outlineCurrent(), true
+}
+
+// refreshOutline lists the keys of the data again, after it is reloaded
+func (m *Model) refreshOutline() {
+	o := m.Outline
+	if o == nil {
+		return
+	}
+	o.Entries, o.Cut = outlineEntries(m.Data)
+	if o.Cursor >= len(o.Entries) {
+		o.Cursor = len(o.Entries) - 1
+	}
+	if o.Cursor < 0 {
+		o.Cursor = 0
+	}
+}
+
+// outlineCurrent returns the entry the listing is in, the one with the
+// longest path leading to the row under the cursor, -1 if there is none
+func (m *Model) outlineCurrent() int {
+	path := m.Path
+	if len(m.CurrKV) > 0 && !m.CurrKV[m.CurrC.RowNo].More {
+		path = m.currentPath()
+	}
+	current, longest := -1, 0
+	for i, e := range m.Outline.Entries {
+		if len(e.Path) > longest && len(e.Path) <= len(path) && pathKey(e.Path) == pathKey(path[:len(e.Path)]) {
+			current, longest = i, len(e.Path)
+		}
+	}
+	return current
+}
+
+// updateOutline handles the keys while the outline is focused
+// enter goes to the entry picked and moves the keys back to the listing
+func (m *Model) updateOutline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	o := m.Outline
+	switch {
+	case key.Matches(msg, m.Keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.Keys.Outline):
+		m.Outline = nil
+	case key.Matches(msg, m.Keys.Close):
+		o.Focused = false
+	case key.Matches(msg, m.Keys.Up):
+		m.moveOutline(-1)
+	case key.Matches(msg, m.Keys.Down):
+		m.moveOutline(1)
+	case key.Matches(msg, m.Keys.PageUp):
+		m.moveOutline(-m.Page.PerPage)
+	case key.Matches(msg, m.Keys.PageDown):
+		m.moveOutline(m.Page.PerPage)
+	case key.Matches(msg, m.Keys.Expand):
+		if len(o.Entries) == 0 {
+			break
+		}
+		o.Focused = false
+		m.Flat, m.Filter = false, ""
+		m.goTo(o.Entries[o.Cursor].Path)
+	}
+	return m, nil
+}
+
+// moveOutline moves the cursor of the outline n entries down, or up if n
+// is negative
+func (m *Model) moveOutline(n int) {
+	o := m.Outline
+	o.Cursor += n
+	if o.Cursor > len(o.Entries)-1 {
+		o.Cursor = len(o.Entries) - 1
+	}
+	if o.Cursor < 0 {
+		o.Cursor = 0
+	}
+}
+
+// outlineWidth is how wide the outline is drawn, a quarter of the terminal
+// within limits
+func (m *Model) outlineWidth() int {
+	width := m.Width / 4
+	if width > 32 {
+		width = 32
+	}
+	if width < 12 {
+		width = 12
+	}
+	return width
+}
+
+// withOutline draws the outline to the left of the rows of the listing,
+// as many entries as there are rows on a page
+// the entry the listing is in is marked and kept in view, as is the
+// cursor of the outline while it is focused
+func (m *Model) withOutline(rows []string) []string {
+	o := m.Outline
+	height := m.Page.PerPage
+	if len(rows) > height {
+		height = len(rows)
+	}
+	width := m.outlineWidth()
+	current := m.outlineCurrent()
+	focus := current
+	if o.Focused {
+		focus = o.Cursor
+	}
+	if focus >= 0 && focus < o.Offset {
+		o.Offset = focus
+	}
+	if focus >= o.Offset+height {
+		o.Offset = focus - height + 1
+	}
+	lines := make([]string, height)
+	for i := range lines {
+		cell := ""
+		n := o.Offset + i
+		switch {
+		case n < len(o.Entries):
+			e := o.Entries[n]
+			marker := "  "
+			if o.Focused && n == o.Cursor {
+				marker = "→ "
+			} else if n == current {
+				marker = "• "
+			}
+			cell = marker + strings.Repeat("  ", len(e.Path)-1) + e.Label
+			if e.Count >= 0 {
+				cell += " (" + strconv.Itoa(e.Count) + ")"
+			}
+			cell = fitCell(cell, width)
+			if o.Focused && n == o.Cursor {
+				cell = style(cell, styleBold)
+			} else if n == current {
+				cell = style(cell, styleKey)
+			}
+		case n == len(o.Entries) && o.Cut:
+			cell = style(fitCell("  …", width), styleFaint)
+		default:
+			cell = fitCell("", width)
+		}
+		row := ""
+		if i < len(rows) {
+			row = rows[i]
+		}
+		lines[i] = cell + " " + style("│", styleFaint) + " " + row
+	}
+	return lines
+}
@@ -0,0 +1,171 @@
+package jv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// overlap is what two values have in common and what only one of them has,
+// a quicker look than their full differences
+type overlap struct {
+	OnlyA  [][]string    // paths in the first value that the second lacks, the outermost of each
+	OnlyB  [][]string    // paths in the second value that the first lacks, the outermost of each
+	Shared [][2][]string // paths of identical objects and arrays in the first and second, the outermost of each
+}
+
+// subtreeHashes is a utility function that hashes every value in o and
+// adds the paths of the objects and arrays with something in them to found
+// by their hash, returning the hash of o
+func subtreeHashes(o any, path []string, found map[string][][]string) string {
+	h := sha256.New()
+	n := 0
+	switch v := o.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		h.Write([]byte("{"))
+		for _, k := range keys {
+			child := append(append([]string{}, path...), k)
+			fmt.Fprintf(h, "%q:%s,", k, subtreeHashes(v[k], child, found))
+		}
+		n = len(v)
+	case []any:
+		h.Write([]byte("["))
+		for i, e := range v {
+			child := append(append([]string{}, path...), strconv.Itoa(i))
+			fmt.Fprintf(h, "%s,", subtreeHashes(e, child, found))
+		}
+		n = len(v)
+	default:
+		b, _ := json.Marshal(v)
+		h.Write(b)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if n > 0 {
+		found[sum] = append(found[sum], path)
+	}
+	return sum
+}
+
+// missingPaths is a utility function that adds the paths in a that b lacks
+// to found, without looking inside them
+// keys are matched by name and elements by position like diffValues does
+func missingPaths(a, b any, path []string, found *[][]string) {
+	child := func(k string) []string {
+		return append(append([]string{}, path...), k)
+	}
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok {
+			return
+		}
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if yv, ok := y[k]; ok {
+				missingPaths(x[k], yv, child(k), found)
+			} else {
+				*found = append(*found, child(k))
+			}
+		}
+	case []any:
+		y, ok := b.([]any)
+		if !ok {
+			return
+		}
+		for i, e := range x {
+			if i < len(y) {
+				missingPaths(e, y[i], child(strconv.Itoa(i)), found)
+			} else {
+				*found = append(*found, child(strconv.Itoa(i)))
+			}
+		}
+	}
+}
+
+// findOverlap is a utility function that works out the overlap of two
+// materialized values
+// identical subtrees are found wherever they are in either value, an
+// object moved to another key still counts
+func findOverlap(a, b any) overlap {
+	o := overlap{OnlyA: [][]string{}, OnlyB: [][]string{}, Shared: [][2][]string{}}
+	missingPaths(a, b, []string{}, &o.OnlyA)
+	missingPaths(b, a, []string{}, &o.OnlyB)
+	inA, inB := map[string][][]string{}, map[string][][]string{}
+	subtreeHashes(a, []string{}, inA)
+	subtreeHashes(b, []string{}, inB)
+	shared := map[string]bool{}
+	for sum, paths := range inA {
+		if _, ok := inB[sum]; ok {
+			for _, p := range paths {
+				shared[pathKey(p)] = true
+			}
+		}
+	}
+	for sum, paths := range inA {
+		others, ok := inB[sum]
+		if !ok {
+			continue
+		}
+		for _, p := range paths {
+			// the subtrees inside a shared one are left out
+			if len(p) > 0 && shared[pathKey(p[:len(p)-1])] {
+				continue
+			}
+			o.Shared = append(o.Shared, [2][]string{p, others[0]})
+		}
+	}
+	sort.Slice(o.Shared, func(i, j int) bool {
+		return pathKey(o.Shared[i][0]) < pathKey(o.Shared[j][0])
+	})
+	return o
+}
+
+// overlapString is a utility function that lists the overlap of a and b
+// with a section for each kind, paths are selectors within a and b
+func overlapString(a, b any, o overlap) string {
+	lines := []string{fmt.Sprintf("Only in the first: %d", len(o.OnlyA))}
+	for _, p := range o.OnlyA {
+		lines = append(lines, "  "+sanitize(selectorPath(a, p)))
+	}
+	lines = append(lines, "", fmt.Sprintf("Only in the second: %d", len(o.OnlyB)))
+	for _, p := range o.OnlyB {
+		lines = append(lines, "  "+sanitize(selectorPath(b, p)))
+	}
+	lines = append(lines, "", fmt.Sprintf("Identical in both: %d", len(o.Shared)))
+	for _, s := range o.Shared {
+		lines = append(lines, fmt.Sprintf("  %s  =  %s", sanitize(selectorPath(a, s[0])), sanitize(selectorPath(b, s[1]))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// overlapWithMark shows what the marked value and the value under the
+// cursor have in common and what only one of them has in the detail pane
+// marking a document in one tab and looking at another in the next
+// triages two documents before comparing them in full
+func (m *Model) overlapWithMark() {
+	if len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return
+	}
+	mk := m.shared.Mark
+	if mk == nil {
+		m.Status = fmt.Sprintf("nothing marked, %s marks the value to look at the overlap with", m.Keys.Mark.Help().Key)
+		return
+	}
+	value := materialize(m.currentNode())
+	o := findOverlap(mk.Value, value)
+	m.openDetail(fmt.Sprintf("Overlap of %s (first) and %s (second)", mk.Label, m.subtreeLabel()),
+		overlapString(mk.Value, value, o), "overlap.txt")
+}
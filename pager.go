@@ -0,0 +1,57 @@
+package jv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pager is the command values are handed to, such as "bat -l json"
+// it can be set in the config file and falls back to $PAGER and then less
+var pager = ""
+
+// pagerDoneMsg is sent once the pager exits and jv is back on screen
+type pagerDoneMsg struct {
+	Err error
+}
+
+// pagerCommand is a utility function that returns the pager to run
+// split into the program and its arguments
+func pagerCommand() []string {
+	for _, s := range []string{pager, os.Getenv("PAGER"), "less"} {
+		if args := strings.Fields(s); len(args) > 0 {
+			return args
+		}
+	}
+	return nil
+}
+
+// pageContent suspends jv and shows the content in the pager, the content
+// is written to the pager's standard input
+func pageContent(content []byte) tea.Cmd {
+	args := pagerCommand()
+	debugLog.Printf("handing %d bytes to %q", len(content), args)
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdin = bytes.NewReader(content)
+	return suspend(c, func(err error) tea.Msg {
+		if err != nil {
+			err = fmt.Errorf("cannot run %s: %w", args[0], err)
+		}
+		return pagerDoneMsg{Err: err}
+	})
+}
+
+// pageNode shows the value under the cursor in the pager as indented JSON
+func (m *Model) pageNode() tea.Cmd {
+	content, err := json.MarshalIndent(m.exportValue(m.currentPath()), "", "  ")
+	if err != nil {
+		m.Status = fmt.Sprintf("cannot format %s: %s", selectorPath(m.Data, m.currentPath()), err)
+		return nil
+	}
+	return pageContent(append(content, '\n'))
+}
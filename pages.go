@@ -0,0 +1,71 @@
+package jv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pageIndicator says which page of the listing is shown and which rows are
+// on it, which unlike a dot for every page still reads at hundreds of pages
+func (m *Model) pageIndicator() string {
+	if m.PagePrompt {
+		return fmt.Sprintf("Go to page (1-%d): %s_", m.Page.TotalPages, m.PageInput)
+	}
+	if len(m.CurrKV) == 0 {
+		return ""
+	}
+	// pages of wrapped rows hold as many rows as fit
+	if m.wrapping() {
+		return fmt.Sprintf("rows %d-%d of %d", m.wrapTop+1, m.wrapEnd, len(m.CurrKV))
+	}
+	s := fmt.Sprintf("page %d/%d", m.Page.Page+1, m.Page.TotalPages)
+	if m.Page.TotalPages > 1 {
+		start, end := m.Page.GetSliceBounds(len(m.CurrKV))
+		s += fmt.Sprintf("  rows %d-%d of %d", start+1, end, len(m.CurrKV))
+	}
+	return s
+}
+
+// openPagePrompt starts typing the number of the page to go to
+func (m *Model) openPagePrompt() {
+	if m.Page.TotalPages <= 1 {
+		m.Status = "the listing fits on one page"
+		return
+	}
+	m.PagePrompt, m.PageInput = true, ""
+}
+
+// updatePagePrompt handles typing the number of the page to go to
+// the cursor moves to the first row of the page so that the page shown
+// and the row selected agree
+func (m *Model) updatePagePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.PagePrompt = false
+	case tea.KeyEnter:
+		m.PagePrompt = false
+		n, err := strconv.Atoi(strings.TrimSpace(m.PageInput))
+		if err != nil || n < 1 || n > m.Page.TotalPages {
+			m.Status = fmt.Sprintf("cannot go to page %s, there are pages 1 to %d", sanitize(m.PageInput), m.Page.TotalPages)
+			return m, nil
+		}
+		m.CurrC.RowNo = (n - 1) * m.Page.PerPage
+		m.syncPage()
+	case tea.KeyBackspace:
+		if len(m.PageInput) > 0 {
+			m.PageInput = m.PageInput[:len(m.PageInput)-1]
+		}
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r >= '0' && r <= '9' {
+				m.PageInput += string(r)
+			}
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,100 @@
+package jv
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pathInput is a prompt for the path of a file
+// tab completes the path from the files on disk and ~ stands for the home
+// directory
+type pathInput struct {
+	Value       string   // the path as it is typed
+	Completions []string // files the path can be completed with, after tab
+}
+
+// update handles a key typed at the prompt
+func (p *pathInput) update(msg tea.KeyMsg) {
+	p.Completions = nil
+	switch msg.Type {
+	case tea.KeyTab:
+		p.Value, p.Completions = completeFile(p.Value)
+	case tea.KeyBackspace:
+		if len(p.Value) > 0 {
+			runes := []rune(p.Value)
+			p.Value = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.Value += string(msg.Runes)
+	}
+}
+
+// path returns the path typed with ~ expanded
+func (p *pathInput) path() string {
+	return expandHome(p.Value)
+}
+
+// view renders the prompt along with the files it can be completed with
+func (p *pathInput) view(prompt string, width int) string {
+	s := prompt + ": " + sanitize(p.Value) + "_"
+	if len(p.Completions) > 0 {
+		s += "\n" + style(truncate(sanitize(strings.Join(p.Completions, "  ")), width), styleFaint)
+	}
+	return s
+}
+
+// expandHome is a utility function that replaces a leading ~ in a path
+// with the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// completeFile is a utility function that completes a path as far as the
+// files it could name agree, returning their names when there are several
+// directories end in a slash so that completing can carry on into them
+// hidden files are only completed once a dot is typed
+func completeFile(path string) (string, []string) {
+	if path == "~" {
+		return "~/", nil
+	}
+	dir, base := filepath.Split(path)
+	read := expandHome(dir)
+	if read == "" {
+		read = "."
+	}
+	entries, err := os.ReadDir(read)
+	if err != nil {
+		return path, nil
+	}
+	names := []string{}
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		// links to directories are followed to tell them apart from files
+		if info, err := os.Stat(filepath.Join(read, name)); err == nil && info.IsDir() {
+			name += string(filepath.Separator)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	switch len(names) {
+	case 0:
+		return path, nil
+	case 1:
+		return dir + names[0], nil
+	}
+	return dir + commonPrefix(names), names
+}
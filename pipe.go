@@ -0,0 +1,52 @@
+package jv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// stdoutPiped is a utility function that reports whether standard output
+// goes to a pipe or a file rather than to a terminal
+func stdoutPiped() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// printSelection writes the value under the cursor as JSON for the program
+// standard output is piped to, with its secrets masked if they are
+func printSelection(out io.Writer, m *Model) error {
+	if m == nil || m.Data == nil || m.Err != nil {
+		return nil
+	}
+	content, err := json.MarshalIndent(m.exportValue(m.selection()), "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot print the selection: %w", err)
+	}
+	m.audit(auditExport, m.selection(), "stdout")
+	_, err = fmt.Fprintf(out, "%s\n", content)
+	return err
+}
+
+// printValue loads the input and writes the value at path as JSON without
+// the viewer, for when there is no terminal to show it on
+func printValue(out io.Writer, source string, git bool, path []string) error {
+	data, _, err := readJsonSource(context.Background(), &loadProgress{}, source, git)
+	if err != nil {
+		return err
+	}
+	m := NewModel(source)
+	m.Data = data
+	if err := m.checkPath(path); err != nil {
+		return err
+	}
+	m.audit(auditExport, path, "stdout")
+	content, err := json.MarshalIndent(m.exportValue(path), "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot print the input: %w", err)
+	}
+	_, err = fmt.Fprintf(out, "%s\n", content)
+	return err
+}
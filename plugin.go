@@ -0,0 +1,201 @@
+package jv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pluginPrefix starts the name of every plugin executable
+const pluginPrefix = "jv-"
+
+// Palette is the prompt for picking a plugin to run on the value under the cursor
+type Palette struct {
+	Input   string   // text typed to narrow down the plugins
+	Plugins []string // every plugin found on PATH
+	Cursor  int      // plugin that enter runs among the matching ones
+}
+
+// pluginInput is what a plugin reads from its standard input
+type pluginInput struct {
+	Path  string   `json:"path"`  // selector of the value such as .a[0]
+	Keys  []string `json:"keys"`  // the keys on the way to the value
+	Value any      `json:"value"` // the value itself
+}
+
+// pluginMsg is sent once a plugin exits
+type pluginMsg struct {
+	Name   string // plugin that ran
+	Path   string // selector of the value it ran on
+	Output string // what it wrote to standard output
+	Err    error
+}
+
+// findPlugins is a utility function that returns the names of the jv-<name>
+// executables on PATH without the prefix
+// earlier directories on PATH win like they do in the shell
+func findPlugins() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := strings.TrimSuffix(e.Name(), ".exe")
+			if !strings.HasPrefix(name, pluginPrefix) || name == pluginPrefix || seen[name] {
+				continue
+			}
+			info, err := os.Stat(filepath.Join(dir, e.Name()))
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+			seen[name] = true
+			names = append(names, strings.TrimPrefix(name, pluginPrefix))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matches returns the plugins whose names contain the typed text
+func (p *Palette) matches() []string {
+	found := []string{}
+	for _, name := range p.Plugins {
+		if strings.Contains(name, strings.ToLower(p.Input)) {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+// runPlugin runs a plugin in the background with the value on its
+// standard input
+func runPlugin(name string, input pluginInput) tea.Cmd {
+	return func() tea.Msg {
+		content, err := json.Marshal(input)
+		if err != nil {
+			return pluginMsg{Name: name, Path: input.Path, Err: err}
+		}
+		debugLog.Printf("running %s%s on %s", pluginPrefix, name, input.Path)
+		var stdout, stderr bytes.Buffer
+		c := exec.Command(pluginPrefix + name)
+		c.Stdin = bytes.NewReader(content)
+		c.Stdout = &stdout
+		c.Stderr = &stderr
+		if err := c.Run(); err != nil {
+			// the first line the plugin complained with says the most
+			if line, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); line != "" {
+				err = fmt.Errorf("%w: %s", err, line)
+			}
+			return pluginMsg{Name: name, Path: input.Path, Err: err}
+		}
+		return pluginMsg{Name: name, Path: input.Path, Output: stdout.String()}
+	}
+}
+
+// openPalette starts picking a plugin to run on the value under the cursor
+func (m *Model) openPalette() {
+	plugins := findPlugins()
+	if len(plugins) == 0 {
+		m.Status = fmt.Sprintf("no plugins found, plugins are executables named %s<name> on PATH", pluginPrefix)
+		return
+	}
+	m.Palette = &Palette{Plugins: plugins}
+}
+
+// updatePalette handles key presses while the palette is open
+func (m *Model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.Palette
+	switch {
+	case key.Matches(msg, m.Keys.Quit):
+		return m, tea.Quit
+	case msg.Type == tea.KeyEsc:
+		m.Palette = nil
+	case key.Matches(msg, m.Keys.Up):
+		if p.Cursor > 0 {
+			p.Cursor--
+		}
+	case key.Matches(msg, m.Keys.Down):
+		if p.Cursor < len(p.matches())-1 {
+			p.Cursor++
+		}
+	case msg.Type == tea.KeyEnter:
+		found := p.matches()
+		if len(found) == 0 {
+			return m, nil
+		}
+		m.Palette = nil
+		path := m.currentPath()
+		input := pluginInput{
+			Path:  selectorPath(m.Data, path),
+			Keys:  path,
+			Value: m.exportValue(path),
+		}
+		m.Status = fmt.Sprintf("running %s%s on %s…", pluginPrefix, found[p.Cursor], input.Path)
+		return m, runPlugin(found[p.Cursor], input)
+	case msg.Type == tea.KeyBackspace:
+		if len(p.Input) > 0 {
+			runes := []rune(p.Input)
+			p.Input = string(runes[:len(runes)-1])
+			p.Cursor = 0
+		}
+	case msg.Type == tea.KeyRunes:
+		p.Input += string(msg.Runes)
+		p.Cursor = 0
+	}
+	return m, nil
+}
+
+// finishPlugin shows what a plugin wrote in the detail pane
+func (m *Model) finishPlugin(msg pluginMsg) {
+	if msg.Err != nil {
+		debugLog.Printf("%s%s failed: %s", pluginPrefix, msg.Name, msg.Err)
+		m.Status = fmt.Sprintf("%s%s failed: %s", pluginPrefix, msg.Name, msg.Err)
+		return
+	}
+	m.Status = ""
+	m.openDetail(
+		fmt.Sprintf("%s%s on %s", pluginPrefix, msg.Name, sanitize(msg.Path)),
+		pluginOutput(msg.Output),
+		msg.Name+".txt")
+}
+
+// pluginOutput is a utility function that makes the output of a plugin safe
+// to show, escaping control characters line by line
+func pluginOutput(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		lines[i] = sanitize(strings.ReplaceAll(line, "\t", "    "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// viewPalette renders the palette of plugins
+func (m *Model) viewPalette() string {
+	p := m.Palette
+	s := fmt.Sprintf("Run plugin on %s: %s_\n\n", style(sanitize(selectorPath(m.Data, m.currentPath())), styleBold), p.Input)
+	found := p.matches()
+	if len(found) == 0 {
+		s += "(no plugins match)\n"
+	}
+	for i, name := range found {
+		if i == p.Cursor {
+			s += style("→ "+pluginPrefix+name, styleBold) + "\n"
+		} else {
+			s += "  " + pluginPrefix + name + "\n"
+		}
+	}
+	s += "\n" + style("Run: enter  Cancel: esc  Up: ↑  Down: ↓", styleFaint) + "\n"
+	return s
+}
@@ -0,0 +1,81 @@
+package jv
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// previewDepth is how many levels of a container are shown inline in the
+// listing, 0 only shows {} or [], it can be set from the command line
+var previewDepth = 0
+
+// previewItems is the most children shown inline for each container, 0 for
+// no limit, it can be set from the command line
+var previewItems = 10
+
+// previewLength is the longest a preview is shown in characters before it is
+// cut short, 0 for no limit, it can be set from the command line
+var previewLength = 0
+
+// shortPreview is a utility function that cuts a preview down to the
+// preview length
+func shortPreview(s string) string {
+	if previewLength <= 0 || utf8.RuneCountInString(s) <= previewLength {
+		return s
+	}
+	return string([]rune(s)[:previewLength-1]) + "…"
+}
+
+// preview is a utility function that returns a compact one line preview of
+// o going depth levels deep
+// containers past that depth are shown as {…} or […], and values that
+// secret reports are masked unless it is nil
+func preview(o any, depth int, secret func(key string, o any) bool) string {
+	keys, children := orderedChildren(o)
+	if keys == nil {
+		return getVal(o)
+	}
+	open, close := "{", "}"
+	arr := isArray(o)
+	if arr {
+		open, close = "[", "]"
+	}
+	if len(keys) == 0 {
+		return open + close
+	}
+	if depth <= 0 {
+		return open + "…" + close
+	}
+	parts := []string{}
+	for i, k := range keys {
+		if previewItems > 0 && i == previewItems {
+			parts = append(parts, "…")
+			break
+		}
+		s := preview(children[k], depth-1, secret)
+		// strings are quoted so they can be told apart from other values
+		switch val := children[k].(type) {
+		case string:
+			s = strconv.Quote(val)
+		case json.RawMessage:
+			if rawKind(val) == '"' {
+				s = string(bytes.TrimSpace(val))
+			}
+		}
+		if secret != nil && secret(k, children[k]) {
+			s = secretMask
+		}
+		if !arr {
+			if gronIdentifier.MatchString(k) {
+				s = k + ": " + s
+			} else {
+				s = strconv.Quote(k) + ": " + s
+			}
+		}
+		parts = append(parts, s)
+	}
+	return open + strings.Join(parts, ", ") + close
+}
@@ -0,0 +1,204 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recentLimit is the most inputs remembered, the ones opened longest ago go first
+const recentLimit = 50
+
+// recentEntry is an input that was opened
+type recentEntry struct {
+	Path   string    `json:"path"`   // file, URL or other source as given on the command line
+	Git    bool      `json:"git"`    // Path is a git object such as HEAD~1:config.json
+	Opened time.Time `json:"opened"` // when it was last opened
+}
+
+// Recent is the picker for reopening an input that was opened before
+type Recent struct {
+	Input   string        // text typed to narrow down the inputs
+	Entries []recentEntry // inputs opened before, the latest first
+	Cursor  int           // input that enter opens among the matching ones
+}
+
+// recentPath is a utility function that returns the file the recent inputs
+// are kept in
+func recentPath() string {
+	dir := stateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "recent.json")
+}
+
+// loadRecent is a utility function that returns the inputs opened before,
+// the latest first
+func loadRecent() []recentEntry {
+	entries := []recentEntry{}
+	content, err := os.ReadFile(recentPath())
+	if err != nil {
+		return entries
+	}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		debugLog.Printf("cannot read recent inputs: %s", err)
+	}
+	return entries
+}
+
+// addRecent is a utility function that puts an input at the top of the
+// recent inputs
+// standard input and the clipboard cannot be opened again so they are left
+// out, files are remembered by their absolute path and URLs without their
+// password
+// it is best effort so failing to save is only logged
+func addRecent(path string, git bool) {
+	file := recentPath()
+	switch {
+	case file == "", path == "", path == "-", path == clipboardPath:
+		return
+	case !git:
+		switch sourceFor(path).(type) {
+		case fileSource, archiveSource:
+			if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+		case urlSource:
+			if u, err := url.Parse(path); err == nil {
+				path = u.Redacted()
+			}
+		}
+	}
+	entries := []recentEntry{{Path: path, Git: git, Opened: time.Now()}}
+	for _, e := range loadRecent() {
+		if (e.Path != path || e.Git != git) && len(entries) < recentLimit {
+			entries = append(entries, e)
+		}
+	}
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err == nil {
+		err = writeState(file, content)
+	}
+	if err != nil {
+		debugLog.Printf("cannot save recent inputs: %s", err)
+	}
+}
+
+// matches returns the inputs whose path contains what was typed
+func (r *Recent) matches() []recentEntry {
+	found := []recentEntry{}
+	for _, e := range r.Entries {
+		if strings.Contains(strings.ToLower(e.Path), strings.ToLower(r.Input)) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// openRecent shows the picker of inputs opened before
+func (m *Model) openRecent() {
+	entries := loadRecent()
+	if len(entries) == 0 {
+		m.Status = "no inputs have been opened yet"
+		return
+	}
+	m.Recent = &Recent{Entries: entries}
+}
+
+// updateRecent handles key presses while the recent inputs are shown
+// closing the picker quits when it was opened with -recent since there is
+// nothing else to show
+func (m *Model) updateRecent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	r := m.Recent
+	switch {
+	case key.Matches(msg, m.Keys.Quit):
+		return m, tea.Quit
+	case msg.Type == tea.KeyEsc:
+		if m.Data == nil {
+			return m, tea.Quit
+		}
+		m.Recent = nil
+	case key.Matches(msg, m.Keys.Up):
+		if r.Cursor > 0 {
+			r.Cursor--
+		}
+	case key.Matches(msg, m.Keys.Down):
+		if r.Cursor < len(r.matches())-1 {
+			r.Cursor++
+		}
+	case msg.Type == tea.KeyEnter:
+		found := r.matches()
+		if len(found) == 0 {
+			return m, nil
+		}
+		m.Recent = nil
+		m.Start = nil
+		m.saveSession()
+		return m, m.reload(found[r.Cursor].Path, found[r.Cursor].Git)
+	// the input can be opened in a new tab instead, once this one has
+	// something to show
+	case key.Matches(msg, m.Keys.OpenTab) && m.Data != nil:
+		found := r.matches()
+		if len(found) == 0 {
+			return m, nil
+		}
+		m.Recent = nil
+		return m, openDoc(m.sibling(found[r.Cursor].Path, found[r.Cursor].Git))
+	case msg.Type == tea.KeyBackspace:
+		if len(r.Input) > 0 {
+			runes := []rune(r.Input)
+			r.Input = string(runes[:len(runes)-1])
+			r.Cursor = 0
+		}
+	case msg.Type == tea.KeyRunes:
+		r.Input += string(msg.Runes)
+		r.Cursor = 0
+	}
+	return m, nil
+}
+
+// viewRecent renders the picker of inputs opened before
+func (m *Model) viewRecent() string {
+	r := m.Recent
+	s := fmt.Sprintf("Open recent: %s_\n\n", r.Input)
+	found := r.matches()
+	if len(found) == 0 {
+		s += "(no recent inputs match)\n"
+	}
+	// the list scrolls to keep the cursor on the screen
+	rows := m.Height - 5
+	if rows < 1 {
+		rows = len(found)
+	}
+	first := 0
+	if r.Cursor >= rows {
+		first = r.Cursor - rows + 1
+	}
+	for i := first; i < len(found) && i < first+rows; i++ {
+		e := found[i]
+		line := sanitize(e.Path)
+		if e.Git {
+			line += " (git)"
+		}
+		line += "  " + style(relativeTime(time.Since(e.Opened)), styleFaint)
+		if i == r.Cursor {
+			s += style("→ ", styleBold) + line + "\n"
+		} else {
+			s += "  " + line + "\n"
+		}
+	}
+	help := "Open: enter  Cancel: esc  Up: ↑  Down: ↓"
+	if m.Data != nil {
+		help += "  " + strings.TrimSpace(helpLine(m.Keys.OpenTab))
+	}
+	s += "\n" + style(help, styleFaint) + "\n"
+	return s
+}
@@ -0,0 +1,263 @@
+package jv
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ways values matching a redaction rule are replaced
+const (
+	redactPlaceholder = "placeholder" // the same text for every value
+	redactHash        = "hash"        // a hash of the value, the same for equal values
+)
+
+// redactedText is what values are replaced with, hashes are written inside
+// it after a space
+const redactedText = "[redacted]"
+
+// redactRule replaces the values at paths matching a pattern as soon as the
+// input is read, so that they are never shown, searched, copied or exported
+// a pattern is either a regular expression between slashes matched against
+// the key of the value, or dotted keys with * matching any run of characters
+// matched against the last keys of the path, or against the whole path when
+// it starts with a dot like the selectors
+type redactRule struct {
+	Pattern  string
+	Action   string
+	key      *regexp.Regexp // the expression of /regexp/ patterns
+	anchored bool           // whether a dotted pattern starts at the root
+}
+
+// redactRules are the rules of the [redact] table of the config file
+var redactRules []redactRule
+
+// redactKey keys the hashes of redacted values, it is drawn again every time
+// jv starts so that equal values can be told apart in a session but short
+// values such as card numbers cannot be found again from their hashes
+var redactKey = func() []byte {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return b
+}()
+
+// parseRedaction is a utility function that reads a rule of the [redact]
+// table
+func parseRedaction(pattern, action string) (redactRule, error) {
+	if action != redactPlaceholder && action != redactHash {
+		return redactRule{}, fmt.Errorf("unknown redaction %q, expected placeholder or hash", action)
+	}
+	rule := redactRule{Pattern: pattern, Action: action}
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return redactRule{}, fmt.Errorf("invalid key pattern %s: %w", pattern, err)
+		}
+		rule.key = re
+		return rule, nil
+	}
+	rule.anchored = strings.HasPrefix(pattern, ".")
+	for _, part := range strings.Split(strings.TrimPrefix(pattern, "."), ".") {
+		if _, err := path.Match(part, ""); err != nil || part == "" {
+			return redactRule{}, fmt.Errorf("invalid path pattern %s", pattern)
+		}
+	}
+	return rule, nil
+}
+
+// redactionRules is a utility function that reads the rules of the config
+// file in the order of their patterns, the patterns were checked when they
+// were set
+func redactionRules(rules map[string]string) []redactRule {
+	patterns := []string{}
+	for pattern := range rules {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	parsed := []redactRule{}
+	for _, pattern := range patterns {
+		if rule, err := parseRedaction(pattern, rules[pattern]); err == nil {
+			parsed = append(parsed, rule)
+		}
+	}
+	return parsed
+}
+
+// matches checks if the value at p is redacted by the rule
+func (r redactRule) matches(p []string) bool {
+	if len(p) == 0 {
+		return false
+	}
+	if r.key != nil {
+		return r.key.MatchString(p[len(p)-1])
+	}
+	pattern := strings.TrimPrefix(r.Pattern, ".")
+	if r.anchored && strings.Count(pattern, ".")+1 != len(p) {
+		return false
+	}
+	return concealed(p, []string{pattern})
+}
+
+// isRedacted is a utility function that checks if a value was redacted
+// already, so that redacting again for a profile detected later leaves it
+func isRedacted(o any) bool {
+	if raw, ok := o.(json.RawMessage); ok && rawKind(raw) == '"' {
+		o = materialize(raw)
+	}
+	s, ok := o.(string)
+	return ok && strings.HasPrefix(s, redactedText[:len(redactedText)-1]) && strings.HasSuffix(s, "]")
+}
+
+// redactedValue is a utility function that returns what a value is replaced
+// with
+func redactedValue(o any, action string) any {
+	if action != redactHash {
+		return redactedText
+	}
+	b, _ := jsonScalar(materialize(o))
+	mac := hmac.New(sha256.New, redactKey)
+	mac.Write(b)
+	sum := hex.EncodeToString(mac.Sum(nil))[:12]
+	return strings.TrimSuffix(redactedText, "]") + " " + sum + "]"
+}
+
+// rawJson is a utility function that returns a value as JSON, values not
+// parsed yet as they are in the input
+func rawJson(o any) []byte {
+	switch v := o.(type) {
+	case json.RawMessage:
+		return v
+	case fileSpan:
+		return v.bytes()
+	}
+	b, _ := jsonScalar(o)
+	return b
+}
+
+// redactValue returns o with the values matching the rules replaced, and
+// whether any were, adding the paths of the values it replaced to redacted
+// values not parsed yet stay so, the replacements are spliced into the
+// bytes of the objects and arrays holding them so that everything else in
+// them, duplicate keys included, is kept as it was
+// inputs are not streamed when there are rules, so streamed containers
+// only reach it when a detected profile adds rules, and are left as they
+// are until the input is read into memory again
+func redactValue(o any, p []string, rules []redactRule, redacted map[string]bool) (any, bool) {
+	for _, r := range rules {
+		if r.matches(p) && !isRedacted(o) {
+			redacted[pathKey(p)] = true
+			return redactedValue(o, r.Action), true
+		}
+	}
+	child := func(k string) []string {
+		return append(append([]string{}, p...), k)
+	}
+	switch v := o.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		changed := false
+		for k, c := range v {
+			var ok bool
+			out[k], ok = redactValue(c, child(k), rules, redacted)
+			changed = changed || ok
+		}
+		if !changed {
+			return o, false
+		}
+		return out, true
+	case []any:
+		out := make([]any, len(v))
+		changed := false
+		for i, c := range v {
+			var ok bool
+			out[i], ok = redactValue(c, child(fmt.Sprint(i)), rules, redacted)
+			changed = changed || ok
+		}
+		if !changed {
+			return o, false
+		}
+		return out, true
+	case json.RawMessage:
+		return redactRaw(v, p, rules, redacted)
+	}
+	return o, false
+}
+
+// redactRaw redacts the children of an object or array not parsed yet,
+// writing each replacement where the value it replaces was in raw
+func redactRaw(raw json.RawMessage, p []string, rules []redactRule, redacted map[string]bool) (any, bool) {
+	kind := rawKind(raw)
+	if kind != '{' && kind != '[' {
+		return raw, false
+	}
+	var out []byte
+	last := 0
+	i := skipSpace(raw, 0) + 1
+	for n := 0; ; n++ {
+		i = skipSpace(raw, i)
+		if i >= len(raw) || raw[i] == '}' || raw[i] == ']' {
+			break
+		}
+		key := strconv.Itoa(n)
+		if kind == '{' {
+			end := skipValue(raw, i)
+			if err := json.Unmarshal(raw[i:end], &key); err != nil {
+				break
+			}
+			// step over the colon
+			i = skipSpace(raw, end) + 1
+			i = skipSpace(raw, i)
+		}
+		end := skipValue(raw, i)
+		value, changed := redactValue(json.RawMessage(raw[i:end]), append(append([]string{}, p...), key), rules, redacted)
+		if changed {
+			out = append(append(out, raw[last:i]...), rawJson(value)...)
+			last = end
+		}
+		// step over the comma
+		i = skipSpace(raw, end)
+		if i < len(raw) && raw[i] == ',' {
+			i++
+		}
+	}
+	if out == nil {
+		return raw, false
+	}
+	return json.RawMessage(append(out, raw[last:]...)), true
+}
+
+// redactInput replaces the values of the input matching the redaction rules,
+// and leaves out the text lenient mode rewrote for them or inside them
+func redactInput(data any, relaxed map[string]string) (any, map[string]string) {
+	if len(redactRules) == 0 {
+		return data, relaxed
+	}
+	redacted := map[string]bool{}
+	data, _ = redactValue(data, []string{}, redactRules, redacted)
+	debugLog.Printf("redacted %d values", len(redacted))
+	if len(redacted) == 0 || len(relaxed) == 0 {
+		return data, relaxed
+	}
+	kept := map[string]string{}
+	for k, v := range relaxed {
+		hidden := false
+		for r := range redacted {
+			if k == r || strings.HasPrefix(k, strings.TrimSuffix(r, "]")+",") {
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			kept[k] = v
+		}
+	}
+	return data, kept
+}
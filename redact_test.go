@@ -0,0 +1,122 @@
+package jv
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    map[string]string
+		input    string
+		want     string
+		redacted []string
+	}{
+		{
+			name:     "key pattern",
+			rules:    map[string]string{"/^password$/": redactPlaceholder},
+			input:    `{"user": "ann", "password": "hunter2"}`,
+			want:     `{"user": "ann", "password": "[redacted]"}`,
+			redacted: []string{`["password"]`},
+		},
+		{
+			name:     "duplicate keys are kept and both redacted",
+			rules:    map[string]string{"token": redactPlaceholder},
+			input:    `{"token": "a", "id": 1, "token": "b", "id": 2}`,
+			want:     `{"token": "[redacted]", "id": 1, "token": "[redacted]", "id": 2}`,
+			redacted: []string{`["token"]`},
+		},
+		{
+			name:     "duplicate keys next to a nested redaction",
+			rules:    map[string]string{"auth.key": redactPlaceholder},
+			input:    `{"a": 1, "a": 2, "auth": {"key": "k", "kind": "x"}}`,
+			want:     `{"a": 1, "a": 2, "auth": {"key": "[redacted]", "kind": "x"}}`,
+			redacted: []string{`["auth","key"]`},
+		},
+		{
+			name:     "anchored path inside an array",
+			rules:    map[string]string{".users.*.ssn": redactPlaceholder},
+			input:    `{"users": [{"ssn": "1"}, {"name": "b", "ssn": "2"}], "ssn": "3"}`,
+			want:     `{"users": [{"ssn": "[redacted]"}, {"name": "b", "ssn": "[redacted]"}], "ssn": "3"}`,
+			redacted: []string{`["users","0","ssn"]`, `["users","1","ssn"]`},
+		},
+		{
+			name:     "containers are redacted whole",
+			rules:    map[string]string{"secrets": redactPlaceholder},
+			input:    `{"secrets": {"a": [1, 2]}, "x": null}`,
+			want:     `{"secrets": "[redacted]", "x": null}`,
+			redacted: []string{`["secrets"]`},
+		},
+		{
+			name:     "nothing matches",
+			rules:    map[string]string{"nope": redactPlaceholder},
+			input:    `{ "a" : [ 1 , 2 ] }`,
+			want:     `{ "a" : [ 1 , 2 ] }`,
+			redacted: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted := map[string]bool{}
+			got, _ := redactValue(json.RawMessage(tt.input), []string{}, redactionRules(tt.rules), redacted)
+			if string(rawJson(got)) != tt.want {
+				t.Errorf("got %s, want %s", rawJson(got), tt.want)
+			}
+			paths := []string{}
+			for _, p := range tt.redacted {
+				if !redacted[p] {
+					t.Errorf("%s is not reported as redacted", p)
+				}
+				paths = append(paths, p)
+			}
+			if len(redacted) != len(paths) {
+				t.Errorf("redacted %v, want %v", redacted, paths)
+			}
+		})
+	}
+}
+
+func TestRedactKeepsDuplicateKeys(t *testing.T) {
+	rules := redactionRules(map[string]string{"password": redactPlaceholder})
+	data, _ := redactValue(json.RawMessage(`{"user": {"name": "a", "name": "b", "password": "x"}}`), []string{}, rules, map[string]bool{})
+	want := map[string][]string{pathKey([]string{"user"}): {"name"}}
+	if got := findDuplicateKeys(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("duplicate keys %v, want %v", got, want)
+	}
+}
+
+func TestRedactHash(t *testing.T) {
+	rules := redactionRules(map[string]string{"id": redactHash})
+	got, _ := redactValue(json.RawMessage(`[{"id": "a"}, {"id": "a"}, {"id": "b"}]`), []string{}, rules, map[string]bool{})
+	values := materialize(got).([]any)
+	first := values[0].(map[string]any)["id"].(string)
+	if !strings.HasPrefix(first, "[redacted ") || !isRedacted(first) {
+		t.Fatalf("hash %q is not marked as redacted", first)
+	}
+	if values[1].(map[string]any)["id"] != first {
+		t.Errorf("equal values hash differently")
+	}
+	if values[2].(map[string]any)["id"] == first {
+		t.Errorf("different values hash the same")
+	}
+	// redacting again leaves the hashes as they are
+	again, changed := redactValue(got, []string{}, rules, map[string]bool{})
+	if changed || string(rawJson(again)) != string(rawJson(got)) {
+		t.Errorf("redacting again changed %s into %s", rawJson(got), rawJson(again))
+	}
+}
+
+func TestRedactedInputsAreNotStreamed(t *testing.T) {
+	defer func(rules []redactRule) { redactRules = rules }(redactRules)
+	redactRules = nil
+	if !shouldStream(streamThreshold + 1) {
+		t.Fatalf("large input is not streamed")
+	}
+	redactRules = redactionRules(map[string]string{"password": redactPlaceholder})
+	if shouldStream(streamThreshold + 1) {
+		t.Errorf("large input is streamed even though there are redaction rules")
+	}
+}
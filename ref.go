@@ -0,0 +1,179 @@
+package jv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// jump is a place in the listing that following a $ref left from
+type jump struct {
+	Path []string // level that was shown
+	Row  int      // row the cursor was on
+}
+
+// refMsg is sent once a $ref into another document has been resolved
+type refMsg struct {
+	Ref     string // the reference that was followed
+	Content string // the referenced value as indented JSON
+	Err     error
+}
+
+// refAt returns the $ref of the value under the cursor, which is either
+// the string of a $ref key or the $ref of an object holding one
+func (m *Model) refAt() string {
+	kv := m.CurrKV[m.CurrC.RowNo]
+	node := materializeScalar(m.currentNode())
+	if ref, ok := node.(string); ok && kv.Key == "$ref" {
+		return ref
+	}
+	if children := getKAny(m.currentNode()); children != nil && !isArray(m.currentNode()) {
+		if ref, ok := materializeScalar(children["$ref"]).(string); ok {
+			return ref
+		}
+	}
+	return ""
+}
+
+// materializeScalar parses a raw scalar but leaves containers alone
+func materializeScalar(o any) any {
+	if getKAny(o) != nil {
+		return o
+	}
+	return materialize(o)
+}
+
+// pointerPath is a utility function that turns a JSON Pointer such as
+// /components/schemas/Pet into a path of keys
+func pointerPath(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("pointer %s does not start with /", pointer)
+	}
+	path := []string{}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		path = append(path, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+	}
+	return path, nil
+}
+
+// followRef goes to what the $ref under the cursor points to
+// references within the document move the listing there and references
+// into other files are shown in the detail pane
+func (m *Model) followRef() tea.Cmd {
+	ref := m.refAt()
+	target, fragment, _ := strings.Cut(ref, "#")
+	if target != "" {
+		source := m.Source
+		return m.startTask("ref", "following "+sanitize(ref), func(ctx context.Context, _ func(int)) tea.Msg {
+			return resolveExternalRef(ctx, source, ref)
+		})
+	}
+	// the pointer is a URI fragment so it can be percent encoded
+	var path []string
+	pointer, err := url.PathUnescape(fragment)
+	if err == nil {
+		path, err = pointerPath(pointer)
+	}
+	if err == nil {
+		err = m.checkPath(path)
+	}
+	if err != nil {
+		m.Status = fmt.Sprintf("cannot follow %s: %s", ref, err)
+		return nil
+	}
+	m.Jumps = append(m.Jumps, jump{Path: append([]string{}, m.Path...), Row: m.CurrC.RowNo})
+	m.goTo(path)
+	m.Status = fmt.Sprintf("followed %s, %s returns", ref, m.Keys.Return.Help().Key)
+	return nil
+}
+
+// returnFromRef goes back to where the last $ref was followed from
+func (m *Model) returnFromRef() {
+	if len(m.Jumps) == 0 {
+		m.Status = "no $ref to return from"
+		return
+	}
+	j := m.Jumps[len(m.Jumps)-1]
+	m.Jumps = m.Jumps[:len(m.Jumps)-1]
+	if missing := m.goToNearest(j.Path); missing != "" {
+		m.Status = "cannot return all the way, " + missing
+		return
+	}
+	if j.Row < len(m.CurrKV) {
+		m.CurrC.RowNo = j.Row
+	}
+}
+
+// resolveExternalRef loads the document a $ref points into, relative to
+// the input it was found in, and resolves the pointer in it
+// the document names the target so only files and http(s) URLs are read,
+// never the other sources such as commands or cloud storage
+func resolveExternalRef(ctx context.Context, source, ref string) tea.Msg {
+	target, fragment, _ := strings.Cut(ref, "#")
+	if _, ok := sourceFor(target).(fileSource); !ok && !isURL(target) {
+		return refMsg{Ref: ref, Err: fmt.Errorf("only files and http(s) URLs can be followed")}
+	}
+	switch {
+	case isURL(target) || filepath.IsAbs(target):
+	case isURL(source):
+		base, err := url.Parse(source)
+		if err != nil {
+			return refMsg{Ref: ref, Err: err}
+		}
+		rel, err := url.Parse(target)
+		if err != nil {
+			return refMsg{Ref: ref, Err: err}
+		}
+		target = base.ResolveReference(rel).String()
+	case source != "":
+		target = filepath.Join(filepath.Dir(source), target)
+	}
+	debugLog.Printf("following %s into %s", ref, target)
+	var data any
+	var err error
+	switch _, file := sourceFor(target).(fileSource); {
+	case isURL(target):
+		// the headers and credentials of the input only go where it came from
+		data, _, err = readSource(ctx, &loadProgress{}, urlSource{Anonymous: !sameOrigin(target, source)}, target)
+	case file:
+		data, _, err = readJsonFile(ctx, &loadProgress{}, target)
+	default:
+		// a relative target resolved against a URL can still name a scheme
+		err = fmt.Errorf("only files and http(s) URLs can be followed")
+	}
+	if err != nil {
+		return refMsg{Ref: ref, Err: err}
+	}
+	data, _ = redactInput(data, nil)
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil {
+		return refMsg{Ref: ref, Err: err}
+	}
+	val, err := resolvePointer(materialize(data), pointer)
+	closeInput(data)
+	if err != nil {
+		return refMsg{Ref: ref, Err: err}
+	}
+	content, err := json.MarshalIndent(val, "", "  ")
+	if err != nil {
+		return refMsg{Ref: ref, Err: err}
+	}
+	return refMsg{Ref: ref, Content: string(content)}
+}
+
+// finishRef shows the value a $ref into another document points to
+func (m *Model) finishRef(msg refMsg) {
+	if msg.Err != nil {
+		m.Status = fmt.Sprintf("cannot follow %s: %s", msg.Ref, msg.Err)
+		return
+	}
+	m.openDetail(fmt.Sprintf("$ref %s", sanitize(msg.Ref)), msg.Content, "ref.json")
+}
@@ -0,0 +1,49 @@
+package jv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveExternalRef(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "defs.json"), []byte(`{"a": {"b": 1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source := filepath.Join(dir, "input.json")
+	marker := filepath.Join(dir, "ran")
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "defs.json#/a", want: `"b": 1`},
+		{ref: filepath.Join(dir, "defs.json") + "#/a/b", want: "1"},
+		{ref: "missing.json#/a", wantErr: "cannot read"},
+		// targets are never run as commands or fetched from cloud storage
+		{ref: "exec:touch " + marker + "#", wantErr: "only files and http(s) URLs"},
+		{ref: "s3://bucket/defs.json#/a", wantErr: "only files and http(s) URLs"},
+		{ref: "gs://bucket/defs.json#/a", wantErr: "only files and http(s) URLs"},
+		{ref: "-#/a", wantErr: "only files and http(s) URLs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			msg := resolveExternalRef(context.Background(), source, tt.ref).(refMsg)
+			if tt.wantErr != "" {
+				if msg.Err == nil || !strings.Contains(msg.Err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", msg.Err, tt.wantErr)
+				}
+			} else if msg.Err != nil {
+				t.Fatal(msg.Err)
+			} else if !strings.Contains(msg.Content, tt.want) {
+				t.Errorf("got %s, want %s", msg.Content, tt.want)
+			}
+		})
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("following an exec: $ref ran the command")
+	}
+}
@@ -0,0 +1,108 @@
+package jv
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchInput reloads the input whenever it changes, it can be set from the
+// command line
+var watchInput bool
+
+// spot is where the listing was before reloading, which it goes back to
+// as far as the new input allows
+type spot struct {
+	Path   []string // path of the listing
+	Key    string   // key under the cursor
+	Row    int      // row the cursor was on, for when the key is gone
+	Flat   bool     // the flattened view was shown
+	Filter string   // filter of the flattened view
+	Data   any      // copy of the document before reloading, to mark what changed
+}
+
+// watchMsg is sent when the input has changed, or could not be watched
+type watchMsg struct {
+	Err error
+}
+
+// reloadInPlace loads the input again and goes back to where the listing was
+// if the input failed to load last time the place from before that is kept
+func (m *Model) reloadInPlace() tea.Cmd {
+	if !m.canRetry() {
+		m.Status = "standard input cannot be reloaded"
+		return nil
+	}
+	s := m.spot
+	if m.Data != nil {
+		s = &spot{Path: append([]string{}, m.Path...), Row: m.CurrC.RowNo, Flat: m.Flat, Filter: m.Filter, Data: m.previous}
+		if len(m.CurrKV) > 0 {
+			s.Key = m.CurrKV[m.CurrC.RowNo].Key
+		}
+	}
+	cmd := m.reload(m.Source, m.Git)
+	m.spot = s
+	return cmd
+}
+
+// returnToSpot goes back to where the listing was before reloading, or as
+// close to it as the new input allows, and says so if it could not get there
+func (m *Model) returnToSpot(s *spot) tea.Cmd {
+	if missing := m.goToNearest(s.Path); missing != "" {
+		m.Status = "reloaded, " + missing
+		return nil
+	}
+	m.Status = "reloaded"
+	if s.Flat {
+		m.Flat, m.Filter = true, s.Filter
+		m.resetCursor()
+		m.updateKV()
+	}
+	m.CurrC.RowNo = s.Row
+	for i, kv := range m.CurrKV {
+		if !m.Flat && kv.Key == s.Key {
+			m.CurrC.RowNo = i
+			break
+		}
+	}
+	m.syncPage()
+	if m.Flat && m.Filter != "" {
+		return m.startSearch()
+	}
+	return nil
+}
+
+// startWatch reloads the input once it changes
+// it is started again after every reload
+func (m *Model) startWatch() tea.Cmd {
+	if !watchInput || m.Git || !m.canRetry() {
+		return nil
+	}
+	source, path := sourceFor(m.Source), m.Source
+	return m.startTask("watch", "", func(ctx context.Context, _ func(int)) tea.Msg {
+		changes, err := source.Watch(ctx, path)
+		if err != nil {
+			return watchMsg{Err: err}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			debugLog.Printf("%s has changed", path)
+			return watchMsg{}
+		}
+	})
+}
+
+// updateWatch reloads the input once it has changed
+func (m *Model) updateWatch(msg watchMsg) tea.Cmd {
+	if msg.Err != nil {
+		m.Status = fmt.Sprintf("cannot watch the input: %s", msg.Err)
+		return nil
+	}
+	return m.reloadInPlace()
+}
@@ -0,0 +1,281 @@
+package jv
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Renderer shows a readable form of string and number values of one kind,
+// such as timestamps or colours, next to the value in the listing
+type Renderer interface {
+	// Kind names the kind of value, such as timestamp
+	Kind() string
+	// Render returns the readable form of the value, a string or a
+	// json.Number, and false if the value is not of the kind
+	Render(value any) (string, bool)
+}
+
+// renderFunc is a renderer made of a kind and a function
+type renderFunc struct {
+	kind   string
+	render func(value any) (string, bool)
+}
+
+// Kind names the kind of value
+func (r renderFunc) Kind() string {
+	return r.kind
+}
+
+// Render returns the readable form of the value
+func (r renderFunc) Render(value any) (string, bool) {
+	return r.render(value)
+}
+
+// renderValues turns the renderers on, it can be set from the command line
+var renderValues = true
+
+// detectIDs turns on recognising UUIDs, digests and colours, which are also
+// shortened in the listing, it can be set from the command line
+var detectIDs = true
+
+// renderers are tried in order on every value and the first of the kind wins
+var renderers = []Renderer{
+	renderFunc{"timestamp", renderTimestamp},
+	renderFunc{"uuid", renderUUID},
+	renderFunc{"hash", renderHash},
+	renderFunc{"color", renderColor},
+	renderFunc{"url", renderURL},
+	renderFunc{"table", renderTable},
+	renderFunc{"markup", renderMarkup},
+	renderFunc{"base64", renderBase64},
+}
+
+// RegisterRenderer adds a renderer that is tried before the built in ones,
+// for programs embedding the viewer that know their own kinds of values
+func RegisterRenderer(r Renderer) {
+	renderers = append([]Renderer{r}, renderers...)
+}
+
+// renderValue is a utility function that returns the readable form of a
+// string or number from the first renderer of its kind, or "" if there is none
+func renderValue(o any) string {
+	if !renderValues {
+		return ""
+	}
+	switch o.(type) {
+	case string, json.Number:
+	default:
+		return ""
+	}
+	for _, r := range renderers {
+		if s, ok := r.Render(o); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// epoch bounds the numbers taken for Unix times to 2001 up to 2100 so that
+// counts and ids are not mistaken for them
+var epoch = struct{ min, max int64 }{978307200, 4102444800}
+
+// renderTimestamp shows RFC 3339 times and Unix times in seconds or
+// milliseconds in the time format, by default as the time in the time zone
+// along with how long ago they were
+func renderTimestamp(value any) (string, bool) {
+	t, ok := parseTimestamp(value)
+	if !ok {
+		return "", false
+	}
+	return formatTimestamp(t, timeFormat), true
+}
+
+// parseTimestamp is a utility function that reads an RFC 3339 time or a
+// Unix time in seconds or milliseconds
+func parseTimestamp(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	case json.Number:
+		n, err := strconv.ParseInt(string(v), 10, 64)
+		switch {
+		case err != nil:
+		case n >= epoch.min && n < epoch.max:
+			return time.Unix(n, 0), true
+		case n >= epoch.min*1000 && n < epoch.max*1000:
+			return time.UnixMilli(n), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// relativeTime is a utility function that describes a duration from now
+// in its largest unit such as 3 days ago or in 2 hours
+func relativeTime(d time.Duration) string {
+	format := "%d %s ago"
+	if d < 0 {
+		d, format = -d, "in %d %s"
+	}
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+	}
+	for _, u := range units {
+		if n := int(d / u.size); n > 0 || u.size == time.Second {
+			name := u.name
+			if n != 1 {
+				name += "s"
+			}
+			return fmt.Sprintf(format, n, name)
+		}
+	}
+	return ""
+}
+
+// uuidPattern matches a UUID in its usual 8-4-4-4-12 form
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-([0-9a-fA-F])[0-9a-fA-F]{3}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// renderUUID shows the version of a UUID
+func renderUUID(value any) (string, bool) {
+	s, _ := value.(string)
+	match := uuidPattern.FindStringSubmatch(s)
+	if match == nil || !detectIDs {
+		return "", false
+	}
+	if strings.Trim(s, "0-") == "" {
+		return "nil UUID", true
+	}
+	return "UUID v" + match[1], true
+}
+
+// hashPattern matches hex digests, optionally prefixed by their algorithm
+// as in sha256:9f86d0…
+var hashPattern = regexp.MustCompile(`^(?i:(md5|sha1|sha224|sha256|sha384|sha512)[:-])?([0-9a-fA-F]+)$`)
+
+// hashNames names the digests by their length in hex digits
+var hashNames = map[int]string{
+	32:  "MD5",
+	40:  "SHA-1",
+	56:  "SHA-224",
+	64:  "SHA-256",
+	96:  "SHA-384",
+	128: "SHA-512",
+}
+
+// renderHash shows the algorithm of a hex digest, told from its prefix or
+// from its length
+// strings of only digits are left alone since they are more likely numbers
+func renderHash(value any) (string, bool) {
+	s, _ := value.(string)
+	match := hashPattern.FindStringSubmatch(s)
+	if match == nil || !detectIDs || strings.Trim(match[2], "0123456789") == "" {
+		return "", false
+	}
+	name, ok := hashNames[len(match[2])]
+	if !ok {
+		return "", false
+	}
+	if match[1] != "" {
+		name = strings.ToUpper(match[1])
+		if i := strings.IndexAny(name, "0123456789"); i > 0 && name != "MD5" {
+			name = name[:i] + "-" + name[i:]
+		}
+	}
+	return name + " digest", true
+}
+
+// shortID is a utility function that shortens a UUID or a digest to its
+// first digits, as git does with commits, and false if the string is neither
+// the full value is shown on the row under the cursor
+func shortID(s string) (string, bool) {
+	if !detectIDs {
+		return "", false
+	}
+	if uuidPattern.MatchString(s) {
+		return s[:8] + "…", true
+	}
+	if _, ok := renderHash(s); ok {
+		match := hashPattern.FindStringSubmatch(s)
+		return strings.TrimSuffix(s, match[2]) + match[2][:12] + "…", true
+	}
+	return "", false
+}
+
+// colorPattern matches colours written as #rgb or #rrggbb
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// renderColor shows a swatch of a hex colour along with its RGB values
+func renderColor(value any) (string, bool) {
+	s, _ := value.(string)
+	if !colorPattern.MatchString(s) || !detectIDs {
+		return "", false
+	}
+	hex := s[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	rgb, _ := strconv.ParseUint(hex, 16, 32)
+	r, g, b := rgb>>16, rgb>>8&0xff, rgb&0xff
+	swatch := ""
+	if useColor {
+		swatch = " " + style("  ", fmt.Sprintf("48;2;%d;%d;%d", r, g, b))
+	}
+	return fmt.Sprintf("rgb(%d, %d, %d)%s", r, g, b, swatch), true
+}
+
+// renderURL shows the host a URL points at
+func renderURL(value any) (string, bool) {
+	s, _ := value.(string)
+	if !isURL(s) {
+		return "", false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	return "link to " + u.Host, true
+}
+
+// base64MinLen is the shortest string taken for base64, shorter ones are
+// too often plain words
+const base64MinLen = 12
+
+// renderBase64 shows the text that a base64 string decodes to, as long as
+// it decodes to printable text
+func renderBase64(value any) (string, bool) {
+	s, _ := value.(string)
+	if len(s) < base64MinLen || len(s)%4 != 0 {
+		return "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		if decoded, err = base64.URLEncoding.DecodeString(s); err != nil {
+			return "", false
+		}
+	}
+	if !utf8.Valid(decoded) {
+		return "", false
+	}
+	text := string(decoded)
+	for _, r := range text {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return "", false
+		}
+	}
+	return "base64 of " + strconv.Quote(truncate(text, 40)), true
+}
@@ -0,0 +1,75 @@
+package jv
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// sampleSize is the number of random elements shown of a sampled array
+var sampleSize = 20
+
+// sampleRand draws the elements of samples, seeded so that every run
+// draws different ones
+var sampleRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// sampleIndices is a utility function that draws k distinct indices below n
+// in order, without making a list of all n of them
+func sampleIndices(n, k int) []int {
+	chosen := map[int]bool{}
+	for j := n - k; j < n; j++ {
+		i := sampleRand.Intn(j + 1)
+		if chosen[i] {
+			i = j
+		}
+		chosen[i] = true
+	}
+	indices := make([]int, 0, k)
+	for i := range chosen {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// sampleArray lists random elements of the current array instead of all of
+// them, drawing new ones every time
+func (m *Model) sampleArray() {
+	if m.Flat || !isArray(m.node()) {
+		m.Status = "only arrays can be sampled"
+		return
+	}
+	n := len(getKAny(m.node()))
+	if n <= sampleSize {
+		m.Status = fmt.Sprintf("the array only has %d elements", n)
+		return
+	}
+	key := pathKey(m.Path)
+	if m.Samples == nil {
+		m.Samples = map[string][]int{}
+	}
+	m.Samples[key] = sampleIndices(n, sampleSize)
+	delete(m.KVCache, key)
+	m.resetCursor()
+	m.updateKV()
+	m.syncPage()
+	m.Status = fmt.Sprintf("%d random elements of %d, %s draws again", sampleSize, n, m.Keys.Sample.Help().Key)
+}
+
+// sampleKV returns the key-value pairs of the sampled elements of an array
+// a pseudo-row is added at the end that lists the array in order again
+func (m *Model) sampleKV(o any, indices []int) []KVPair {
+	children := getKAny(o)
+	kvpairs := []KVPair{}
+	for _, i := range indices {
+		k := strconv.Itoa(i)
+		kvpairs = append(kvpairs, KVPair{Key: k, Value: getVal(children[k]), Index: true})
+	}
+	return append(kvpairs, KVPair{
+		Key:   "…",
+		Value: fmt.Sprintf("%d random elements of %d, list them in order", len(indices), len(children)),
+		More:  true,
+	})
+}
@@ -0,0 +1,67 @@
+package jv
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sanitize is a utility function that escapes control characters, line
+// separators and invalid UTF-8 so that a key or value cannot corrupt
+// the terminal layout
+func sanitize(s string) string {
+	clean := true
+	for _, r := range s {
+		if r == utf8.RuneError || unicode.IsControl(r) || r == '\u2028' || r == '\u2029' {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x80 && unicode.IsControl(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case unicode.IsControl(r) || r == '\u2028' || r == '\u2029':
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// rawBytes is a utility function that returns the bytes of a value
+// exactly as they appear in the input
+// values that have already been parsed are encoded again
+func rawBytes(o any) []byte {
+	switch val := o.(type) {
+	case json.RawMessage:
+		return val
+	case fileSpan:
+		return val.bytes()
+	}
+	content, _ := json.Marshal(o)
+	return content
+}
+
+// rawBytesString returns a hex dump of the bytes of a value
+func rawBytesString(o any) string {
+	return hex.Dump(rawBytes(o))
+}
@@ -0,0 +1,154 @@
+package jv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sbomFormat returns "SPDX" or "CycloneDX" if the data is an SBOM of that format
+func sbomFormat(data any) string {
+	root := getKAny(data)
+	switch {
+	case root == nil || isArray(data):
+		return ""
+	case stringAt(root, "spdxVersion") != "":
+		return "SPDX"
+	case stringAt(root, "bomFormat") == "CycloneDX":
+		return "CycloneDX"
+	}
+	return ""
+}
+
+// sbomPackage holds the columns of a package in the table
+type sbomPackage struct {
+	Name    string
+	Version string
+	License string
+}
+
+// spdxPackage reads a package of an SPDX document
+func spdxPackage(pkg map[string]any) sbomPackage {
+	license := stringAt(pkg, "licenseConcluded")
+	if license == "" || license == "NOASSERTION" {
+		license = stringAt(pkg, "licenseDeclared")
+	}
+	return sbomPackage{Name: stringAt(pkg, "name"), Version: stringAt(pkg, "versionInfo"), License: license}
+}
+
+// cycloneDXPackage reads a component of a CycloneDX document, whose licenses
+// are a list of ids, names or expressions
+func cycloneDXPackage(component map[string]any) sbomPackage {
+	licenses := []string{}
+	list, _ := materialize(component["licenses"]).([]any)
+	for _, l := range list {
+		l, _ := l.(map[string]any)
+		if expr, ok := l["expression"].(string); ok {
+			licenses = append(licenses, expr)
+			continue
+		}
+		license, _ := l["license"].(map[string]any)
+		if id, ok := license["id"].(string); ok {
+			licenses = append(licenses, id)
+		} else if name, ok := license["name"].(string); ok {
+			licenses = append(licenses, name)
+		}
+	}
+	return sbomPackage{
+		Name:    stringAt(component, "name"),
+		Version: stringAt(component, "version"),
+		License: strings.Join(licenses, " AND "),
+	}
+}
+
+// summary formats the package as a row like name@version  license
+func (p sbomPackage) summary() string {
+	s := p.Name
+	if p.Version != "" {
+		s += "@" + p.Version
+	}
+	if p.License != "" {
+		s += "  " + p.License
+	}
+	return s
+}
+
+// sbomSummary returns the summary row of a package in an SBOM's package list
+func (m *Model) sbomSummary(kv KVPair) (string, bool) {
+	if kv.More || kv.Path != nil || kv.Value != "{}" {
+		return "", false
+	}
+	lvl := m.currentLevel()
+	switch lvl.sbom {
+	case "SPDX":
+		return spdxPackage(getKAny(lvl.children[kv.Key])).summary(), true
+	case "CycloneDX":
+		return cycloneDXPackage(getKAny(lvl.children[kv.Key])).summary(), true
+	}
+	return "", false
+}
+
+// sbomList returns the format of the SBOM whose packages are listed, or ""
+// if the current level is not a package list of an SBOM
+func (m *Model) sbomList() string {
+	if len(m.Path) == 0 {
+		return ""
+	}
+	want := map[string]string{"packages": "SPDX", "components": "CycloneDX"}[m.Path[len(m.Path)-1]]
+	if want == "" || sbomFormat(m.Data) != want {
+		return ""
+	}
+	return want
+}
+
+// sbomPackages is a utility function that returns every package in an SBOM
+// including the components nested in CycloneDX components
+func sbomPackages(data any) []sbomPackage {
+	packages := []sbomPackage{}
+	switch sbomFormat(data) {
+	case "SPDX":
+		for _, pkg := range getKAny(getKAny(data)["packages"]) {
+			packages = append(packages, spdxPackage(getKAny(pkg)))
+		}
+	case "CycloneDX":
+		var walk func(components any)
+		walk = func(components any) {
+			for _, c := range getKAny(components) {
+				component := getKAny(c)
+				packages = append(packages, cycloneDXPackage(component))
+				walk(component["components"])
+			}
+		}
+		walk(getKAny(data)["components"])
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+	return packages
+}
+
+// sbomTableString is a utility function that lays out the packages as a
+// table of name, version and license
+func sbomTableString(packages []sbomPackage) string {
+	if len(packages) == 0 {
+		return "(no packages)"
+	}
+	nameWidth, versionWidth := len("NAME"), len("VERSION")
+	for _, p := range packages {
+		if n := len(sanitize(p.Name)); n > nameWidth {
+			nameWidth = n
+		}
+		if n := len(sanitize(p.Version)); n > versionWidth {
+			versionWidth = n
+		}
+	}
+	row := fmt.Sprintf("%%-%ds  %%-%ds  %%s", nameWidth, versionWidth)
+	lines := []string{fmt.Sprintf(row, "NAME", "VERSION", "LICENSE")}
+	for _, p := range packages {
+		lines = append(lines, strings.TrimRight(fmt.Sprintf(row, sanitize(p.Name), sanitize(p.Version), sanitize(p.License)), " "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,138 @@
+package jv
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// inferSchema is a utility function that infers a JSON Schema
+// describing the given any which must already be materialized
+func inferSchema(o any) map[string]any {
+	switch val := o.(type) {
+	case map[string]any:
+		props := map[string]any{}
+		required := []string{}
+		for k, v := range val {
+			props[k] = inferSchema(v)
+			required = append(required, k)
+		}
+		sort.Strings(required)
+		return map[string]any{"type": "object", "properties": props, "required": required}
+	case []any:
+		s := map[string]any{"type": "array"}
+		// every element contributes to the schema of the items
+		if len(val) > 0 {
+			items := inferSchema(val[0])
+			for _, v := range val[1:] {
+				items = mergeSchema(items, inferSchema(v))
+			}
+			s["items"] = items
+		}
+		return s
+	case string:
+		return map[string]any{"type": "string"}
+	case json.Number:
+		if !strings.ContainsAny(string(val), ".eE") {
+			return map[string]any{"type": "integer"}
+		}
+		return map[string]any{"type": "number"}
+	case float64:
+		if val == math.Trunc(val) {
+			return map[string]any{"type": "integer"}
+		}
+		return map[string]any{"type": "number"}
+	case bool:
+		return map[string]any{"type": "boolean"}
+	}
+	return map[string]any{"type": "null"}
+}
+
+// mergeSchema is a utility function that combines two inferred schemas
+// into one that accepts values matching either of them
+func mergeSchema(a, b map[string]any) map[string]any {
+	if reflect.DeepEqual(a, b) {
+		return a
+	}
+	ta, tb := a["type"], b["type"]
+	// integers are also numbers
+	if (ta == "integer" && tb == "number") || (ta == "number" && tb == "integer") {
+		return map[string]any{"type": "number"}
+	}
+	if ta == "object" && tb == "object" {
+		pa := a["properties"].(map[string]any)
+		pb := b["properties"].(map[string]any)
+		props := map[string]any{}
+		for k, v := range pa {
+			props[k] = v
+		}
+		for k, v := range pb {
+			if prev, ok := props[k]; ok {
+				props[k] = mergeSchema(prev.(map[string]any), v.(map[string]any))
+			} else {
+				props[k] = v
+			}
+		}
+		// only keys seen in both objects stay required
+		required := []string{}
+		for _, k := range a["required"].([]string) {
+			if _, ok := pb[k]; ok {
+				required = append(required, k)
+			}
+		}
+		return map[string]any{"type": "object", "properties": props, "required": required}
+	}
+	if ta == "array" && tb == "array" {
+		ia, oka := a["items"].(map[string]any)
+		ib, okb := b["items"].(map[string]any)
+		switch {
+		case oka && okb:
+			return map[string]any{"type": "array", "items": mergeSchema(ia, ib)}
+		case oka:
+			return a
+		default:
+			return b
+		}
+	}
+	// the types are incompatible so accept any of them
+	options := []any{}
+	for _, s := range []map[string]any{a, b} {
+		if opts, ok := s["anyOf"].([]any); ok {
+			options = append(options, opts...)
+		} else {
+			options = append(options, s)
+		}
+	}
+	return map[string]any{"anyOf": dedupSchemas(options)}
+}
+
+// dedupSchemas removes repeated schemas from a list of options
+func dedupSchemas(options []any) []any {
+	unique := []any{}
+	for _, o := range options {
+		seen := false
+		for _, u := range unique {
+			if reflect.DeepEqual(o, u) {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			unique = append(unique, o)
+		}
+	}
+	return unique
+}
+
+// schemaString returns the inferred JSON Schema of the given any as indented JSON
+func schemaString(o any) string {
+	s := inferSchema(materialize(o))
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(content)
+}
@@ -0,0 +1,170 @@
+package jv
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchMsg carries the leaves matching a search query
+type searchMsg struct {
+	Query   string   // the query that was searched for
+	Results []KVPair // leaves whose path or value contains the query
+}
+
+// searchFound holds the leaves a search has found so far so that they can
+// be shown before it finishes
+type searchFound struct {
+	mu     sync.Mutex
+	query  string
+	leaves []KVPair
+}
+
+// add records a leaf found and returns how many have been found
+func (f *searchFound) add(kv KVPair) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.leaves = append(f.leaves, kv)
+	return len(f.leaves)
+}
+
+// sorted returns a sorted copy of the leaves found so far
+func (f *searchFound) sorted() []KVPair {
+	f.mu.Lock()
+	leaves := append([]KVPair{}, f.leaves...)
+	f.mu.Unlock()
+	sortLeaves(leaves)
+	return leaves
+}
+
+// matchesQuery checks if the leaf's path or value contains the lower-cased query
+func matchesQuery(kv KVPair, query string) bool {
+	return strings.Contains(strings.ToLower(kv.Key), query) ||
+		strings.Contains(strings.ToLower(kv.Value), query)
+}
+
+// searchLeaves searches every leaf in the document for the query using one
+// worker per CPU and stops early if ctx is cancelled
+// if the document has been indexed the index is split between the workers,
+// otherwise the top level subtrees are walked in parallel
+// found, if not nil, gets every match as soon as it is found and progress is
+// called with how many there are so far
+func searchLeaves(ctx context.Context, data any, index []KVPair, query string, found *searchFound, progress func(int)) tea.Msg {
+	lower := strings.ToLower(query)
+	jobs := make(chan func(visit func(KVPair) bool))
+	results := make(chan []KVPair)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			matches := []KVPair{}
+			for job := range jobs {
+				job(func(kv KVPair) bool {
+					if matchesQuery(kv, lower) {
+						matches = append(matches, kv)
+						if found != nil {
+							progress(found.add(kv))
+						}
+					}
+					return ctx.Err() == nil
+				})
+			}
+			results <- matches
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		if index != nil {
+			chunk := len(index)/runtime.NumCPU() + 1
+			for start := 0; start < len(index); start += chunk {
+				end := start + chunk
+				if end > len(index) {
+					end = len(index)
+				}
+				part := index[start:end]
+				jobs <- func(visit func(KVPair) bool) {
+					for _, kv := range part {
+						if !visit(kv) {
+							return
+						}
+					}
+				}
+			}
+			return
+		}
+		for k, v := range getKAny(data) {
+			k, v := k, v
+			jobs <- func(visit func(KVPair) bool) {
+				walkLeaves(data, v, []string{k}, visit)
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	matches := []KVPair{}
+	for found := range results {
+		matches = append(matches, found...)
+	}
+	// a newer query has been typed so these results are not needed
+	if ctx.Err() != nil {
+		return nil
+	}
+	sortLeaves(matches)
+	return searchMsg{Query: query, Results: matches}
+}
+
+// startSearch cancels any search in progress and starts searching for the filter
+func (m *Model) startSearch() tea.Cmd {
+	data, index, query := m.Data, m.Index, m.Filter
+	found := &searchFound{query: query}
+	m.found = found
+	return m.startTask("search", "searching", func(ctx context.Context, progress func(int)) tea.Msg {
+		return searchLeaves(ctx, data, index, query, found, progress)
+	})
+}
+
+// updateSearch shows the results of the latest search
+func (m *Model) updateSearch(msg searchMsg) {
+	if !m.Flat || msg.Query != m.Filter {
+		return
+	}
+	debugLog.Printf("search for %q found %d leaves", msg.Query, len(msg.Results))
+	m.found = nil
+	m.showResults(msg.Query, msg.Results)
+}
+
+// showFound lists the leaves the search in progress has found so far, they
+// can be gone through while it goes on
+func (m *Model) showFound() {
+	if m.found == nil || !m.Flat || m.found.query != m.Filter {
+		return
+	}
+	m.showResults(m.found.query, m.found.sorted())
+}
+
+// showResults lists the leaves found for a query, keeping the cursor on the
+// same leaf when more are found for the query already listed
+func (m *Model) showResults(query string, results []KVPair) {
+	var at []string
+	if m.listedQuery == query && len(m.CurrKV) > 0 {
+		at = m.CurrKV[m.CurrC.RowNo].Path
+	}
+	m.CurrKV, m.listedQuery = results, query
+	m.rowCache = nil
+	m.resetCursor()
+	if at != nil {
+		for i, kv := range results {
+			if pathKey(kv.Path) == pathKey(at) {
+				m.CurrC.RowNo = i
+				break
+			}
+		}
+	}
+	m.syncPage()
+}
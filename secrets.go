@@ -0,0 +1,204 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maskSecrets starts the viewer with likely credentials masked, it can be
+// set from the command line
+var maskSecrets bool
+
+// secretMask is shown and copied in place of a masked value
+const secretMask = "••••••••"
+
+// secretKey matches keys whose values are likely credentials
+var secretKey = regexp.MustCompile(`(?i)(passw(or)?d|passphrase|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential)`)
+
+// secretValues match strings that are likely credentials whatever their key
+var secretValues = []*regexp.Regexp{
+	// JSON Web Tokens are three base64url parts, the first a JSON object
+	regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`),
+	// AWS access key ids
+	regexp.MustCompile(`^(AKIA|ASIA|AGPA|AIDA|AROA|ANPA)[A-Z0-9]{16}$`),
+	// PEM private keys
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+}
+
+// isSecret is a utility function that reports whether a string or number
+// is likely a credential, from its key or from what it looks like
+// containers are never secrets themselves, only the values in them
+func isSecret(key string, o any) bool {
+	switch v := o.(type) {
+	case json.Number:
+		return secretKey.MatchString(key)
+	case string:
+		if v == "" {
+			return false
+		}
+		if secretKey.MatchString(key) {
+			return true
+		}
+		for _, re := range secretValues {
+			if re.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// masked reports whether the value at the path is shown and copied masked
+// revealing a container reveals everything in it
+func (m *Model) masked(path []string) bool {
+	if !m.Masking {
+		return false
+	}
+	if len(m.Revealed) == 0 {
+		return true
+	}
+	for i := len(path); i >= 0; i-- {
+		if m.Revealed[pathKey(path[:i])] {
+			return false
+		}
+	}
+	return true
+}
+
+// maskedAt reports whether the value at the path is a secret that is masked
+func (m *Model) maskedAt(path []string) bool {
+	if len(path) == 0 || !m.masked(path) {
+		return false
+	}
+	return isSecret(path[len(path)-1], materializeScalar(m.valueAt(path)))
+}
+
+// maskValue returns a copy of a fully parsed value at the path with the
+// secrets in it masked, unless they have been revealed
+func (m *Model) maskValue(path []string, o any) any {
+	if !m.masked(path) {
+		return o
+	}
+	child := func(k string) []string {
+		return append(append([]string{}, path...), k)
+	}
+	switch v := o.(type) {
+	case map[string]any:
+		masked := make(map[string]any, len(v))
+		for k, val := range v {
+			masked[k] = m.maskValue(child(k), val)
+		}
+		return masked
+	case []any:
+		masked := make([]any, len(v))
+		for i, val := range v {
+			masked[i] = m.maskValue(child(fmt.Sprint(i)), val)
+		}
+		return masked
+	}
+	if len(path) > 0 && isSecret(path[len(path)-1], o) {
+		return secretMask
+	}
+	return o
+}
+
+// maskChanges returns the differences between two documents with the
+// secrets in them masked
+func (m *Model) maskChanges(changes []change) []change {
+	if !m.Masking {
+		return changes
+	}
+	masked := make([]change, len(changes))
+	for i, c := range changes {
+		path := make([]string, len(c.Path))
+		for j, k := range c.Path {
+			path[j] = fmt.Sprint(k)
+		}
+		c.Old, c.New = m.maskValue(path, c.Old), m.maskValue(path, c.New)
+		masked[i] = c
+	}
+	return masked
+}
+
+// exportValue returns the value at the path fully parsed, as it is copied or
+// handed to other programs, with its secrets masked
+func (m *Model) exportValue(path []string) any {
+	return m.maskValue(path, materialize(m.valueAt(path)))
+}
+
+// toggleMasking masks likely credentials or shows them all again
+func (m *Model) toggleMasking() {
+	m.Masking, m.Revealed = !m.Masking, nil
+	if m.Masking {
+		m.Status = fmt.Sprintf("masking secrets, %s reveals the value under the cursor", m.Keys.Reveal.Help().Key)
+		return
+	}
+	m.Status = "showing secrets"
+}
+
+// toggleReveal shows the masked secrets in the value under the cursor, or
+// masks them again
+func (m *Model) toggleReveal() {
+	if len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return
+	}
+	if !m.Masking {
+		m.Status = fmt.Sprintf("secrets are not masked, %s masks them", m.Keys.Mask.Help().Key)
+		return
+	}
+	path := m.currentPath()
+	key := pathKey(path)
+	if m.Revealed[key] {
+		delete(m.Revealed, key)
+		m.Status = "masked " + selectorPath(m.Data, path)
+		return
+	}
+	if m.Revealed == nil {
+		m.Revealed = map[string]bool{}
+	}
+	m.Revealed[key] = true
+	m.Status = "revealed " + selectorPath(m.Data, path)
+}
+
+// secretPlaceholder is a utility function that returns a value as long as
+// a secret and of the same type, strings of asterisks and numbers of nines
+func secretPlaceholder(o any) any {
+	switch v := o.(type) {
+	case string:
+		return strings.Repeat("*", utf8.RuneCountInString(v))
+	case json.Number:
+		digits := 0
+		for _, r := range string(v) {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		return json.Number(strings.Repeat("9", digits))
+	}
+	return o
+}
+
+// exportFileName is a utility function that returns the file something
+// made from the input is written to, named after the input with the suffix
+// such as masked.json
+func exportFileName(source, suffix string) string {
+	base := filepath.Base(source)
+	if source == "" || base == "." || base == "/" {
+		return suffix
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "." + suffix
+}
+
+// exportMasked writes a copy of the whole document with its secrets masked
+// to a file in the current directory, whether or not they are masked on
+// screen
+// it is written in the background since the document can be huge
+func (m *Model) exportMasked() tea.Cmd {
+	return m.streamExport([]string{}, exportFileName(m.Source, "masked.json"), true)
+}
@@ -0,0 +1,277 @@
+package jv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// serveSearchLimit is the most search results sent to the browser
+const serveSearchLimit = 500
+
+// serveRow is a row of the listing as it is sent to the browser
+type serveRow struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Path      string `json:"path,omitempty"`      // selector of the value, empty for the row loading more elements
+	Container bool   `json:"container,omitempty"` // the value is an object or array that can be opened
+	Shown     int    `json:"shown,omitempty"`     // elements to show once the row loading more is clicked
+}
+
+// serveCrumb is a level along the path to the listing
+type serveCrumb struct {
+	Key  string `json:"key"`
+	Path string `json:"path"`
+}
+
+// server answers the browser with the listing of the document
+// a model is set up for every request so that they share nothing but
+// the data, the lock keeps the lazily parsed data from being read twice
+// at the same time
+type server struct {
+	data any
+	sort string
+	lock sync.Mutex
+}
+
+// serve loads the input and serves a read-only browser view of it at addr
+// until the program is interrupted
+func serve(addr, path string, git bool, order string) error {
+	data, _, err := readJsonSource(context.Background(), &loadProgress{}, path, git)
+	if err != nil {
+		return err
+	}
+	// browsers show the rows as they are without terminal styling
+	useColor = false
+	s := &server{data: data, sort: order}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.index)
+	mux.HandleFunc("/api/node", s.node)
+	mux.HandleFunc("/api/value", s.value)
+	mux.HandleFunc("/api/search", s.search)
+	source := path
+	if source == "" {
+		source = "stdin"
+	}
+	fmt.Fprintf(os.Stderr, "jv: serving %s on http://%s\n", source, addr)
+	debugLog.Printf("serving %s on %s", source, addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		return fmt.Errorf("cannot serve: %w", err)
+	}
+	return nil
+}
+
+// model returns a model opened at the path of the selector
+// shown is how many elements of an array to list, 0 for the default
+func (s *server) model(selector string, shown int) (*Model, error) {
+	path := []string{}
+	if selector != "" && selector != "." {
+		var err error
+		if path, err = selectorKeys(selector); err != nil {
+			return nil, fmt.Errorf("invalid path %s: %w", selector, err)
+		}
+	}
+	m := NewModel("")
+	m.Loading = false
+	m.Sort = s.sort
+	m.Data = s.data
+	m.Nodes = []*Node{NewNode(s.data)}
+	if err := m.checkPath(path); err != nil {
+		return nil, err
+	}
+	if shown > 0 {
+		m.Shown[pathKey(path)] = shown
+	}
+	m.setPath(path)
+	m.updateKV()
+	return m, nil
+}
+
+// node sends the rows of the object or array at the path
+func (s *server) node(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	shown, _ := strconv.Atoi(r.URL.Query().Get("shown"))
+	m, err := s.model(r.URL.Query().Get("path"), shown)
+	if err != nil {
+		serveError(w, http.StatusNotFound, err)
+		return
+	}
+	if getKAny(m.node()) == nil {
+		serveError(w, http.StatusBadRequest, fmt.Errorf("%s is not an object or array", selectorPath(m.Data, m.Path)))
+		return
+	}
+	crumbs := []serveCrumb{{Key: "(root)", Path: "."}}
+	for i := range m.Path {
+		crumbs = append(crumbs, serveCrumb{Key: m.Path[i], Path: selectorPath(m.Data, m.Path[:i+1])})
+	}
+	rows := []serveRow{}
+	for _, kv := range m.CurrKV {
+		row := serveRow{Key: m.displayKey(kv), Value: m.displayValue(kv)}
+		if kv.More {
+			row.Shown = len(m.CurrKV) - 1 + arrayPageSize
+		} else {
+			row.Path = selectorPath(m.Data, m.currentPathOf(kv))
+			row.Container = kv.Value == "{}" || kv.Value == "[]"
+		}
+		rows = append(rows, row)
+	}
+	serveJson(w, map[string]any{"path": selectorPath(m.Data, m.Path), "crumbs": crumbs, "rows": rows})
+}
+
+// value sends the value at the path as indented JSON
+func (s *server) value(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	m, err := s.model(r.URL.Query().Get("path"), 0)
+	if err != nil {
+		serveError(w, http.StatusNotFound, err)
+		return
+	}
+	content, err := json.MarshalIndent(m.exportValue(m.Path), "", "  ")
+	if err != nil {
+		serveError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(content)
+}
+
+// search sends the leaves whose path or value contains the query
+func (s *server) search(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	query := r.URL.Query().Get("q")
+	msg, _ := searchLeaves(r.Context(), s.data, nil, query, nil, nil).(searchMsg)
+	rows := []serveRow{}
+	for _, kv := range msg.Results {
+		if len(rows) == serveSearchLimit {
+			break
+		}
+		value := kv.Value
+		if maskSecrets && len(kv.Path) > 0 && isSecret(kv.Path[len(kv.Path)-1], value) {
+			value = secretMask
+		}
+		rows = append(rows, serveRow{Key: sanitize(kv.Key), Value: truncate(sanitize(value), limits.ValueLen), Path: kv.Key})
+	}
+	serveJson(w, map[string]any{"query": query, "total": len(msg.Results), "rows": rows})
+}
+
+// index sends the page that browses the document
+func (s *server) index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, serveIndex)
+}
+
+// serveJson is a utility function that sends v as JSON
+func serveJson(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		debugLog.Printf("cannot send response: %s", err)
+	}
+}
+
+// serveError is a utility function that sends an error as JSON
+func serveError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// serveIndex is the page that browses the document through the api
+// the path is kept in the fragment so a view can be shared as a link
+const serveIndex = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>jv</title>
+<style>
+body { font-family: monospace; margin: 1em 2em; }
+#crumbs a, .row a { cursor: pointer; color: #0645ad; }
+.row { white-space: pre; padding: 1px 0; }
+.key { font-weight: bold; }
+#value { background: #f4f4f4; padding: 1em; white-space: pre-wrap; }
+#error { color: #b00; }
+</style>
+</head>
+<body>
+<input id="search" placeholder="search paths and values" size="40">
+<p>You are here: <span id="crumbs"></span></p>
+<p id="error"></p>
+<div id="rows"></div>
+<pre id="value" hidden></pre>
+<script>
+function el(tag, text, cls) {
+  var e = document.createElement(tag);
+  e.textContent = text;
+  if (cls) e.className = cls;
+  return e;
+}
+function get(url, text, done) {
+  fetch(url).then(function (r) {
+    if (!r.ok) return r.json().then(function (e) { throw new Error(e.error); });
+    return text ? r.text() : r.json();
+  }).then(done).catch(function (e) { document.getElementById("error").textContent = e.message; });
+}
+function showRows(rows) {
+  var list = document.getElementById("rows");
+  list.replaceChildren();
+  rows.forEach(function (row) {
+    var div = el("div", "", "row");
+    var key = el(row.path || row.shown ? "a" : "span", row.key, "key");
+    key.onclick = function () {
+      if (row.shown) open(current, row.shown);
+      else if (row.container) location.hash = row.path;
+      else showValue(row.path);
+    };
+    div.append(key, ": " + row.value);
+    list.append(div);
+  });
+}
+function showValue(path) {
+  get("/api/value?path=" + encodeURIComponent(path), true, function (text) {
+    var pre = document.getElementById("value");
+    pre.textContent = path + "\n\n" + text;
+    pre.hidden = false;
+  });
+}
+var current = ".";
+function open(path, shown) {
+  current = path;
+  document.getElementById("error").textContent = "";
+  document.getElementById("value").hidden = true;
+  get("/api/node?path=" + encodeURIComponent(path) + (shown ? "&shown=" + shown : ""), false, function (node) {
+    var crumbs = document.getElementById("crumbs");
+    crumbs.replaceChildren();
+    node.crumbs.forEach(function (c, i) {
+      var a = el("a", c.key);
+      a.onclick = function () { location.hash = c.path; };
+      if (i > 0) crumbs.append(" › ");
+      crumbs.append(a);
+    });
+    showRows(node.rows);
+  });
+}
+document.getElementById("search").onkeydown = function (e) {
+  if (e.key != "Enter") return;
+  if (this.value == "") { open(current); return; }
+  get("/api/search?q=" + encodeURIComponent(this.value), false, function (res) {
+    document.getElementById("value").hidden = true;
+    document.getElementById("crumbs").textContent = res.total + " leaves match " + res.query;
+    showRows(res.rows);
+  });
+};
+window.onhashchange = function () { open(decodeURIComponent(location.hash.slice(1)) || "."); };
+window.onhashchange();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,175 @@
+package jv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// restoreSessions opens inputs where they were left the last time, it is
+// turned off with -no-restore
+var restoreSessions = true
+
+// sessionLimit is the most sessions kept, the ones used longest ago go first
+const sessionLimit = 200
+
+// session is where an input was left when it was last closed
+type session struct {
+	Path   []string `json:"path"`   // path of the listing
+	Row    int      `json:"row"`    // row the cursor was on
+	Sort   string   `json:"sort"`   // order object keys were listed in
+	Flat   bool     `json:"flat"`   // the flattened view was shown
+	Filter string   `json:"filter"` // filter of the flattened view
+}
+
+// stateDir is a utility function that returns where jv keeps what it
+// remembers between runs, following the XDG base directory convention
+func stateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "jv")
+}
+
+// writeState is a utility function that writes a file under stateDir that
+// only the user can read, since what jv remembers tells what they opened
+// files and directories written by earlier versions are made private too
+func writeState(file string, content []byte) error {
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, content, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(file, 0600)
+}
+
+// sessionDir is a utility function that returns where sessions are kept
+func sessionDir() string {
+	dir := stateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "sessions")
+}
+
+// sessionKey is a utility function that returns the hash sessions of the
+// input are kept under
+// streamed files are too large to read a second time so they are told
+// apart by their path, size and modification time instead
+func sessionKey(source string, data any) string {
+	h := sha256.New()
+	if _, ok := data.(fileSpan); ok {
+		info, err := os.Stat(source)
+		if err != nil {
+			return ""
+		}
+		abs, _ := filepath.Abs(source)
+		fmt.Fprintf(h, "%s\x00%d\x00%d", abs, info.Size(), info.ModTime().UnixNano())
+	} else {
+		h.Write(rawBytes(data))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// restoreSession opens the listing where it was left the last time the
+// input was closed, as long as that place is still there
+// it returns the search to run if the flattened view was filtered
+func (m *Model) restoreSession() tea.Cmd {
+	dir := sessionDir()
+	if !restoreSessions || dir == "" || m.sessionKey == "" {
+		return nil
+	}
+	content, err := os.ReadFile(filepath.Join(dir, m.sessionKey+".json"))
+	if err != nil {
+		return nil
+	}
+	var s session
+	if err := json.Unmarshal(content, &s); err != nil {
+		debugLog.Printf("cannot read session: %s", err)
+		return nil
+	}
+	debugLog.Printf("restoring session %s", m.sessionKey)
+	if checkOrder(s.Sort) == nil && s.Sort != m.Sort {
+		m.Sort = s.Sort
+		m.KVCache = map[string][]KVPair{}
+	}
+	// the input changed since it was left, the listing opens as close to
+	// where it was as it can
+	if missing := m.goToNearest(s.Path); missing != "" {
+		m.Status = "restored as close to where this input was left as it allows, " + missing
+		return nil
+	}
+	m.Flat, m.Filter = s.Flat, s.Filter
+	if m.Flat {
+		m.resetCursor()
+	}
+	m.updateKV()
+	m.CurrC.RowNo = s.Row
+	m.syncPage()
+	m.Status = "restored where this input was left, -no-restore opens it at the top"
+	if m.Flat && m.Filter != "" {
+		return m.startSearch()
+	}
+	return nil
+}
+
+// saveSession records where the listing is so that it opens there the
+// next time the input is opened
+// it is best effort so failing to save is only logged
+func (m *Model) saveSession() {
+	dir := sessionDir()
+	if dir == "" || m.sessionKey == "" || m.Data == nil || m.Scalar {
+		return
+	}
+	file := filepath.Join(dir, m.sessionKey+".json")
+	s := session{Path: m.Path, Row: m.CurrC.RowNo, Sort: m.Sort, Flat: m.Flat, Filter: m.Filter}
+	// there is nothing to restore when the input was left at the top
+	if len(s.Path) == 0 && s.Row == 0 && !s.Flat {
+		os.Remove(file)
+		return
+	}
+	content, err := json.Marshal(s)
+	if err == nil {
+		err = writeState(file, content)
+	}
+	if err != nil {
+		debugLog.Printf("cannot save session: %s", err)
+		return
+	}
+	pruneSessions(dir)
+}
+
+// pruneSessions is a utility function that removes the sessions used
+// longest ago once there are more than sessionLimit
+func pruneSessions(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) <= sessionLimit {
+		return
+	}
+	infos := []os.FileInfo{}
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().After(infos[j].ModTime()) })
+	for i := sessionLimit; i < len(infos); i++ {
+		os.Remove(filepath.Join(dir, infos[i].Name()))
+	}
+}
@@ -0,0 +1,114 @@
+package jv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// parseSlice is a utility function that reads a range of an array of n
+// elements written like jq as start:end, where either can be left out and
+// negative numbers count from the end
+func parseSlice(s string, n int) (int, int, error) {
+	first, last, ok := strings.Cut(strings.Trim(strings.TrimSpace(s), "[]"), ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected start:end such as 100:200")
+	}
+	bound := func(s string, fallback int) (int, error) {
+		if s = strings.TrimSpace(s); s == "" {
+			return fallback, nil
+		}
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not an index", s)
+		}
+		if i < 0 {
+			i += n
+		}
+		if i < 0 {
+			i = 0
+		}
+		if i > n {
+			i = n
+		}
+		return i, nil
+	}
+	start, err := bound(first, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := bound(last, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start >= end {
+		return 0, 0, fmt.Errorf("%d:%d has no elements", start, end)
+	}
+	return start, end, nil
+}
+
+// openSlice starts typing the range of the current array to list
+// the range counts from the start of the whole array, not of its slice
+func (m *Model) openSlice() {
+	if m.Flat || !isArray(m.node()) {
+		m.Status = "only arrays can be sliced"
+		return
+	}
+	m.Slicing, m.SliceInput = true, ""
+}
+
+// updateSlice handles typing the range of the current array to list
+// an empty range lists the whole array again
+func (m *Model) updateSlice(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.Slicing = false
+	case tea.KeyEnter:
+		key := pathKey(m.Path)
+		if strings.TrimSpace(m.SliceInput) == "" {
+			delete(m.Slices, key)
+		} else {
+			start, end, err := parseSlice(m.SliceInput, len(getKAny(m.node())))
+			if err != nil {
+				m.Status = fmt.Sprintf("cannot slice: %s", err)
+				return m, nil
+			}
+			if m.Slices == nil {
+				m.Slices = map[string][2]int{}
+			}
+			m.Slices[key] = [2]int{start, end}
+		}
+		m.Slicing = false
+		delete(m.Samples, key)
+		delete(m.Shown, key)
+		delete(m.KVCache, key)
+		m.resetCursor()
+		m.updateKV()
+		m.syncPage()
+	case tea.KeyBackspace:
+		if len(m.SliceInput) > 0 {
+			runes := []rune(m.SliceInput)
+			m.SliceInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes:
+		m.SliceInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// listingSelector returns the selector of the current listing, with the
+// range of the array if it is sliced, such as .items[100:200]
+func (m *Model) listingSelector() string {
+	s := selectorPath(m.Data, m.Path)
+	if r, ok := m.Slices[pathKey(m.Path)]; ok && !m.Flat {
+		s = strings.TrimSuffix(s, ".") + fmt.Sprintf("[%d:%d]", r[0], r[1])
+		if !strings.HasPrefix(s, ".") {
+			s = "." + s
+		}
+	}
+	return s
+}
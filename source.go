@@ -0,0 +1,372 @@
+package jv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Source is somewhere input can be read from, such as a file, a URL or the
+// output of a command
+// the source is picked by the path given on the command line
+type Source interface {
+	// Detect reports whether the source reads the input at path
+	Detect(path string) bool
+	// Open starts reading the input at path, along with its size in bytes
+	// if it is known or 0 if not
+	Open(ctx context.Context, path string) (io.ReadCloser, int64, error)
+	// Watch sends on the channel whenever the input at path may have
+	// changed until ctx is done
+	// sources that cannot tell return errNoWatch
+	Watch(ctx context.Context, path string) (<-chan struct{}, error)
+}
+
+// sourceReader is a source that parses its input itself rather than
+// having it read into memory and parsed, such as files that are streamed
+type sourceReader interface {
+	read(ctx context.Context, progress *loadProgress, path string) (any, map[string]string, error)
+}
+
+// errNoWatch is returned by sources that cannot tell when their input changes
+var errNoWatch = errors.New("the input cannot be watched for changes")
+
+// watchInterval is how often watched files are checked for changes
+const watchInterval = time.Second
+
+// sources are the sources paths are checked against in order
+// files come last since any path can be a file
+var sources = []Source{
+	stdinSource{},
+	urlSource{},
+	objectSource{},
+	execSource{},
+	clipboardSource{},
+	archiveSource{},
+	fileSource{},
+}
+
+// RegisterSource adds a source that is checked before the built in ones,
+// for programs embedding the viewer that read input from elsewhere
+func RegisterSource(s Source) {
+	sources = append([]Source{s}, sources...)
+}
+
+// sourceFor is a utility function that returns the source that reads the
+// input at path
+func sourceFor(path string) Source {
+	for _, s := range sources {
+		if s.Detect(path) {
+			return s
+		}
+	}
+	return fileSource{}
+}
+
+// readSource is a utility function that reads the input at path from the
+// source and returns an any along with any values rewritten in lenient mode
+// gzipped input is decompressed on the way
+func readSource(ctx context.Context, progress *loadProgress, s Source, path string) (any, map[string]string, error) {
+	if r, ok := s.(sourceReader); ok {
+		return r.read(ctx, progress, path)
+	}
+	rc, size, err := s.Open(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if size > 0 {
+		atomic.StoreInt64(&progress.Total, size)
+	}
+	content, err := io.ReadAll(progressReader{ctx: ctx, r: rc, count: &progress.Bytes})
+	// commands report how they exited once they are closed
+	if cerr := rc.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read JSON input: %w", err)
+	}
+	if content, err = gunzip(content); err != nil {
+		return nil, nil, fmt.Errorf("cannot read JSON input: %w", err)
+	}
+	return parseInput(content)
+}
+
+// stdinSource reads standard input, when no path or - is given
+type stdinSource struct{}
+
+// Detect reports whether the path stands for standard input
+func (stdinSource) Detect(path string) bool {
+	return path == "" || path == "-"
+}
+
+// Open returns standard input
+func (stdinSource) Open(ctx context.Context, path string) (io.ReadCloser, int64, error) {
+	return io.NopCloser(os.Stdin), 0, nil
+}
+
+// Watch cannot tell when standard input changes
+func (stdinSource) Watch(ctx context.Context, path string) (<-chan struct{}, error) {
+	return nil, errNoWatch
+}
+
+// fileSource reads a file, which any path can be
+type fileSource struct{}
+
+// Detect reports that any path can be a file
+func (fileSource) Detect(path string) bool {
+	return true
+}
+
+// Open opens the file
+func (fileSource) Open(ctx context.Context, path string) (io.ReadCloser, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot read JSON input: %w", err)
+	}
+	var size int64
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
+	}
+	return f, size, nil
+}
+
+// read maps the file into memory so that large files can be streamed
+func (fileSource) read(ctx context.Context, progress *loadProgress, path string) (any, map[string]string, error) {
+	return readJsonFile(ctx, progress, path)
+}
+
+// Watch checks the file for changes
+func (fileSource) Watch(ctx context.Context, path string) (<-chan struct{}, error) {
+	return watchFile(ctx, path)
+}
+
+// execSource reads the output of a shell command given as exec:command
+type execSource struct{}
+
+// execPrefix starts a path that is a command to run
+const execPrefix = "exec:"
+
+// Detect reports whether the path is a command
+func (execSource) Detect(path string) bool {
+	return strings.HasPrefix(path, execPrefix)
+}
+
+// Open runs the command
+func (execSource) Open(ctx context.Context, path string) (io.ReadCloser, int64, error) {
+	shell := []string{"sh", "-c"}
+	if runtime.GOOS == "windows" {
+		shell = []string{"cmd", "/c"}
+	}
+	return startCommand(ctx, append(shell, strings.TrimPrefix(path, execPrefix)))
+}
+
+// Watch cannot tell when the output of a command changes
+func (execSource) Watch(ctx context.Context, path string) (<-chan struct{}, error) {
+	return nil, errNoWatch
+}
+
+// clipboardSource reads the clipboard when the path is clipboard:
+type clipboardSource struct{}
+
+// clipboardPath is the path that stands for the clipboard
+const clipboardPath = "clipboard:"
+
+// Detect reports whether the path stands for the clipboard
+func (clipboardSource) Detect(path string) bool {
+	return path == clipboardPath
+}
+
+// Open runs the command that prints the clipboard
+func (clipboardSource) Open(ctx context.Context, path string) (io.ReadCloser, int64, error) {
+	candidates := [][]string{}
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = append(candidates, []string{"pbpaste"})
+	case "windows":
+		candidates = append(candidates, []string{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"})
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-paste", "--no-newline"})
+		}
+		candidates = append(candidates,
+			[]string{"xclip", "-selection", "clipboard", "-o"},
+			[]string{"xsel", "--clipboard", "--output"})
+	}
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err == nil {
+			return startCommand(ctx, args)
+		}
+	}
+	return nil, 0, fmt.Errorf("cannot read the clipboard: no pbpaste, wl-paste, xclip or xsel found")
+}
+
+// Watch cannot tell when the clipboard changes
+func (clipboardSource) Watch(ctx context.Context, path string) (<-chan struct{}, error) {
+	return nil, errNoWatch
+}
+
+// archiveSource reads a file inside a zip or tar archive given as
+// archive.zip:path/in/archive.json
+type archiveSource struct{}
+
+// archiveExts are the archives whose files can be read
+var archiveExts = []string{".zip", ".tar", ".tar.gz", ".tgz"}
+
+// splitArchive is a utility function that splits a path into the archive
+// and the name of the file inside it, returning false if it is not one
+func splitArchive(path string) (string, string, bool) {
+	for _, ext := range archiveExts {
+		if i := strings.Index(path, ext+":"); i >= 0 {
+			return path[:i+len(ext)], path[i+len(ext)+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// Detect reports whether the path is a file inside an archive
+func (archiveSource) Detect(path string) bool {
+	_, _, ok := splitArchive(path)
+	return ok
+}
+
+// Open finds the file in the archive
+func (archiveSource) Open(ctx context.Context, path string) (io.ReadCloser, int64, error) {
+	archive, name, _ := splitArchive(path)
+	if strings.HasSuffix(archive, ".zip") {
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, 0, fmt.Errorf("cannot open archive: %w", err)
+		}
+		for _, f := range zr.File {
+			if f.Name != name {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				zr.Close()
+				return nil, 0, fmt.Errorf("cannot open %s in %s: %w", name, archive, err)
+			}
+			return readCloser{r, func() error { r.Close(); return zr.Close() }}, int64(f.UncompressedSize64), nil
+		}
+		zr.Close()
+		return nil, 0, fmt.Errorf("cannot find %s in %s", name, archive)
+	}
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot open archive: %w", err)
+	}
+	var r io.Reader = f
+	if !strings.HasSuffix(archive, ".tar") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("cannot decompress %s: %w", archive, err)
+		}
+		r = zr
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("cannot read archive %s: %w", archive, err)
+		}
+		if hdr.Name == name || strings.TrimPrefix(hdr.Name, "./") == name {
+			return readCloser{tr, f.Close}, hdr.Size, nil
+		}
+	}
+	f.Close()
+	return nil, 0, fmt.Errorf("cannot find %s in %s", name, archive)
+}
+
+// Watch checks the archive for changes
+func (archiveSource) Watch(ctx context.Context, path string) (<-chan struct{}, error) {
+	archive, _, _ := splitArchive(path)
+	return watchFile(ctx, archive)
+}
+
+// readCloser reads from one reader and closes with a function
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+// Close closes whatever the reader reads from
+func (rc readCloser) Close() error {
+	return rc.close()
+}
+
+// startCommand is a utility function that runs a command and returns its
+// standard output
+// closing it waits for the command and fails with what it printed to
+// standard error if it did not succeed
+func startCommand(ctx context.Context, args []string) (io.ReadCloser, int64, error) {
+	debugLog.Printf("reading the output of %q", args)
+	var stderr bytes.Buffer
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Stderr = &stderr
+	out, err := c.StdoutPipe()
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot run %s: %w", args[0], err)
+	}
+	if err := c.Start(); err != nil {
+		return nil, 0, fmt.Errorf("cannot run %s: %w", args[0], err)
+	}
+	return readCloser{out, func() error {
+		err := c.Wait()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%s: %w: %s", args[0], err, msg)
+			} else {
+				err = fmt.Errorf("%s: %w", args[0], err)
+			}
+		}
+		return err
+	}}, 0, nil
+}
+
+// watchFile is a utility function that checks a file for changes to its
+// size or modification time every watchInterval
+func watchFile(ctx context.Context, path string) (<-chan struct{}, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot watch %s: %w", path, err)
+	}
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		last := info
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			info, err := os.Stat(path)
+			if err != nil || (info.Size() == last.Size() && info.ModTime().Equal(last.ModTime())) {
+				continue
+			}
+			last = info
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return changes, nil
+}
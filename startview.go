@@ -0,0 +1,48 @@
+package jv
+
+import "fmt"
+
+// views the listing can open in
+const (
+	viewListing = "listing" // the keys of the level
+	viewOutline = "outline" // the listing with the outline of the document beside it
+	viewTable   = "table"   // the level as a table if it is an array of objects
+	viewFlat    = "flat"    // every leaf of the document
+)
+
+// startView is the view the input opens in, it can be set in the config
+// file, for a profile or from the command line, such as table for logs
+var startView = viewListing
+
+// checkView is a utility function that checks the input can open in a view
+func checkView(view string) error {
+	switch view {
+	case viewListing, viewOutline, viewTable, viewFlat:
+		return nil
+	}
+	return fmt.Errorf("unknown view %q, expected listing, outline, table or flat", view)
+}
+
+// openStartView switches to the view the input opens in, from the listing
+// at the path it opened at
+// a table is only opened at an array of objects, the listing is kept
+// otherwise
+func (m *Model) openStartView() {
+	switch startView {
+	case viewOutline:
+		if m.Outline == nil {
+			m.Outline = &Outline{}
+			m.refreshOutline()
+		}
+	case viewTable:
+		if columns, ok := tableColumns(m.node()); !m.Flat && isArray(m.node()) && ok && len(columns) > 0 {
+			m.openTable()
+		}
+	case viewFlat:
+		if !m.Flat {
+			m.Flat, m.Filter = true, ""
+			m.resetCursor()
+			m.updateKV()
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package jv
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// streamThreshold is the file size above which files are streamed
+// instead of being read into memory
+const streamThreshold = 256 << 20
+
+// shouldStream is a utility function that checks if an input of size bytes
+// is streamed, which it is not when there are redaction rules however large
+// it is, since a streamed input is read span by span and cannot be rewritten
+func shouldStream(size int64) bool {
+	return size > streamThreshold && len(redactRules) == 0
+}
+
+// streamSource is a large input being read on demand
+type streamSource struct {
+	r     io.ReaderAt
+	mu    sync.Mutex               // guards index since the search indexer runs in the background
+	index map[int64]map[string]any // children of every container indexed so far keyed by offset
+	order map[int64][]string       // keys of every container indexed so far in file order
+}
+
+// fileSpan is a container in a streamed input that has not been parsed
+type fileSpan struct {
+	src  *streamSource
+	off  int64 // offset of the container in the file
+	size int64 // length of the container in bytes
+	kind byte  // '{' or '['
+}
+
+// spanScanner reads through a part of a streamed file one byte at a time
+type spanScanner struct {
+	src *streamSource
+	r   *bufio.Reader
+	off int64 // offset in the file of the next byte
+}
+
+// openStream is a utility function that validates a large input without
+// parsing it into memory and returns its top level value
+// or an array of the values if there are several back to back
+func openStream(ctx context.Context, progress *loadProgress, r io.ReaderAt, size int64) (any, error) {
+	// the decoder only holds a small buffer while checking the syntax
+	pr := progressReader{ctx: ctx, r: io.NewSectionReader(r, 0, size), count: &progress.Bytes}
+	dec := json.NewDecoder(bufio.NewReader(pr))
+	depth := 0
+	docs := 0
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, newSyntaxError(r, size, err)
+		}
+		atomic.AddInt64(&progress.Elements, 1)
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			docs++
+		}
+	}
+	if depth != 0 {
+		return nil, newSyntaxError(r, size, io.ErrUnexpectedEOF)
+	}
+	src := &streamSource{r: r, index: map[int64]map[string]any{}, order: map[int64][]string{}}
+	sc := src.scanner(0, size)
+	sc.skipSpace()
+	if docs < 2 {
+		return sc.readValue(), nil
+	}
+	// back to back documents are shown as an array of them
+	values := []any{}
+	for {
+		if _, err := sc.peek(); err != nil {
+			return values, nil
+		}
+		values = append(values, sc.readValue())
+		sc.skipSpace()
+	}
+}
+
+// isStreamed is a utility function that checks if a document is read from
+// a file on demand rather than held in memory
+func isStreamed(data any) bool {
+	values, ok := data.([]any)
+	if !ok || len(values) == 0 {
+		values = []any{data}
+	}
+	_, ok = values[0].(fileSpan)
+	return ok
+}
+
+// closeInput is a utility function that closes the file a streamed input is
+// read from once its document is no longer shown
+// reading what is left of the document afterwards fails rather than
+// crashing, so background tasks still walking it just stop
+func closeInput(data any) {
+	values, ok := data.([]any)
+	if !ok {
+		values = []any{data}
+	}
+	for _, v := range values {
+		if span, ok := v.(fileSpan); ok {
+			span.src.close()
+		}
+	}
+}
+
+// close closes the file the input is read from, if it is one
+func (src *streamSource) close() {
+	if c, ok := src.r.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// scanner returns a scanner over a part of the file
+func (src *streamSource) scanner(off, size int64) *spanScanner {
+	return &spanScanner{
+		src: src,
+		r:   bufio.NewReader(io.NewSectionReader(src.r, off, size)),
+		off: off,
+	}
+}
+
+// children indexes the children of a container, reading them only as far
+// as needed to find where each of them starts and ends
+func (s fileSpan) children() map[string]any {
+	s.src.mu.Lock()
+	defer s.src.mu.Unlock()
+	if children, ok := s.src.index[s.off]; ok {
+		return children
+	}
+	children, keys := s.scan()
+	s.src.index[s.off] = children
+	s.src.order[s.off] = keys
+	return children
+}
+
+// walkChildren returns the children of a container without indexing them
+// so that walking the whole input does not keep all of it in memory
+func (s fileSpan) walkChildren() map[string]any {
+	s.src.mu.Lock()
+	children, ok := s.src.index[s.off]
+	s.src.mu.Unlock()
+	if ok {
+		return children
+	}
+	children, _ = s.scan()
+	return children
+}
+
+// scan reads the children of a container and their keys in file order
+func (s fileSpan) scan() (map[string]any, []string) {
+	children := map[string]any{}
+	keys := []string{}
+	sc := s.src.scanner(s.off, s.size)
+	sc.next()
+	for n := 0; ; n++ {
+		sc.skipSpace()
+		if c, err := sc.peek(); err != nil || c == '}' || c == ']' {
+			break
+		}
+		key := strconv.Itoa(n)
+		if s.kind == '{' {
+			if err := json.Unmarshal(sc.readValue().(json.RawMessage), &key); err != nil {
+				break
+			}
+			sc.skipSpace()
+			// step over the colon
+			sc.next()
+			sc.skipSpace()
+		}
+		if _, ok := children[key]; !ok {
+			keys = append(keys, key)
+		}
+		children[key] = sc.readValue()
+		sc.skipSpace()
+		// step over the comma
+		if c, _ := sc.peek(); c == ',' {
+			sc.next()
+		}
+	}
+	return children, keys
+}
+
+// keys returns the keys of the container in the order they are in the file
+func (s fileSpan) keys() []string {
+	s.children()
+	s.src.mu.Lock()
+	defer s.src.mu.Unlock()
+	return s.src.order[s.off]
+}
+
+// bytes reads the whole container into memory
+func (s fileSpan) bytes() json.RawMessage {
+	content := make([]byte, s.size)
+	if _, err := s.src.r.ReadAt(content, s.off); err != nil && err != io.EOF {
+		return nil
+	}
+	return content
+}
+
+// peek returns the next byte without moving past it
+func (sc *spanScanner) peek() (byte, error) {
+	c, err := sc.r.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return c[0], nil
+}
+
+// next moves past the next byte and returns it
+func (sc *spanScanner) next() byte {
+	c, err := sc.r.ReadByte()
+	if err != nil {
+		return 0
+	}
+	sc.off++
+	return c
+}
+
+// skipSpace moves past any whitespace
+func (sc *spanScanner) skipSpace() {
+	for {
+		c, err := sc.peek()
+		if err != nil || (c != ' ' && c != '\t' && c != '\n' && c != '\r') {
+			return
+		}
+		sc.next()
+	}
+}
+
+// readString moves past the rest of a string whose opening quote was
+// already read, adding its bytes to buf if it is not nil
+func (sc *spanScanner) readString(buf *[]byte) {
+	for {
+		c, err := sc.r.ReadByte()
+		if err != nil {
+			return
+		}
+		sc.off++
+		if buf != nil {
+			*buf = append(*buf, c)
+		}
+		switch c {
+		case '\\':
+			escaped := sc.next()
+			if buf != nil {
+				*buf = append(*buf, escaped)
+			}
+		case '"':
+			return
+		}
+	}
+}
+
+// readValue moves past the value at the current position
+// scalars are read whole while containers are only recorded by their position
+func (sc *spanScanner) readValue() any {
+	start := sc.off
+	kind, _ := sc.peek()
+	switch kind {
+	case '{', '[':
+		depth := 0
+		for {
+			c, err := sc.r.ReadByte()
+			if err != nil {
+				return fileSpan{src: sc.src, off: start, size: sc.off - start, kind: kind}
+			}
+			sc.off++
+			switch c {
+			case '"':
+				sc.readString(nil)
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return fileSpan{src: sc.src, off: start, size: sc.off - start, kind: kind}
+				}
+			}
+		}
+	case '"':
+		buf := []byte{sc.next()}
+		sc.readString(&buf)
+		return json.RawMessage(buf)
+	}
+	// numbers, true, false and null run until the next delimiter
+	buf := []byte{}
+	for {
+		c, err := sc.peek()
+		if err != nil || c == ',' || c == '}' || c == ']' || c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			return json.RawMessage(buf)
+		}
+		buf = append(buf, sc.next())
+	}
+}
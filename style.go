@@ -0,0 +1,76 @@
+package jv
+
+import (
+	"html"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SGR parameters for the few styles used
+const (
+	styleBold  = "1"
+	styleFaint = "2"
+	styleKey   = "36" // cyan
+	styleWarn  = "33" // yellow
+)
+
+// useColor turns styling on, without it only the structure and markers are shown
+var useColor = true
+
+// colorAllowed is a utility function that checks if the environment
+// allows styling, following https://no-color.org
+func colorAllowed() bool {
+	return os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+}
+
+// style is a utility function that wraps s in an SGR escape sequence
+// if styling is turned on
+func style(s, sgr string) string {
+	if !useColor || s == "" {
+		return s
+	}
+	return "\x1b[" + sgr + "m" + s + "\x1b[0m"
+}
+
+// sgrPattern matches the escape sequences text is styled with
+var sgrPattern = regexp.MustCompile("\x1b\\[([0-9;]*)m")
+
+// htmlStyles are the CSS of the styles for styledHTML
+var htmlStyles = map[string]string{
+	styleBold:  "font-weight:bold",
+	styleFaint: "opacity:0.6",
+	styleKey:   "color:#0aa",
+	styleWarn:  "color:#a80",
+}
+
+// unstyle is a utility function that removes the styling from s
+func unstyle(s string) string {
+	return sgrPattern.ReplaceAllString(s, "")
+}
+
+// styledHTML is a utility function that turns text styled with style into
+// HTML with the same styling
+// a reset ends every style open at that point as it does in a terminal
+func styledHTML(s string) string {
+	var b strings.Builder
+	open := 0
+	for {
+		loc := sgrPattern.FindStringSubmatchIndex(s)
+		if loc == nil {
+			b.WriteString(html.EscapeString(s))
+			break
+		}
+		b.WriteString(html.EscapeString(s[:loc[0]]))
+		if sgr := s[loc[2]:loc[3]]; sgr == "0" || sgr == "" {
+			b.WriteString(strings.Repeat("</span>", open))
+			open = 0
+		} else {
+			b.WriteString(`<span style="` + htmlStyles[sgr] + `">`)
+			open++
+		}
+		s = s[loc[1]:]
+	}
+	b.WriteString(strings.Repeat("</span>", open))
+	return b.String()
+}
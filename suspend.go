@@ -0,0 +1,20 @@
+package jv
+
+import (
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// suspend hands the terminal to an external command such as the pager and
+// takes it back once the command exits, whether it succeeds, fails or is
+// killed, done turns how it exited into the message jv resumes with
+// bubbletea puts the terminal back in raw mode and on the alternate screen
+// but leaves mouse reporting off, so it is turned back on after the command
+func suspend(c *exec.Cmd, done func(error) tea.Msg) tea.Cmd {
+	run := tea.ExecProcess(c, done)
+	if !mouseEnabled {
+		return run
+	}
+	return tea.Sequence(run, tea.EnableMouseCellMotion)
+}
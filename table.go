@@ -0,0 +1,728 @@
+package jv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tableScanRows is how many elements of an array are read to find the
+// columns of its table
+const tableScanRows = 1000
+
+// tableMaxWidth is the widest a column is shown
+const tableMaxWidth = 30
+
+// Table shows an array of objects with a column for each key
+type Table struct {
+	Path     []string        // path of the array
+	Columns  []string        // every column in the order shown, hidden ones too
+	Hidden   map[string]bool // columns left out
+	Rows     []string        // indices of the elements in the order shown
+	Row      int             // row the cursor is on
+	Offset   int             // first row shown
+	Col      int             // focused column among the shown ones
+	SortBy   string          // column the rows are sorted by, "" for the order of the array
+	Desc     bool            // the rows are sorted in descending order
+	Choosing bool            // the column chooser is open
+	Choice   int             // column the chooser cursor is on
+	Adding   bool            // an expression for a computed column is being typed
+	Input    string          // expression typed so far
+	layout   string          // hash the layout is kept under
+	elements map[string]any  // elements of the array keyed by index, read once
+	computed map[string]expr // expressions of the computed columns by their column
+}
+
+// tableLayout is how a table was last laid out, it is kept for every set
+// of columns so that documents of the same type open the same way
+type tableLayout struct {
+	Columns  []string `json:"columns"`            // every column in the order shown
+	Hidden   []string `json:"hidden"`             // columns left out
+	SortBy   string   `json:"sort_by"`            // column the rows are sorted by
+	Desc     bool     `json:"desc"`               // the rows are sorted in descending order
+	Computed []string `json:"computed,omitempty"` // expressions of the computed columns
+}
+
+// tableColumns is a utility function that returns the keys of the objects
+// in an array in the order they are first seen, and false if none of the
+// elements are objects
+func tableColumns(o any) ([]string, bool) {
+	children := getKAny(o)
+	columns, seen := []string{}, map[string]bool{}
+	objects := false
+	for i := 0; i < len(children) && i < tableScanRows; i++ {
+		child := children[strconv.Itoa(i)]
+		if getKAny(child) == nil || isArray(child) {
+			continue
+		}
+		objects = true
+		keys, _ := orderedChildren(child)
+		for _, k := range keys {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	return columns, objects
+}
+
+// hasColumn is a utility function that checks if a table has a column
+func hasColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// tableDir is a utility function that returns where table layouts are kept
+func tableDir() string {
+	dir := stateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "tables")
+}
+
+// tableLayoutKey is a utility function that returns the hash the layout of
+// a table with the given columns is kept under, whatever their order
+func tableLayoutKey(columns []string) string {
+	sorted := append([]string{}, columns...)
+	sort.Strings(sorted)
+	h := sha256.Sum256([]byte(strings.Join(sorted, "\x00")))
+	return hex.EncodeToString(h[:])
+}
+
+// openTable shows the current array of objects as a table
+func (m *Model) openTable() {
+	if m.Flat || !isArray(m.node()) {
+		m.Status = "only arrays of objects can be shown as a table"
+		return
+	}
+	columns, ok := tableColumns(m.node())
+	if !ok || len(columns) == 0 {
+		m.Status = "only arrays of objects can be shown as a table"
+		return
+	}
+	t := &Table{
+		Path:     append([]string{}, m.Path...),
+		Columns:  columns,
+		Hidden:   map[string]bool{},
+		layout:   tableLayoutKey(columns),
+		elements: getKAny(m.node()),
+		computed: map[string]expr{},
+	}
+	m.Table = t
+	m.loadTableLayout()
+	m.sortTable()
+}
+
+// loadTableLayout lays the table out the way a table with the same columns
+// was left, columns that were not there then are added at the end
+func (m *Model) loadTableLayout() {
+	dir, t := tableDir(), m.Table
+	if dir == "" {
+		return
+	}
+	content, err := os.ReadFile(filepath.Join(dir, t.layout+".json"))
+	if err != nil {
+		return
+	}
+	var l tableLayout
+	if err := json.Unmarshal(content, &l); err != nil {
+		debugLog.Printf("cannot read table layout: %s", err)
+		return
+	}
+	// computed columns are added back before they are put in order
+	for _, s := range l.Computed {
+		if e, err := parseExpr(s); err == nil && !hasColumn(t.Columns, s) {
+			t.computed[s] = e
+			t.Columns = append(t.Columns, s)
+		}
+	}
+	known := map[string]bool{}
+	for _, c := range t.Columns {
+		known[c] = true
+	}
+	columns := []string{}
+	for _, c := range l.Columns {
+		if known[c] {
+			columns = append(columns, c)
+			delete(known, c)
+		}
+	}
+	for _, c := range t.Columns {
+		if known[c] {
+			columns = append(columns, c)
+		}
+	}
+	t.Columns = columns
+	for _, c := range l.Hidden {
+		t.Hidden[c] = true
+	}
+	if len(t.visible()) == 0 {
+		t.Hidden = map[string]bool{}
+	}
+	t.SortBy, t.Desc = l.SortBy, l.Desc
+}
+
+// saveTableLayout keeps how the table is laid out for tables with the
+// same columns
+// it is best effort so failing to save is only logged
+func (m *Model) saveTableLayout() {
+	dir, t := tableDir(), m.Table
+	if dir == "" {
+		return
+	}
+	l := tableLayout{Columns: t.Columns, Hidden: []string{}, SortBy: t.SortBy, Desc: t.Desc}
+	for _, c := range t.Columns {
+		if t.Hidden[c] {
+			l.Hidden = append(l.Hidden, c)
+		}
+		if _, ok := t.computed[c]; ok {
+			l.Computed = append(l.Computed, c)
+		}
+	}
+	content, err := json.Marshal(l)
+	if err == nil {
+		err = os.MkdirAll(dir, 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, t.layout+".json"), content, 0644)
+	}
+	if err != nil {
+		debugLog.Printf("cannot save table layout: %s", err)
+	}
+}
+
+// visible returns the columns that are shown, in order
+func (t *Table) visible() []string {
+	columns := []string{}
+	for _, c := range t.Columns {
+		if !t.Hidden[c] {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// tableValue returns the value in a column of a row, and false if the
+// element has no such key
+// computed columns are worked out from the element with its secrets masked
+// and are missing where they cannot be
+func (m *Model) tableValue(row, column string) (any, bool) {
+	t := m.Table
+	if e, ok := t.computed[column]; ok {
+		path := append(append([]string{}, t.Path...), row)
+		o, err := e.eval(m.maskValue(path, materialize(t.elements[row])))
+		return o, err == nil && o != nil
+	}
+	o, ok := getKAny(t.elements[row])[column]
+	return o, ok
+}
+
+// tableCell returns the value in a column of a row as it is shown
+func (m *Model) tableCell(row, column string) string {
+	o, ok := m.tableValue(row, column)
+	if !ok {
+		return ""
+	}
+	path := append(append(append([]string{}, m.Table.Path...), row), column)
+	if _, ok := m.Table.computed[column]; !ok && m.maskedAt(path) {
+		return secretMask
+	}
+	return sanitize(getVal(o))
+}
+
+// sortTable puts the rows in the order of the column sorted by
+// numbers are compared by value, other values naturally, and rows without
+// the column come last whichever way it is sorted
+func (m *Model) sortTable() {
+	t := m.Table
+	n := len(t.elements)
+	t.Rows = make([]string, n)
+	for i := range t.Rows {
+		t.Rows[i] = strconv.Itoa(i)
+	}
+	if t.SortBy == "" {
+		return
+	}
+	type sortKey struct {
+		text    string
+		num     float64
+		isNum   bool
+		missing bool
+	}
+	keys := make(map[string]sortKey, n)
+	for _, row := range t.Rows {
+		o, ok := m.tableValue(row, t.SortBy)
+		if !ok {
+			keys[row] = sortKey{missing: true}
+			continue
+		}
+		s := getVal(materializeScalar(o))
+		f, err := strconv.ParseFloat(s, 64)
+		keys[row] = sortKey{text: s, num: f, isNum: err == nil}
+	}
+	sort.SliceStable(t.Rows, func(i, j int) bool {
+		a, b := keys[t.Rows[i]], keys[t.Rows[j]]
+		if a.missing || b.missing {
+			return !a.missing && b.missing
+		}
+		if t.Desc {
+			a, b = b, a
+		}
+		if a.isNum && b.isNum {
+			return a.num < b.num
+		}
+		if a.isNum != b.isNum {
+			return a.isNum
+		}
+		return naturalLess(a.text, b.text)
+	})
+}
+
+// sortByColumn sorts the rows by a column, ascending first, then
+// descending, then in the order of the array again
+func (m *Model) sortByColumn(column string) {
+	t := m.Table
+	switch {
+	case t.SortBy != column:
+		t.SortBy, t.Desc = column, false
+	case !t.Desc:
+		t.Desc = true
+	default:
+		t.SortBy, t.Desc = "", false
+	}
+	m.sortTable()
+	t.Row, t.Offset = 0, 0
+	m.saveTableLayout()
+}
+
+// tableHeight is the number of rows the table can show, leaving room for
+// the title, the header and the lines below
+func (m *Model) tableHeight() int {
+	if m.Height > 7 {
+		return m.Height - 7
+	}
+	return 1
+}
+
+// moveTableRow moves the cursor by n rows keeping it on the table
+func (m *Model) moveTableRow(n int) {
+	t := m.Table
+	t.Row += n
+	if t.Row >= len(t.Rows) {
+		t.Row = len(t.Rows) - 1
+	}
+	if t.Row < 0 {
+		t.Row = 0
+	}
+	if t.Row < t.Offset {
+		t.Offset = t.Row
+	}
+	if t.Row >= t.Offset+m.tableHeight() {
+		t.Offset = t.Row - m.tableHeight() + 1
+	}
+}
+
+// moveColumn moves the focused column one place left or right
+func (m *Model) moveColumn(by int) {
+	t := m.Table
+	visible := t.visible()
+	if t.Col+by < 0 || t.Col+by >= len(visible) {
+		return
+	}
+	index := map[string]int{}
+	for i, c := range t.Columns {
+		index[c] = i
+	}
+	i, j := index[visible[t.Col]], index[visible[t.Col+by]]
+	t.Columns[i], t.Columns[j] = t.Columns[j], t.Columns[i]
+	t.Col += by
+	m.saveTableLayout()
+}
+
+// hideColumn leaves the focused column out, the last one is always shown
+// computed columns are removed since they can be added again
+func (m *Model) hideColumn() {
+	t := m.Table
+	visible := t.visible()
+	if len(visible) <= 1 {
+		m.Status = "the last column cannot be hidden"
+		return
+	}
+	column := visible[t.Col]
+	if _, ok := t.computed[column]; ok {
+		delete(t.computed, column)
+		for i, c := range t.Columns {
+			if c == column {
+				t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+				break
+			}
+		}
+		if t.SortBy == column {
+			t.SortBy, t.Desc = "", false
+			m.sortTable()
+		}
+		m.Status = "removed " + sanitize(column)
+	} else {
+		t.Hidden[column] = true
+		m.Status = fmt.Sprintf("hid %s, %s shows it again", sanitize(column), m.Keys.Columns.Help().Key)
+	}
+	if t.Col >= len(visible)-1 {
+		t.Col--
+	}
+	m.saveTableLayout()
+}
+
+// addColumn adds a column computed from the expression typed for every
+// row, after the focused one
+func (m *Model) addColumn() {
+	t := m.Table
+	s := strings.TrimSpace(t.Input)
+	if s == "" {
+		t.Adding = false
+		return
+	}
+	e, err := parseExpr(s)
+	if err != nil {
+		m.Status = fmt.Sprintf("cannot add the column: %s", err)
+		return
+	}
+	if hasColumn(t.Columns, s) {
+		m.Status = fmt.Sprintf("there is a column %s already", sanitize(s))
+		return
+	}
+	t.Adding, t.Input = false, ""
+	t.computed[s] = e
+	at := len(t.Columns)
+	if visible := t.visible(); t.Col < len(visible) {
+		for i, c := range t.Columns {
+			if c == visible[t.Col] {
+				at = i + 1
+			}
+		}
+	}
+	t.Columns = append(t.Columns[:at], append([]string{s}, t.Columns[at:]...)...)
+	t.Col++
+	m.saveTableLayout()
+}
+
+// updateColumnInput handles typing the expression of a computed column
+func (m *Model) updateColumnInput(msg tea.KeyMsg) {
+	t := m.Table
+	switch msg.Type {
+	case tea.KeyEsc:
+		t.Adding, t.Input = false, ""
+	case tea.KeyEnter:
+		m.addColumn()
+	case tea.KeyBackspace:
+		if len(t.Input) > 0 {
+			runes := []rune(t.Input)
+			t.Input = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		t.Input += " "
+	case tea.KeyRunes:
+		t.Input += string(msg.Runes)
+	}
+}
+
+// updateTable handles the keys and the mouse while the table is shown
+func (m *Model) updateTable(msg tea.Msg) (tea.Model, tea.Cmd) {
+	t := m.Table
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			break
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.moveTableRow(-wheelRows)
+		case tea.MouseButtonWheelDown:
+			m.moveTableRow(wheelRows)
+		case tea.MouseButtonLeft:
+			// clicking a header sorts by its column
+			if column, ok := m.tableColumnAt(msg.X); ok && msg.Y == 1 && !t.Choosing {
+				m.sortByColumn(column)
+			}
+		}
+	case tea.KeyMsg:
+		m.Status = ""
+		if t.Choosing {
+			m.updateChooser(msg)
+			break
+		}
+		if t.Adding {
+			if msg.Type == tea.KeyCtrlC {
+				return m, tea.Quit
+			}
+			m.updateColumnInput(msg)
+			break
+		}
+		visible := t.visible()
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.Keys.Close, m.Keys.Table):
+			m.Table = nil
+		case key.Matches(msg, m.Keys.Up):
+			m.moveTableRow(-1)
+		case key.Matches(msg, m.Keys.Down):
+			m.moveTableRow(1)
+		case key.Matches(msg, m.Keys.PageUp):
+			m.moveTableRow(-m.tableHeight())
+		case key.Matches(msg, m.Keys.PageDown):
+			m.moveTableRow(m.tableHeight())
+		case key.Matches(msg, m.Keys.Left):
+			if t.Col > 0 {
+				t.Col--
+			}
+		case key.Matches(msg, m.Keys.Right):
+			if t.Col < len(visible)-1 {
+				t.Col++
+			}
+		case key.Matches(msg, m.Keys.Sort):
+			m.sortByColumn(visible[t.Col])
+		case key.Matches(msg, m.Keys.HideColumn):
+			m.hideColumn()
+		case key.Matches(msg, m.Keys.Columns):
+			t.Choosing, t.Choice = true, 0
+		case key.Matches(msg, m.Keys.MoveLeft):
+			m.moveColumn(-1)
+		case key.Matches(msg, m.Keys.MoveRight):
+			m.moveColumn(1)
+		case key.Matches(msg, m.Keys.AddColumn):
+			t.Adding, t.Input = true, ""
+		// enter goes into the element on the cursor row in the listing
+		case key.Matches(msg, m.Keys.Expand):
+			if len(t.Rows) > 0 {
+				m.Table = nil
+				m.goTo(append(append([]string{}, t.Path...), t.Rows[t.Row]))
+			}
+		}
+	}
+	return m, nil
+}
+
+// updateChooser handles the keys of the column chooser, which shows and
+// hides columns and moves them
+func (m *Model) updateChooser(msg tea.KeyMsg) {
+	t := m.Table
+	switch {
+	case key.Matches(msg, m.Keys.Close, m.Keys.Columns):
+		t.Choosing = false
+		if visible := len(t.visible()); t.Col >= visible {
+			t.Col = visible - 1
+		}
+	case key.Matches(msg, m.Keys.Up):
+		if t.Choice > 0 {
+			t.Choice--
+		}
+	case key.Matches(msg, m.Keys.Down):
+		if t.Choice < len(t.Columns)-1 {
+			t.Choice++
+		}
+	case key.Matches(msg, m.Keys.ToggleColumn):
+		c := t.Columns[t.Choice]
+		if !t.Hidden[c] && len(t.visible()) <= 1 {
+			m.Status = "the last column cannot be hidden"
+			return
+		}
+		t.Hidden[c] = !t.Hidden[c]
+		m.saveTableLayout()
+	case key.Matches(msg, m.Keys.MoveLeft):
+		if t.Choice > 0 {
+			t.Columns[t.Choice-1], t.Columns[t.Choice] = t.Columns[t.Choice], t.Columns[t.Choice-1]
+			t.Choice--
+			m.saveTableLayout()
+		}
+	case key.Matches(msg, m.Keys.MoveRight):
+		if t.Choice < len(t.Columns)-1 {
+			t.Columns[t.Choice+1], t.Columns[t.Choice] = t.Columns[t.Choice], t.Columns[t.Choice+1]
+			t.Choice++
+			m.saveTableLayout()
+		}
+	}
+}
+
+// fitCell is a utility function that cuts or pads a cell to a width
+func fitCell(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n <= width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// tableLayoutWidths returns the columns shown on screen along with their
+// widths, starting far enough along for the focused column to fit
+func (m *Model) tableLayoutWidths() ([]string, []int) {
+	t := m.Table
+	visible := t.visible()
+	end := t.Offset + m.tableHeight()
+	if end > len(t.Rows) {
+		end = len(t.Rows)
+	}
+	widths := make([]int, len(visible))
+	for i, c := range visible {
+		widths[i] = utf8.RuneCountInString(sanitize(c)) + 2
+		for _, row := range t.Rows[t.Offset:end] {
+			if n := utf8.RuneCountInString(m.tableCell(row, c)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		if widths[i] > tableMaxWidth {
+			widths[i] = tableMaxWidth
+		}
+	}
+	rowWidth := m.tableIndent()
+	start := 0
+	for {
+		total := rowWidth
+		for i := start; i <= t.Col && i < len(widths); i++ {
+			total += widths[i] + 2
+		}
+		if total <= m.Width || start >= t.Col {
+			break
+		}
+		start++
+	}
+	columns, shown := []string{}, []int{}
+	total := rowWidth
+	// the focused column is shown even when it is wider than the screen
+	for i := start; i < len(visible) && (len(columns) == 0 || total+widths[i] <= m.Width); i++ {
+		width := widths[i]
+		if total+width > m.Width && m.Width-total > 1 {
+			width = m.Width - total
+		}
+		columns, shown = append(columns, visible[i]), append(shown, width)
+		total += widths[i] + 2
+	}
+	return columns, shown
+}
+
+// tableIndent is the width of the cursor and the index at the start of
+// every row, which the columns come after
+func (m *Model) tableIndent() int {
+	return len(strconv.Itoa(len(m.Table.Rows))) + 4
+}
+
+// tableColumnAt returns the column shown at a position on screen
+func (m *Model) tableColumnAt(x int) (string, bool) {
+	columns, widths := m.tableLayoutWidths()
+	at := m.tableIndent()
+	for i, c := range columns {
+		if x >= at && x < at+widths[i] {
+			return c, true
+		}
+		at += widths[i] + 2
+	}
+	return "", false
+}
+
+// viewTable renders the table
+func (m *Model) viewTable() string {
+	t := m.Table
+	if t.Choosing {
+		return m.viewChooser()
+	}
+	title := fmt.Sprintf("Table of %s, %d rows", selectorPath(m.Data, t.Path), len(t.Rows))
+	if t.SortBy != "" {
+		order := "ascending"
+		if t.Desc {
+			order = "descending"
+		}
+		title += fmt.Sprintf(", sorted by %s %s", t.SortBy, order)
+	}
+	s := style(truncate(sanitize(title), m.Width), styleBold) + "\n"
+	columns, widths := m.tableLayoutWidths()
+	rowWidth := len(strconv.Itoa(len(t.Rows)))
+	focused := t.visible()[t.Col]
+	header := strings.Repeat(" ", m.tableIndent())
+	for i, c := range columns {
+		name := sanitize(c)
+		if c == t.SortBy && t.Desc {
+			name += " ↓"
+		} else if c == t.SortBy {
+			name += " ↑"
+		}
+		cell := fitCell(name, widths[i])
+		if c == focused {
+			cell = style(cell, styleBold)
+		}
+		header += cell + "  "
+	}
+	s += strings.TrimRight(header, " ") + "\n"
+	end := t.Offset + m.tableHeight()
+	if end > len(t.Rows) {
+		end = len(t.Rows)
+	}
+	for i, row := range t.Rows[t.Offset:end] {
+		line := "  "
+		if t.Offset+i == t.Row {
+			line = "→ "
+		}
+		line += fmt.Sprintf("%*s", rowWidth, row)
+		for j, c := range columns {
+			line += "  " + fitCell(m.tableCell(row, c), widths[j])
+		}
+		s += strings.TrimRight(line, " ") + "\n"
+	}
+	if len(t.Rows) == 0 {
+		s += "(empty array)\n"
+	}
+	s += "\n"
+	if t.Adding {
+		s += fmt.Sprintf("Column: %s_  (such as len(.items) or .limits.cpu - .requests.cpu)\n", sanitize(t.Input))
+	}
+	if m.Status != "" {
+		s += truncate(sanitize(m.Status), m.Width) + "\n"
+	}
+	s += style(m.Keys.tableHelp(), styleFaint) + "\n"
+	return s
+}
+
+// viewChooser renders the column chooser
+func (m *Model) viewChooser() string {
+	t := m.Table
+	s := style("Columns", styleBold) + "\n\n"
+	height := m.tableHeight() + 1
+	start := 0
+	if t.Choice >= height {
+		start = t.Choice - height + 1
+	}
+	for i, c := range t.Columns {
+		if i < start || i >= start+height {
+			continue
+		}
+		line := "  "
+		if i == t.Choice {
+			line = "→ "
+		}
+		box := "[x]"
+		if t.Hidden[c] {
+			box = "[ ]"
+		}
+		s += truncate(line+box+" "+sanitize(c), m.Width) + "\n"
+	}
+	s += "\n"
+	if m.Status != "" {
+		s += truncate(sanitize(m.Status), m.Width) + "\n"
+	}
+	s += style(m.Keys.chooserHelp(), styleFaint) + "\n"
+	return s
+}
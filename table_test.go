@@ -0,0 +1,77 @@
+package jv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		objects bool
+	}{
+		{"keys in the order first seen", `[{"b": 1, "a": 2}, {"c": 3, "a": 4}]`, []string{"b", "a", "c"}, true},
+		{"elements that are not objects are skipped", `[1, [2], {"a": 1}, null]`, []string{"a"}, true},
+		{"no objects", `[1, "a", [3]]`, []string{}, false},
+		{"empty objects", `[{}, {}]`, []string{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parseJson([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, objects := tableColumns(data)
+			if !reflect.DeepEqual(got, tt.want) || objects != tt.objects {
+				t.Errorf("got %v %t, want %v %t", got, objects, tt.want, tt.objects)
+			}
+		})
+	}
+}
+
+func TestSortTable(t *testing.T) {
+	input := `[{"n": 10, "s": "item10"}, {"s": "item2"}, {"n": 9, "s": "b"}, {"n": "x", "s": "item1"}, {"n": 2.5}]`
+	tests := []struct {
+		sortBy string
+		desc   bool
+		want   []string
+	}{
+		{"", false, []string{"0", "1", "2", "3", "4"}},
+		// numbers come before text and rows without the column come last
+		{"n", false, []string{"4", "2", "0", "3", "1"}},
+		{"n", true, []string{"3", "0", "2", "4", "1"}},
+		{"s", false, []string{"2", "3", "1", "0", "4"}},
+		{"s", true, []string{"0", "1", "3", "2", "4"}},
+	}
+	for _, tt := range tests {
+		data, err := parseJson([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := &Model{Table: &Table{elements: getKAny(data), SortBy: tt.sortBy, Desc: tt.desc}}
+		m.sortTable()
+		if !reflect.DeepEqual(m.Table.Rows, tt.want) {
+			t.Errorf("sorted by %q desc %t: got %v, want %v", tt.sortBy, tt.desc, m.Table.Rows, tt.want)
+		}
+	}
+}
+
+func TestFitCell(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"ab", 4, "ab  "},
+		{"abcd", 4, "abcd"},
+		{"abcde", 4, "abc…"},
+		{"héllo wörld", 6, "héllo…"},
+	}
+	for _, tt := range tests {
+		if got := fitCell(tt.s, tt.width); got != tt.want {
+			t.Errorf("fitCell(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
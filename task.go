@@ -0,0 +1,182 @@
+package jv
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// task is work running in the background, such as a search or a fetch
+type task struct {
+	id     int                // tells the task apart from later ones under the same name
+	title  string             // what is shown while it runs, empty for tasks that are not shown
+	done   int                // progress reported so far
+	bar    bool               // the progress is a percentage shown as a bar
+	cancel context.CancelFunc // stops the task
+}
+
+// taskMsg reports the progress of a background task and finally what it
+// came up with
+type taskMsg struct {
+	Name   string         // name the task was started under
+	Done   int            // progress so far
+	Result tea.Msg        // message handed to Update once the task is done, nil until then
+	Over   bool           // the task has finished
+	id     int            // task the message is from
+	ch     <-chan taskMsg // channel the next message comes from
+}
+
+// taskWork is the work of a task, it reports progress through the function
+// it is given and returns the message to hand to Update once it is done
+// it should stop early once ctx is cancelled
+type taskWork func(ctx context.Context, progress func(int)) tea.Msg
+
+// run does the work, turning a panic into a crashMsg for the workspace
+// since nothing would restore the terminal if it took the program down
+func (work taskWork) run(ctx context.Context, progress func(int)) (result tea.Msg) {
+	defer recoverCommand(&result)
+	return work(ctx, progress)
+}
+
+// startTask runs work in the background under name, cancelling the task
+// already running under that name if there is one
+// tasks with a title are shown while they run and can be cancelled with
+// the cancel key, the others run quietly
+func (m *Model) startTask(name, title string, work taskWork) tea.Cmd {
+	if t, ok := m.tasks[name]; ok {
+		t.cancel()
+	}
+	if m.tasks == nil {
+		m.tasks = map[string]*task{}
+	}
+	m.taskID++
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &task{id: m.taskID, title: title, cancel: cancel}
+	m.tasks[name] = t
+	debugLog.Printf("starting task %s", name)
+	ch := make(chan taskMsg, 1)
+	go func() {
+		result := work.run(ctx, func(n int) {
+			// progress is only reported if the last update has been picked up
+			select {
+			case ch <- taskMsg{Name: name, Done: n, id: t.id}:
+			default:
+			}
+		})
+		// progress that was not picked up is stale now
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- taskMsg{Name: name, Result: result, Over: true, id: t.id}
+		close(ch)
+	}()
+	return waitTask(ch)
+}
+
+// startProgressTask is startTask for work that reports its progress as a
+// percentage, which is shown as a bar
+func (m *Model) startProgressTask(name, title string, work taskWork) tea.Cmd {
+	cmd := m.startTask(name, title, work)
+	m.tasks[name].bar = true
+	return cmd
+}
+
+// progressBar is a utility function that draws a percentage as a bar
+func progressBar(percent int) string {
+	const width = 10
+	filled := percent * width / 100
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + fmt.Sprintf(" %d%%", percent)
+}
+
+// waitTask waits for the next message from a task
+func waitTask(ch <-chan taskMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		msg.ch = ch
+		return msg
+	}
+}
+
+// updateTask records the progress of a task and hands its result to
+// Update once it is done
+// messages from tasks that have been cancelled or replaced are dropped
+func (m *Model) updateTask(msg taskMsg) tea.Cmd {
+	t, ok := m.tasks[msg.Name]
+	if !ok || t.id != msg.id {
+		return nil
+	}
+	if !msg.Over {
+		t.done = msg.Done
+		// searches list what they have found so far
+		if msg.Name == "search" {
+			m.showFound()
+		}
+		return waitTask(msg.ch)
+	}
+	t.cancel()
+	delete(m.tasks, msg.Name)
+	debugLog.Printf("task %s is done", msg.Name)
+	switch result := msg.Result.(type) {
+	case nil:
+		return nil
+	case crashMsg:
+		return func() tea.Msg { return result }
+	}
+	_, cmd := m.Update(msg.Result)
+	return cmd
+}
+
+// taskProgress returns the progress of the task running under name and
+// whether there is one
+func (m *Model) taskProgress(name string) (int, bool) {
+	t, ok := m.tasks[name]
+	if !ok {
+		return 0, false
+	}
+	return t.done, true
+}
+
+// cancelTasks stops the tasks that are shown, or every task if all is set
+func (m *Model) cancelTasks(all bool) []string {
+	cancelled := []string{}
+	for name, t := range m.tasks {
+		if t.title == "" && !all {
+			continue
+		}
+		t.cancel()
+		delete(m.tasks, name)
+		cancelled = append(cancelled, t.title)
+	}
+	sort.Strings(cancelled)
+	return cancelled
+}
+
+// tasksLine describes the tasks that are shown, or "" if there are none
+func (m *Model) tasksLine() string {
+	running := []string{}
+	for _, t := range m.tasks {
+		if t.title == "" {
+			continue
+		}
+		if t.bar {
+			running = append(running, fmt.Sprintf("%s %s", t.title, progressBar(t.done)))
+		} else if t.done > 0 {
+			running = append(running, fmt.Sprintf("%s (%d)", t.title, t.done))
+		} else {
+			running = append(running, t.title)
+		}
+	}
+	if len(running) == 0 {
+		return ""
+	}
+	sort.Strings(running)
+	return fmt.Sprintf("⋯ %s…  %s: %s", strings.Join(running, ", "),
+		m.Keys.Cancel.Help().Desc, m.Keys.Cancel.Help().Key)
+}
@@ -0,0 +1,82 @@
+package jv
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeFormats are the ways timestamps can be shown, in the order the time
+// format key goes through them
+// local is the time in timeZone along with how long ago it was, rfc3339 is
+// the time in timeZone as RFC 3339 and relative is only how long ago it was
+var timeFormats = []string{"local", "rfc3339", "relative"}
+
+// timeFormat is how timestamps are shown, it can be set from the command line
+var timeFormat = "local"
+
+// timeZone is the zone timestamps are shown in, the local one unless it is
+// set from the command line
+var timeZone = time.Local
+
+// checkTimeFormat is a utility function that checks if a time format is one
+// timestamps can be shown in
+func checkTimeFormat(format string) error {
+	for _, f := range timeFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown time format %q, expected one of %v", format, timeFormats)
+}
+
+// loadTimeZone is a utility function that finds a time zone by its IANA
+// name such as Europe/Berlin, or the local one if the name is empty or local
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	// the error already says the zone is unknown
+	return time.LoadLocation(name)
+}
+
+// formatTimestamp is a utility function that shows a time in one of the
+// time formats
+func formatTimestamp(t time.Time, format string) string {
+	switch format {
+	case "rfc3339":
+		return t.In(timeZone).Format(time.RFC3339Nano)
+	case "relative":
+		return relativeTime(time.Since(t))
+	}
+	return t.In(timeZone).Format("2006-01-02 15:04:05 MST") + ", " + relativeTime(time.Since(t))
+}
+
+// cycleTimeFormat shows the timestamp under the cursor in the next time
+// format, leaving the other timestamps as they are
+func (m *Model) cycleTimeFormat() {
+	if len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return
+	}
+	path := m.currentPath()
+	if _, ok := parseTimestamp(materialize(m.valueAt(path))); !ok || !renderValues {
+		m.Status = "not a timestamp"
+		return
+	}
+	current, ok := m.TimeFormats[pathKey(path)]
+	if !ok {
+		current = timeFormat
+	}
+	next := timeFormats[0]
+	for i, f := range timeFormats {
+		if f == current {
+			next = timeFormats[(i+1)%len(timeFormats)]
+		}
+	}
+	if m.TimeFormats == nil {
+		m.TimeFormats = map[string]string{}
+	}
+	m.TimeFormats[pathKey(path)] = next
+	m.rowCache = nil
+	m.Status = fmt.Sprintf("showing %s as %s time", selectorPath(m.Data, path), next)
+}
@@ -0,0 +1,176 @@
+package jv
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// traceBarWidth is the width of the bars showing when each span ran
+const traceBarWidth = 40
+
+// span is one operation in a trace
+type span struct {
+	ID       string
+	Parent   string
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+	children []*span
+}
+
+// traceSpans is a utility function that reads the spans of an OTLP or Jaeger
+// trace export and reports whether the data is one
+func traceSpans(data any) ([]*span, bool) {
+	root, ok := materialize(data).(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	if resourceSpans, ok := root["resourceSpans"].([]any); ok {
+		return otlpSpans(resourceSpans), true
+	}
+	if traces, ok := root["data"].([]any); ok && len(traces) > 0 {
+		if first, ok := traces[0].(map[string]any); ok && first["spans"] != nil && first["traceID"] != nil {
+			return jaegerSpans(traces), true
+		}
+	}
+	return nil, false
+}
+
+// otlpSpans reads the spans of OTLP JSON, whose times are in nanoseconds
+func otlpSpans(resourceSpans []any) []*span {
+	spans := []*span{}
+	for _, rs := range resourceSpans {
+		rs, _ := rs.(map[string]any)
+		// older exporters call the scopes instrumentation libraries
+		scopes, _ := rs["scopeSpans"].([]any)
+		if scopes == nil {
+			scopes, _ = rs["instrumentationLibrarySpans"].([]any)
+		}
+		for _, scope := range scopes {
+			scope, _ := scope.(map[string]any)
+			list, _ := scope["spans"].([]any)
+			for _, s := range list {
+				s, _ := s.(map[string]any)
+				start := traceNumber(s["startTimeUnixNano"])
+				end := traceNumber(s["endTimeUnixNano"])
+				spans = append(spans, &span{
+					ID:       fmt.Sprint(s["spanId"]),
+					Parent:   traceString(s["parentSpanId"]),
+					Name:     fmt.Sprint(s["name"]),
+					Start:    time.Unix(0, start),
+					Duration: time.Duration(end - start),
+				})
+			}
+		}
+	}
+	return spans
+}
+
+// jaegerSpans reads the spans of a Jaeger export, whose times are in microseconds
+func jaegerSpans(traces []any) []*span {
+	spans := []*span{}
+	for _, trace := range traces {
+		trace, _ := trace.(map[string]any)
+		list, _ := trace["spans"].([]any)
+		for _, s := range list {
+			s, _ := s.(map[string]any)
+			parent := ""
+			refs, _ := s["references"].([]any)
+			for _, ref := range refs {
+				ref, _ := ref.(map[string]any)
+				if ref["refType"] == "CHILD_OF" {
+					parent = traceString(ref["spanID"])
+				}
+			}
+			spans = append(spans, &span{
+				ID:       fmt.Sprint(s["spanID"]),
+				Parent:   parent,
+				Name:     fmt.Sprint(s["operationName"]),
+				Start:    time.UnixMicro(traceNumber(s["startTime"])),
+				Duration: time.Duration(traceNumber(s["duration"])) * time.Microsecond,
+			})
+		}
+	}
+	return spans
+}
+
+// traceNumber reads a time that is either a number or, as OTLP writes
+// 64 bit integers, a string
+func traceNumber(o any) int64 {
+	n, _ := strconv.ParseInt(strings.Trim(fmt.Sprint(o), `"`), 10, 64)
+	return n
+}
+
+// traceString reads an optional id
+func traceString(o any) string {
+	if s, ok := o.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// spanTreeString is a utility function that draws the spans as a tree
+// indented by parent with a bar showing when each ran within the trace
+func spanTreeString(spans []*span) string {
+	if len(spans) == 0 {
+		return "(no spans)"
+	}
+	byID := map[string]*span{}
+	for _, s := range spans {
+		byID[s.ID] = s
+	}
+	roots := []*span{}
+	start, end := spans[0].Start, spans[0].Start.Add(spans[0].Duration)
+	for _, s := range spans {
+		if p, ok := byID[s.Parent]; ok && p != s {
+			p.children = append(p.children, s)
+		} else {
+			roots = append(roots, s)
+		}
+		if s.Start.Before(start) {
+			start = s.Start
+		}
+		if e := s.Start.Add(s.Duration); e.After(end) {
+			end = e
+		}
+	}
+	total := end.Sub(start)
+	lines := []string{fmt.Sprintf("%d spans over %s", len(spans), total)}
+	var draw func(s *span, depth int)
+	draw = func(s *span, depth int) {
+		lines = append(lines, fmt.Sprintf("%-50s %10s  %s",
+			truncate(strings.Repeat("  ", depth)+sanitize(s.Name), 50), s.Duration, spanBar(s, start, total)))
+		sort.SliceStable(s.children, func(i, j int) bool {
+			return s.children[i].Start.Before(s.children[j].Start)
+		})
+		for _, c := range s.children {
+			draw(c, depth+1)
+		}
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		return roots[i].Start.Before(roots[j].Start)
+	})
+	for _, r := range roots {
+		draw(r, 0)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// spanBar draws when a span ran within the whole trace
+func spanBar(s *span, start time.Time, total time.Duration) string {
+	if total <= 0 {
+		return strings.Repeat("█", traceBarWidth)
+	}
+	from := int(int64(s.Start.Sub(start)) * traceBarWidth / int64(total))
+	width := int(int64(s.Duration) * traceBarWidth / int64(total))
+	if width < 1 {
+		width = 1
+	}
+	if from+width > traceBarWidth {
+		from = traceBarWidth - width
+	}
+	return strings.Repeat(" ", from) + strings.Repeat("█", width)
+}
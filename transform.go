@@ -0,0 +1,176 @@
+package jv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// transformStep decodes a string one way
+type transformStep struct {
+	Key   string // key that applies the step in the menu
+	Name  string
+	Apply func([]byte) ([]byte, error)
+}
+
+// transformSteps are the ways a string can be decoded, in the order of the menu
+var transformSteps = []transformStep{
+	{"1", "base64 decode", decodeBase64},
+	{"2", "gunzip", func(b []byte) ([]byte, error) {
+		if !bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+			return nil, errors.New("not gzipped")
+		}
+		return gunzip(b)
+	}},
+	{"3", "hex decode", func(b []byte) ([]byte, error) {
+		return hex.DecodeString(strings.TrimSpace(string(b)))
+	}},
+	{"4", "JSON parse", parseEmbeddedJson},
+	{"5", "URL decode", func(b []byte) ([]byte, error) {
+		s, err := url.QueryUnescape(string(b))
+		return []byte(s), err
+	}},
+}
+
+// Transform decodes a string value step by step, showing what each step
+// turns it into, for payloads that are encoded more than once
+type Transform struct {
+	Path    []string // path of the string
+	Steps   []string // names of the steps applied
+	Outputs [][]byte // the string and then what each step turned it into
+	Err     string   // why the step tried last failed, "" if it did not
+}
+
+// decodeBase64 is a utility function that decodes standard or URL safe
+// base64, padded or not
+func decodeBase64(b []byte) ([]byte, error) {
+	s := strings.TrimSpace(string(b))
+	var err error
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		var decoded []byte
+		if decoded, err = enc.DecodeString(s); err == nil {
+			return decoded, nil
+		}
+	}
+	return nil, err
+}
+
+// parseEmbeddedJson is a utility function that reads JSON held in a string
+// a JSON string is unquoted so that JSON encoded twice can be read in two
+// steps, anything else is indented
+func parseEmbeddedJson(b []byte) ([]byte, error) {
+	if !json.Valid(b) {
+		return nil, errors.New("not JSON")
+	}
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		return []byte(s), nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// transformPreview is a utility function that shows what a step turned the
+// string into on one line, or how many bytes it is if it is not text
+func transformPreview(b []byte, width int) string {
+	if isBinary(b) {
+		return style(fmt.Sprintf("(%s of binary)", humanBytes(int64(len(b)))), styleFaint)
+	}
+	return truncate(sanitize(strings.Join(strings.Fields(string(b)), " ")), width)
+}
+
+// openTransform starts decoding the string under the cursor
+func (m *Model) openTransform() {
+	if m.Flat || len(m.CurrKV) == 0 || m.CurrKV[m.CurrC.RowNo].More {
+		return
+	}
+	s, ok := materializeScalar(m.currentNode()).(string)
+	if !ok {
+		m.Status = "only strings can be decoded"
+		return
+	}
+	if m.maskedAt(m.currentPath()) {
+		m.Status = fmt.Sprintf("secrets are not decoded while they are masked, %s reveals it", m.Keys.Reveal.Help().Key)
+		return
+	}
+	m.Transform = &Transform{Path: m.currentPath(), Outputs: [][]byte{[]byte(s)}}
+}
+
+// updateTransform handles the keys of the transform menu
+// the number of a step applies it to what the last step turned the string
+// into, backspace undoes the last step and enter shows the result in full
+func (m *Model) updateTransform(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := m.Transform
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.Transform = nil
+	case tea.KeyBackspace:
+		if len(t.Steps) > 0 {
+			t.Steps, t.Outputs = t.Steps[:len(t.Steps)-1], t.Outputs[:len(t.Outputs)-1]
+		}
+		t.Err = ""
+	case tea.KeyEnter:
+		m.Transform = nil
+		title := fmt.Sprintf("%s of %s", strings.Join(t.Steps, " | "), selectorPath(m.Data, t.Path))
+		if len(t.Steps) == 0 {
+			title = selectorPath(m.Data, t.Path)
+		}
+		// bytes that are not text are shown as a hex dump
+		if out := t.Outputs[len(t.Outputs)-1]; isBinary(out) {
+			m.openHexDump(sanitize(title), out)
+		} else {
+			m.openDetail(sanitize(title), pluginOutput(string(out)), "decoded.txt")
+		}
+	case tea.KeyRunes:
+		for _, step := range transformSteps {
+			if string(msg.Runes) != step.Key {
+				continue
+			}
+			out, err := step.Apply(t.Outputs[len(t.Outputs)-1])
+			if err != nil {
+				t.Err = fmt.Sprintf("cannot %s: %s", step.Name, err)
+				break
+			}
+			t.Steps, t.Outputs, t.Err = append(t.Steps, step.Name), append(t.Outputs, out), ""
+		}
+	}
+	return m, nil
+}
+
+// viewTransform renders the string, what each step turned it into and the
+// steps that can be applied next
+func (m *Model) viewTransform() string {
+	t := m.Transform
+	width := m.Width - 4
+	if width < 10 {
+		width = 10
+	}
+	s := fmt.Sprintf("Decode %s\n\n", style(sanitize(selectorPath(m.Data, t.Path)), styleBold))
+	s += "  " + transformPreview(t.Outputs[0], width) + "\n"
+	for i, name := range t.Steps {
+		s += style(fmt.Sprintf("%d. %s", i+1, name), styleKey) + "\n"
+		s += "  " + transformPreview(t.Outputs[i+1], width) + "\n"
+	}
+	if t.Err != "" {
+		s += "\n" + style(truncate(sanitize(t.Err), m.Width), styleWarn) + "\n"
+	}
+	menu := []string{}
+	for _, step := range transformSteps {
+		menu = append(menu, step.Key+" "+step.Name)
+	}
+	s += "\n" + strings.Join(menu, "  ") + "\n"
+	s += "\n" + style("Show in full: enter  Undo: backspace  Close: esc", styleFaint) + "\n"
+	return s
+}
@@ -0,0 +1,118 @@
+package jv
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tsIdentifier matches keys that can be used unquoted as TypeScript property names
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsGenerator collects the TypeScript interfaces generated for a schema
+type tsGenerator struct {
+	optional bool              // mark keys missing from some array elements as optional
+	names    []string          // interface names in the order they were generated
+	defs     map[string]string // interface name to its definition
+}
+
+// tsInterfaceString is a utility function that returns TypeScript interfaces
+// matching the given any
+// if optional is set, keys that are not present in every element of an array
+// are marked as optional properties
+func tsInterfaceString(name string, o any, optional bool) string {
+	g := &tsGenerator{optional: optional, defs: map[string]string{}}
+	root := exportedName(name)
+	t := g.tsType(inferSchema(materialize(o)), root)
+	// scalars and arrays at the top level get a type alias
+	if t != root {
+		g.names = append([]string{root}, g.names...)
+		g.defs[root] = fmt.Sprintf("export type %s = %s;", root, t)
+	}
+	defs := []string{}
+	for _, n := range g.names {
+		defs = append(defs, g.defs[n])
+	}
+	return strings.Join(defs, "\n\n") + "\n"
+}
+
+// tsType returns the TypeScript type for a schema, generating interfaces
+// for objects with the given name
+func (g *tsGenerator) tsType(s map[string]any, name string) string {
+	if options, ok := s["anyOf"].([]any); ok {
+		types := []string{}
+		for _, o := range options {
+			types = append(types, g.tsType(o.(map[string]any), name))
+		}
+		return strings.Join(types, " | ")
+	}
+	switch s["type"] {
+	case "object":
+		return g.tsInterface(s, name)
+	case "array":
+		if items, ok := s["items"].(map[string]any); ok {
+			t := g.tsType(items, name+"Item")
+			if strings.Contains(t, "|") {
+				return fmt.Sprintf("(%s)[]", t)
+			}
+			return t + "[]"
+		}
+		return "unknown[]"
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "null":
+		return "null"
+	}
+	return "unknown"
+}
+
+// tsInterface generates an interface for an object schema and returns its name
+func (g *tsGenerator) tsInterface(s map[string]any, name string) string {
+	unique := name
+	for i := 2; g.defs[unique] != "" || g.taken(unique); i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	name = unique
+	// reserve the name before generating nested interfaces
+	g.names = append(g.names, name)
+	props := s["properties"].(map[string]any)
+	required := map[string]bool{}
+	for _, k := range s["required"].([]string) {
+		required[k] = true
+	}
+	keys := []string{}
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	def := fmt.Sprintf("export interface %s {\n", name)
+	for _, k := range keys {
+		prop := k
+		if !tsIdentifier.MatchString(k) {
+			prop = fmt.Sprintf("%q", k)
+		}
+		if g.optional && !required[k] {
+			prop += "?"
+		}
+		t := g.tsType(props[k].(map[string]any), exportedName(k))
+		def += fmt.Sprintf("  %s: %s;\n", prop, t)
+	}
+	def += "}"
+	g.defs[name] = def
+	return name
+}
+
+// taken checks if an interface name has already been reserved
+func (g *tsGenerator) taken(name string) bool {
+	for _, n := range g.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package jv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTsInterfaceString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		optional bool
+		want     []string
+	}{
+		{
+			name:  "scalars",
+			input: `{"id": 1, "name": "a", "ok": true, "none": null}`,
+			want: []string{
+				"export interface Root {",
+				"  id: number;",
+				"  name: string;",
+				"  none: null;",
+				"  ok: boolean;",
+			},
+		},
+		{
+			name:  "keys that are not identifiers are quoted",
+			input: `{"foo-bar": 1, "$ref": "x", "1st": 2}`,
+			want:  []string{`  "1st": number;`, `  "foo-bar": number;`, "  $ref: string;"},
+		},
+		{
+			name:     "keys missing from some elements are optional",
+			input:    `[{"a": 1, "b": 2}, {"a": 3}]`,
+			optional: true,
+			want:     []string{"export type Root = RootItem[];", "  a: number;", "  b?: number;"},
+		},
+		{
+			name:  "keys missing from some elements are required in strict mode",
+			input: `[{"a": 1, "b": 2}, {"a": 3}]`,
+			want:  []string{"  b: number;"},
+		},
+		{
+			name:  "mixed elements",
+			input: `{"v": [1, "a"]}`,
+			want:  []string{"  v: (number | string)[];"},
+		},
+		{
+			name:  "nested objects with the same name",
+			input: `{"a": {"x": {"p": 1}}, "b": {"x": {"q": 2}}}`,
+			want: []string{
+				"export interface X {\n  p: number;\n}",
+				"export interface X2 {\n  q: number;\n}",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parseJson([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := tsInterfaceString("Root", data, tt.optional)
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("missing %q in\n%s", w, got)
+				}
+			}
+		})
+	}
+}
@@ -1,31 +1,205 @@
-package main
+package jv
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-// readJsonStdin is a utility function that reads JSON from stdin
-// and returns an any
-func readJsonStdin() (any, error) {
-	var data any
-	content, err := io.ReadAll(os.Stdin)
+// readJsonInput is a utility function that reads JSON from the source of
+// the path, such as a file, a URL or stdin if the path is empty
+func readJsonInput(ctx context.Context, progress *loadProgress, path string) (any, map[string]string, error) {
+	return readSource(ctx, progress, sourceFor(path), path)
+}
+
+// mappedFiles holds the files that have been mapped into memory
+// a file is read rather than mapped again when it is reloaded, since
+// reloading means it has been rewritten and a mapping changes under the
+// viewer when the file is rewritten in place and crashes it when the file
+// is truncated
+var mappedFiles sync.Map
+
+// mapFile checks if the file at path should be mapped into memory, which it
+// should unless the input is watched or the file has been mapped before
+func mapFile(path string) bool {
+	if watchInput {
+		return false
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	_, mapped := mappedFiles.LoadOrStore(path, true)
+	return !mapped
+}
+
+// readJsonFile is a utility function that reads JSON from a file
+// and returns an any along with any values rewritten in lenient mode
+// the file is mapped into memory rather than copied the first time it is
+// read, and files too large to comfortably parse in memory are streamed from
+// the mapping or from the open file, which closeInput closes once the
+// document is replaced
+func readJsonFile(ctx context.Context, progress *loadProgress, path string) (any, map[string]string, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read JSON input: %w", err)
+		return nil, nil, fmt.Errorf("cannot read JSON input: %w", err)
 	}
-	err = json.Unmarshal(content, &data)
+	info, err := f.Stat()
 	if err != nil {
-		return nil, fmt.Errorf("cannot unmarshal JSON data: %w", err)
+		f.Close()
+		return nil, nil, fmt.Errorf("cannot read JSON input: %w", err)
 	}
-	return data, nil
+	// pipes and devices cannot be mapped and empty files don't need to be
+	if info.Mode().IsRegular() && info.Size() > 0 && mapFile(path) {
+		content, err := mmapFile(f, info.Size())
+		if err == nil {
+			f.Close()
+			return parseContent(ctx, progress, content)
+		}
+		debugLog.Printf("reading %s since it cannot be mapped: %s", path, err)
+	}
+	// streamed files have to be strict JSON, and watched files are read
+	// into memory however large since they are rewritten all the time
+	if info.Mode().IsRegular() && shouldStream(info.Size()) && !watchInput {
+		atomic.StoreInt64(&progress.Total, info.Size())
+		debugLog.Printf("streaming %s since it is larger than %s", humanBytes(info.Size()), humanBytes(streamThreshold))
+		data, err := openStream(ctx, progress, f, info.Size())
+		if err != nil {
+			f.Close()
+		}
+		return data, nil, err
+	}
+	content, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read JSON input: %w", err)
+	}
+	return parseContent(ctx, progress, content)
+}
+
+// parseContent is a utility function that parses the content of a file,
+// streaming it if it is too large to comfortably parse in memory
+func parseContent(ctx context.Context, progress *loadProgress, content []byte) (any, map[string]string, error) {
+	atomic.StoreInt64(&progress.Total, int64(len(content)))
+	if shouldStream(int64(len(content))) {
+		debugLog.Printf("streaming %s since it is larger than %s", humanBytes(int64(len(content))), humanBytes(streamThreshold))
+		data, err := openStream(ctx, progress, bytes.NewReader(content), int64(len(content)))
+		return data, nil, err
+	}
+	atomic.StoreInt64(&progress.Bytes, int64(len(content)))
+	return parseInput(content)
+}
+
+// parseInput is a utility function that parses the input, first rewriting it
+// into valid JSON in lenient mode
+// it also returns why any values were rewritten keyed by their path
+func parseInput(content []byte) (any, map[string]string, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, nil, errEmptyInput
+	}
+	var fixes map[string]string
+	if lenient && !json.Valid(content) {
+		content, fixes = relaxJson(content)
+		debugLog.Printf("lenient mode rewrote %d values", len(fixes))
+	}
+	data, err := parseJson(content)
+	return data, fixes, err
+}
+
+// readFile is a utility function that reads a whole file into memory
+func readFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read JSON input: %w", err)
+	}
+	return content, nil
+}
+
+// input formats
+const (
+	formatAuto = "auto" // JSON, falling back to gron statements
+	formatJson = "json" // JSON only
+	formatGron = "gron" // gron statements only
+)
+
+// inputFormat is the format of the input, it can be set from the command line
+var inputFormat = formatAuto
+
+// parseJson is a utility function that checks the given content
+// and returns an any
+// the JSON is only validated here and is parsed lazily as it is explored
+// input that is not JSON but gron statements gets rebuilt into a tree
+func parseJson(content []byte) (any, error) {
+	if inputFormat == formatGron {
+		debugLog.Printf("reading %d bytes as gron", len(content))
+		data, err := ungron(content)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read gron input: %w", err)
+		}
+		return data, nil
+	}
+	if !json.Valid(content) {
+		if docs := splitDocuments(content); docs != nil {
+			debugLog.Printf("input is %d JSON documents back to back", len(docs))
+			return docs, nil
+		}
+		if inputFormat == formatAuto {
+			if gdata, gerr := ungron(content); gerr == nil {
+				debugLog.Printf("input is gron")
+				return gdata, nil
+			}
+		}
+		// unmarshal again to find out what is wrong with the input
+		var data any
+		err := json.Unmarshal(content, &data)
+		return nil, newSyntaxError(bytes.NewReader(content), int64(len(content)), err)
+	}
+	debugLog.Printf("input is %d bytes of JSON", len(content))
+	return json.RawMessage(content), nil
+}
+
+// splitDocuments is a utility function that splits input made of several
+// JSON values back to back, such as newline delimited JSON, into an array
+// of the values
+// it returns nil if the input is not a sequence of more than one valid value
+func splitDocuments(content []byte) []any {
+	docs := []any{}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	for {
+		start := dec.InputOffset()
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil
+		}
+		// the values stay slices of the input like any other raw value
+		docs = append(docs, json.RawMessage(content[start:dec.InputOffset()]))
+	}
+	if len(docs) < 2 {
+		return nil
+	}
+	return docs
 }
 
 // getKAny is a utility function that type casts an any
 // and returns a map of string and any
 // if the input is neither one of these we will return nil
 func getKAny(o any) map[string]any {
+	if span, ok := o.(fileSpan); ok {
+		return span.children()
+	}
+	if raw, ok := o.(json.RawMessage); ok {
+		return splitRaw(raw)
+	}
 	if val, ok := o.(map[string]any); ok {
 		return val
 	}
@@ -42,14 +216,37 @@ func getKAny(o any) map[string]any {
 // getVal is a utility function that takes any and returns
 // an appropriate string value for it
 func getVal(o any) string {
+	if span, ok := o.(fileSpan); ok {
+		if span.kind == '{' {
+			return "{}"
+		}
+		return "[]"
+	}
+	if raw, ok := o.(json.RawMessage); ok {
+		// containers are shown without parsing them
+		switch rawKind(raw) {
+		case '{':
+			return "{}"
+		case '[':
+			return "[]"
+		case '"', 't', 'f', 'n':
+			return getVal(materialize(raw))
+		}
+		// numbers are shown exactly as they were written
+		return string(bytes.TrimSpace(raw))
+	}
 	if valstr, ok := o.(string); ok {
 		return valstr
 	}
 	if valint, ok := o.(int); ok {
 		return fmt.Sprintf("%d", valint)
 	}
+	// numbers are shown exactly as they were written
+	if valnum, ok := o.(json.Number); ok {
+		return string(valnum)
+	}
 	if valflt, ok := o.(float64); ok {
-		return fmt.Sprintf("%f", valflt)
+		return strconv.FormatFloat(valflt, 'f', -1, 64)
 	}
 	if valbool, ok := o.(bool); ok {
 		return fmt.Sprintf("%t", valbool)
@@ -63,19 +260,96 @@ func getVal(o any) string {
 	return ""
 }
 
+// childKeys is a utility function that returns the keys of an object or
+// array in the order they appear in the input
+// objects that have already been parsed have lost their order so their
+// keys are sorted instead
+func childKeys(o any) []string {
+	keys, _ := orderedChildren(o)
+	return keys
+}
+
+// orderedChildren is a utility function that returns the children of an
+// object or array along with their keys in the order of childKeys
+func orderedChildren(o any) ([]string, map[string]any) {
+	switch val := o.(type) {
+	case fileSpan:
+		return val.keys(), val.children()
+	case json.RawMessage:
+		return splitRawOrdered(val)
+	case []any:
+		keys := make([]string, len(val))
+		for i := range val {
+			keys[i] = strconv.Itoa(i)
+		}
+		return keys, getKAny(val)
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys, val
+	}
+	return nil, nil
+}
+
 // getInitialKV is a utility function that gets the initial list of key-value pairs
-// given an any
+// given an any, in the order of its keys in the input
 func getInitialKV(o any) []KVPair {
 	kvpairs := []KVPair{}
-	m := getKAny(o)
-	if m != nil {
-		for key, val := range m {
-			kvp := KVPair{
-				Key:   key,
-				Value: getVal(val),
-			}
-			kvpairs = append(kvpairs, kvp)
-		}
+	keys, children := orderedChildren(o)
+	for _, key := range keys {
+		kvpairs = append(kvpairs, KVPair{
+			Key:   key,
+			Value: getVal(children[key]),
+		})
 	}
 	return kvpairs
 }
+
+// splitList is a utility function that reads a comma separated list of
+// keys or patterns such as "name, status, error"
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// pathKey is a utility function that turns a path into a string
+// that can be used as a map key
+func pathKey(path []string) string {
+	key, _ := json.Marshal(path)
+	return string(key)
+}
+
+// decodeJson is a utility function that parses JSON into an any
+// numbers are kept as json.Number so they don't lose precision
+func decodeJson(content []byte) (any, error) {
+	var data any
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// commonPrefix is a utility function that returns the longest prefix the
+// words all start with
+func commonPrefix(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	common := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	return common
+}
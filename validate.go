@@ -0,0 +1,465 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// violation is a value that does not match the schema it is checked against
+type violation struct {
+	Path    []string // path to the value
+	Message string   // what is wrong with it
+}
+
+// violationsMsg is sent once the input has been checked against the schema
+type violationsMsg struct {
+	Violations []violation
+}
+
+// schemaValidator checks values against a JSON Schema
+// it supports the common validation keywords and local $refs
+type schemaValidator struct {
+	root       any // the whole schema that $refs are resolved against
+	violations []violation
+}
+
+// loadSchema is a utility function that reads a JSON Schema from a file
+func loadSchema(path string) (any, error) {
+	content, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema: %w", err)
+	}
+	schema, err := decodeJson(content)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema %s: %w", path, err)
+	}
+	switch schema.(type) {
+	case map[string]any, bool:
+		return schema, nil
+	}
+	return nil, fmt.Errorf("cannot read schema %s: expected an object", path)
+}
+
+// validateSchema is a utility function that checks the data against a schema
+// and returns every violation found in the order of the paths
+func validateSchema(schema any, data any) []violation {
+	v := &schemaValidator{root: schema}
+	v.check(schema, materialize(data), []string{}, 0)
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		return pathLess(v.violations[i].Path, v.violations[j].Path)
+	})
+	return v.violations
+}
+
+// validateCmd checks the data against the schema in the background
+func validateCmd(schema any, data any) tea.Cmd {
+	return func() tea.Msg {
+		violations := validateSchema(schema, data)
+		debugLog.Printf("found %d schema violations", len(violations))
+		return violationsMsg{Violations: violations}
+	}
+}
+
+// fail records a violation at the given path
+func (v *schemaValidator) fail(path []string, format string, args ...any) {
+	p := make([]string, len(path))
+	copy(p, path)
+	v.violations = append(v.violations, violation{Path: p, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches checks if a value matches a schema without recording any violations
+func (v *schemaValidator) matches(s any, o any, depth int) bool {
+	sub := &schemaValidator{root: v.root}
+	sub.check(s, o, []string{}, depth)
+	return len(sub.violations) == 0
+}
+
+// check records every way the value at path does not match the schema
+// depth counts the $refs followed so a schema that refers to itself
+// without ever reaching a value cannot loop forever
+func (v *schemaValidator) check(s any, o any, path []string, depth int) {
+	if b, ok := s.(bool); ok {
+		if !b {
+			v.fail(path, "no value is allowed here")
+		}
+		return
+	}
+	schema, ok := s.(map[string]any)
+	if !ok {
+		return
+	}
+	// OpenAPI 3.0 schemas that also take null are marked nullable
+	if nullable, _ := schema["nullable"].(bool); nullable && o == nil {
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		if depth > 100 {
+			v.fail(path, "too many nested references at %s", ref)
+			return
+		}
+		target, err := resolveRef(v.root, ref)
+		if err != nil {
+			v.fail(path, "%s", err)
+			return
+		}
+		v.check(target, o, path, depth+1)
+	}
+	if t, ok := schema["type"]; ok && !matchesType(t, o) {
+		v.fail(path, "expected %s but got %s", typeNames(t), jsonType(o))
+		// the other keywords would only repeat the same mistake
+		return
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, o) {
+		v.fail(path, "expected %s", compactJson(c))
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, o) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "expected one of %s", compactJson(enum))
+		}
+	}
+	for _, sub := range listOf(schema["allOf"]) {
+		v.check(sub, o, path, depth)
+	}
+	if anyOf := listOf(schema["anyOf"]); anyOf != nil {
+		found := false
+		for _, sub := range anyOf {
+			if v.matches(sub, o, depth) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "does not match any of the anyOf schemas")
+		}
+	}
+	if oneOf := listOf(schema["oneOf"]); oneOf != nil {
+		n := 0
+		for _, sub := range oneOf {
+			if v.matches(sub, o, depth) {
+				n++
+			}
+		}
+		if n != 1 {
+			v.fail(path, "matches %d of the oneOf schemas instead of exactly one", n)
+		}
+	}
+	if not, ok := schema["not"]; ok && v.matches(not, o, depth) {
+		v.fail(path, "matches the schema it must not match")
+	}
+	switch val := o.(type) {
+	case map[string]any:
+		v.checkObject(schema, val, path, depth)
+	case []any:
+		v.checkArray(schema, val, path, depth)
+	case string:
+		v.checkString(schema, val, path)
+	case json.Number:
+		v.checkNumber(schema, val, path)
+	}
+}
+
+// checkObject checks the keywords that apply to objects
+func (v *schemaValidator) checkObject(schema map[string]any, o map[string]any, path []string, depth int) {
+	for _, k := range listOf(schema["required"]) {
+		if name, ok := k.(string); ok {
+			if _, ok := o[name]; !ok {
+				v.fail(path, "missing required key %s", name)
+			}
+		}
+	}
+	if n, ok := schemaInt(schema["minProperties"]); ok && len(o) < n {
+		v.fail(path, "has %d keys, fewer than the minimum of %d", len(o), n)
+	}
+	if n, ok := schemaInt(schema["maxProperties"]); ok && len(o) > n {
+		v.fail(path, "has %d keys, more than the maximum of %d", len(o), n)
+	}
+	props, _ := schema["properties"].(map[string]any)
+	patterns, _ := schema["patternProperties"].(map[string]any)
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		child := append(path, k)
+		known := false
+		if sub, ok := props[k]; ok {
+			known = true
+			v.check(sub, o[k], child, depth)
+		}
+		for pattern, sub := range patterns {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(k) {
+				known = true
+				v.check(sub, o[k], child, depth)
+			}
+		}
+		if known {
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.fail(child, "key %s is not allowed", k)
+			}
+		case map[string]any:
+			v.check(extra, o[k], child, depth)
+		}
+	}
+}
+
+// checkArray checks the keywords that apply to arrays
+func (v *schemaValidator) checkArray(schema map[string]any, o []any, path []string, depth int) {
+	if n, ok := schemaInt(schema["minItems"]); ok && len(o) < n {
+		v.fail(path, "has %d elements, fewer than the minimum of %d", len(o), n)
+	}
+	if n, ok := schemaInt(schema["maxItems"]); ok && len(o) > n {
+		v.fail(path, "has %d elements, more than the maximum of %d", len(o), n)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+	outer:
+		for i := range o {
+			for j := 0; j < i; j++ {
+				if jsonEqual(o[i], o[j]) {
+					v.fail(append(path, strconv.Itoa(i)), "repeats element %d", j)
+					break outer
+				}
+			}
+		}
+	}
+	// prefixItems describes the first elements and items the rest
+	prefix := listOf(schema["prefixItems"])
+	for i, e := range o {
+		child := append(path, strconv.Itoa(i))
+		if i < len(prefix) {
+			v.check(prefix[i], e, child, depth)
+		} else if items, ok := schema["items"]; ok {
+			v.check(items, e, child, depth)
+		}
+	}
+}
+
+// checkString checks the keywords that apply to strings
+func (v *schemaValidator) checkString(schema map[string]any, o string, path []string) {
+	n := len([]rune(o))
+	if min, ok := schemaInt(schema["minLength"]); ok && n < min {
+		v.fail(path, "is %d characters long, shorter than the minimum of %d", n, min)
+	}
+	if max, ok := schemaInt(schema["maxLength"]); ok && n > max {
+		v.fail(path, "is %d characters long, longer than the maximum of %d", n, max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.fail(path, "invalid pattern %s in the schema: %s", pattern, err)
+		} else if !re.MatchString(o) {
+			v.fail(path, "does not match the pattern %s", pattern)
+		}
+	}
+}
+
+// checkNumber checks the keywords that apply to numbers
+func (v *schemaValidator) checkNumber(schema map[string]any, o json.Number, path []string) {
+	f, err := o.Float64()
+	if err != nil {
+		return
+	}
+	if min, ok := schemaFloat(schema["minimum"]); ok && f < min {
+		v.fail(path, "%s is less than the minimum of %v", o, min)
+	}
+	if max, ok := schemaFloat(schema["maximum"]); ok && f > max {
+		v.fail(path, "%s is more than the maximum of %v", o, max)
+	}
+	if min, ok := schemaFloat(schema["exclusiveMinimum"]); ok && f <= min {
+		v.fail(path, "%s is not more than %v", o, min)
+	}
+	if max, ok := schemaFloat(schema["exclusiveMaximum"]); ok && f >= max {
+		v.fail(path, "%s is not less than %v", o, max)
+	}
+	if step, ok := schemaFloat(schema["multipleOf"]); ok && step > 0 {
+		if q := f / step; q != float64(int64(q)) {
+			v.fail(path, "%s is not a multiple of %v", o, step)
+		}
+	}
+}
+
+// resolveRef is a utility function that finds the schema a $ref points to
+// only references within the same schema, like #/$defs/name, are supported
+func resolveRef(root any, ref string) (any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("cannot resolve %s, only references within the schema are supported", ref)
+	}
+	pointer, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference %s: %w", ref, err)
+	}
+	target, err := resolvePointer(root, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s: %w", ref, err)
+	}
+	return target, nil
+}
+
+// resolvePointer is a utility function that finds the value a JSON Pointer
+// such as /components/schemas/Pet points to in a materialized document
+func resolvePointer(root any, pointer string) (any, error) {
+	path, err := pointerPath(pointer)
+	if err != nil {
+		return nil, err
+	}
+	o := root
+	for _, token := range path {
+		switch val := o.(type) {
+		case map[string]any:
+			child, ok := val[token]
+			if !ok {
+				return nil, fmt.Errorf("no key %s", token)
+			}
+			o = child
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(val) {
+				return nil, fmt.Errorf("no element %s", token)
+			}
+			o = val[i]
+		default:
+			return nil, fmt.Errorf("%s is not inside an object or array", token)
+		}
+	}
+	return o, nil
+}
+
+// jsonType is a utility function that returns the JSON Schema type
+// of a materialized value
+func jsonType(o any) string {
+	switch val := o.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := val.Float64(); err == nil && f == float64(int64(f)) {
+			return "integer"
+		}
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// matchesType checks a value against the type keyword of a schema
+// which is either one type or a list of them
+func matchesType(t any, o any) bool {
+	names := listOf(t)
+	if name, ok := t.(string); ok {
+		names = []any{name}
+	}
+	actual := jsonType(o)
+	for _, name := range names {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeNames formats the type keyword of a schema for a message
+func typeNames(t any) string {
+	list := listOf(t)
+	if list == nil {
+		return fmt.Sprint(t)
+	}
+	names := []string{}
+	for _, name := range list {
+		names = append(names, fmt.Sprint(name))
+	}
+	return strings.Join(names, " or ")
+}
+
+// jsonEqual is a utility function that compares two materialized values
+// numbers are equal if they have the same value however they are written
+func jsonEqual(a, b any) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, errx := x.Float64()
+		fy, erry := y.Float64()
+		if errx != nil || erry != nil {
+			return x == y
+		}
+		return fx == fy
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// listOf returns a schema keyword that holds a list, or nil if it is not one
+func listOf(o any) []any {
+	list, _ := o.([]any)
+	return list
+}
+
+// schemaFloat reads a number from a schema keyword
+func schemaFloat(o any) (float64, bool) {
+	n, ok := o.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+// schemaInt reads a count from a schema keyword
+func schemaInt(o any) (int, bool) {
+	f, ok := schemaFloat(o)
+	return int(f), ok
+}
+
+// compactJson formats a value from a schema for a message
+func compactJson(o any) string {
+	content, err := json.Marshal(o)
+	if err != nil {
+		return fmt.Sprint(o)
+	}
+	return string(content)
+}
@@ -0,0 +1,181 @@
+package jv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checkUpdates asks GitHub for the latest release when the version is
+// shown, it is off unless it is turned on so that jv works offline
+var checkUpdates = false
+
+// releasesURL is where the latest release is looked up
+var releasesURL = "https://api.github.com/repos/nishakm/jv/releases/latest"
+
+// updateTimeout is how long the latest release is waited for
+const updateTimeout = 10 * time.Second
+
+// aboutTitle is the title of the detail pane showing the version
+const aboutTitle = "About jv"
+
+// updateMsg carries the latest release once it has been looked up
+type updateMsg struct {
+	Latest string // tag of the latest release such as v1.4.0
+	URL    string // page of the latest release
+	Err    error
+}
+
+// buildLines is a utility function that describes the build of the
+// program: its version, the commit it was built from and the Go version
+func buildLines() [][2]string {
+	lines := [][2]string{{"Version", getVersion()}}
+	commit, built, modified := "", "", false
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				commit = s.Value
+			case "vcs.time":
+				built = s.Value
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+	}
+	if commit != "" {
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if modified {
+			commit += " (modified)"
+		}
+		lines = append(lines, [2]string{"Commit", commit})
+	}
+	if built != "" {
+		lines = append(lines, [2]string{"Commit time", built})
+	}
+	return append(lines,
+		[2]string{"Go", runtime.Version()},
+		[2]string{"Platform", runtime.GOOS + "/" + runtime.GOARCH})
+}
+
+// openVersion shows the version of the program in the detail pane and
+// looks up the latest release in the background if checking is on
+func (m *Model) openVersion() tea.Cmd {
+	s := []string{}
+	for _, l := range buildLines() {
+		s = append(s, fmt.Sprintf("%-12s%s", l[0], l[1]))
+	}
+	m.openDetail(aboutTitle, strings.Join(s, "\n"), "version.txt")
+	if !checkUpdates {
+		m.Detail.Status = "checking for updates is off, -check-updates turns it on"
+		return nil
+	}
+	m.Detail.Status = "checking for updates…"
+	return m.startTask("update", "checking for updates", func(ctx context.Context, _ func(int)) tea.Msg {
+		return latestRelease(ctx)
+	})
+}
+
+// latestRelease looks up the latest release on GitHub
+func latestRelease(ctx context.Context) tea.Msg {
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+	client, err := httpClient(fetchOptions)
+	if err != nil {
+		return updateMsg{Err: err}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releasesURL, nil)
+	if err != nil {
+		return updateMsg{Err: err}
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return updateMsg{Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return updateMsg{Err: fmt.Errorf("%s returned %s", releasesURL, resp.Status)}
+	}
+	var release struct {
+		Tag string `json:"tag_name"`
+		URL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return updateMsg{Err: fmt.Errorf("cannot read the latest release: %w", err)}
+	}
+	return updateMsg{Latest: release.Tag, URL: release.URL}
+}
+
+// versionParts is a utility function that reads a version such as v1.4.0
+// into its numbers, false if it is not one
+func versionParts(v string) ([]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "-")
+	parts := []int{}
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// newerVersion is a utility function that reports whether latest comes
+// after current, and false if either cannot be compared
+func newerVersion(latest, current string) (bool, bool) {
+	l, ok := versionParts(latest)
+	c, cok := versionParts(current)
+	if !ok || !cok {
+		return false, false
+	}
+	for i := 0; i < len(l) || i < len(c); i++ {
+		a, b := 0, 0
+		if i < len(l) {
+			a = l[i]
+		}
+		if i < len(c) {
+			b = c[i]
+		}
+		if a != b {
+			return a > b, true
+		}
+	}
+	return false, true
+}
+
+// finishUpdateCheck says whether a newer release exists, under the version
+// if it is still shown
+func (m *Model) finishUpdateCheck(msg updateMsg) {
+	var status string
+	current := getVersion()
+	newer, ok := newerVersion(msg.Latest, current)
+	switch {
+	case msg.Err != nil:
+		debugLog.Printf("cannot check for updates: %s", msg.Err)
+		status = fmt.Sprintf("cannot check for updates: %s", msg.Err)
+	case !ok:
+		status = fmt.Sprintf("the latest release is %s, this build is %s", sanitize(msg.Latest), current)
+	case newer:
+		status = fmt.Sprintf("%s is out, this is %s: %s", sanitize(msg.Latest), current, sanitize(msg.URL))
+	default:
+		status = fmt.Sprintf("no release is newer than %s", current)
+	}
+	if m.Detail != nil && m.Detail.Title == aboutTitle {
+		m.Detail.Status = status
+		return
+	}
+	m.Status = status
+}
@@ -0,0 +1,45 @@
+package jv
+
+import (
+	"fmt"
+	"io"
+)
+
+// tracePaths prints every path visited once the viewer is closed, it can
+// be set from the command line
+var tracePaths bool
+
+// recordVisit remembers the level being listed the first time it is visited
+func (m *Model) recordVisit() {
+	if !tracePaths || m.Flat {
+		return
+	}
+	if m.seen == nil {
+		m.seen = map[string]bool{}
+	}
+	if key := pathKey(m.Path); !m.seen[key] {
+		m.seen[key] = true
+		m.visited = append(m.visited, append([]string{}, m.Path...))
+	}
+}
+
+// printVisits writes the paths visited in each document in the order they
+// were first visited, as selectors that -path takes
+func printVisits(out io.Writer, docs []*Document) {
+	for _, d := range docs {
+		m := d.Model
+		if m.Data == nil || len(m.visited) == 0 {
+			continue
+		}
+		if len(docs) > 1 {
+			source := m.Source
+			if source == "" {
+				source = "stdin"
+			}
+			fmt.Fprintf(out, "# %s\n", sanitize(source))
+		}
+		for _, path := range m.visited {
+			fmt.Fprintln(out, sanitize(selectorPath(m.Data, path)))
+		}
+	}
+}
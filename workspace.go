@@ -0,0 +1,357 @@
+package jv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Document is one of the documents open in a workspace
+// every document is a model of its own with its own data, path, caches
+// and the places that $refs were followed from
+type Document struct {
+	Model *Model
+	id    int // tells the messages of the document apart from the others
+}
+
+// Workspace holds the documents that are open and shows one of them
+// key presses go to the document that is shown while the results of
+// background work go back to the document that started it
+type Workspace struct {
+	Docs   []*Document // documents in the order they were opened
+	Active int         // index of the document that is shown
+	Width  int         // terminal width
+	Height int         // terminal height
+
+	docID  int                // id of the document opened last
+	shared *shared            // what the documents share, such as the subtree marked to compare
+	crash  *crash             // panic that stopped the program, nil if there was none
+	stop   context.CancelFunc // stops the program when View panics, nil if it is not running in a terminal
+}
+
+// docMsg is a message from a command that a document started
+type docMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+// openDocMsg asks the workspace to open a model as a new document and show
+// it, a model on its own cannot so it only says why
+type openDocMsg struct {
+	Model *Model
+}
+
+// showDoc shows a document a model on its own was asked to open, which
+// it can only do in the detail pane and only for documents that are
+// already loaded, such as fetched links
+func (m *Model) showDoc(d *Model) {
+	if d.loaded == nil || d.loaded.Err != nil {
+		m.Status = fmt.Sprintf("cannot open %s, there are only tabs in the viewer", sanitize(d.tabLabel()))
+		return
+	}
+	content, err := json.MarshalIndent(materialize(d.loaded.Data), "", "  ")
+	if err != nil {
+		m.Status = fmt.Sprintf("cannot show %s: %s", sanitize(d.Source), err)
+		return
+	}
+	m.openDetail(sanitize(d.Source), string(content), "resource.json")
+}
+
+// openDoc is a utility function that returns the command asking the
+// workspace to open a model in a new tab
+func openDoc(m *Model) tea.Cmd {
+	return func() tea.Msg { return openDocMsg{Model: m} }
+}
+
+// teaPackage is where the messages that bubbletea handles itself come from,
+// such as quitting or running a pager, which must reach it unwrapped
+var teaPackage = reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+
+// NewWorkspace returns a workspace with the given documents open and the
+// first one shown
+func NewWorkspace(models ...*Model) *Workspace {
+	w := &Workspace{shared: &shared{}}
+	for _, m := range models {
+		w.add(m)
+	}
+	return w
+}
+
+// add adds a model as a document without starting it
+// the model shares the subtree marked to compare with the other documents
+func (w *Workspace) add(m *Model) *Document {
+	m.shared = w.shared
+	w.docID++
+	d := &Document{Model: m, id: w.docID}
+	w.Docs = append(w.Docs, d)
+	return d
+}
+
+// Init starts loading every document
+func (w *Workspace) Init() tea.Cmd {
+	cmds := []tea.Cmd{}
+	for _, d := range w.Docs {
+		cmds = append(cmds, wrapDoc(d.id, d.Model.Init()))
+	}
+	return tea.Batch(cmds...)
+}
+
+// Open adds a document while the workspace is running, shows it and
+// starts loading it
+func (w *Workspace) Open(m *Model) tea.Cmd {
+	d := w.add(m)
+	w.Active = len(w.Docs) - 1
+	// the tab bar takes a line from every document once there are two
+	return tea.Batch(w.resize(), wrapDoc(d.id, m.Init()))
+}
+
+// Close closes the document at index i, saving where it was left, and
+// stops its background work
+// closing the last document quits
+func (w *Workspace) Close(i int) tea.Cmd {
+	if i < 0 || i >= len(w.Docs) {
+		return nil
+	}
+	m := w.Docs[i].Model
+	m.saveSession()
+	m.cancelTasks(true)
+	if m.cancelLoad != nil {
+		m.cancelLoad()
+	}
+	w.Docs = append(w.Docs[:i], w.Docs[i+1:]...)
+	if len(w.Docs) == 0 {
+		return tea.Quit
+	}
+	if w.Active > i || w.Active == len(w.Docs) {
+		w.Active--
+	}
+	return w.resize()
+}
+
+// Switch shows the document at index i
+func (w *Workspace) Switch(i int) error {
+	if i < 0 || i >= len(w.Docs) {
+		return fmt.Errorf("no document %d, there are %d", i+1, len(w.Docs))
+	}
+	w.Active = i
+	return nil
+}
+
+// resize tells every document how much of the terminal it has, which is
+// all of it but the line of the tab bar while more than one is open
+func (w *Workspace) resize() tea.Cmd {
+	if w.Width == 0 {
+		return nil
+	}
+	height := w.Height
+	if len(w.Docs) > 1 {
+		height--
+	}
+	cmds := []tea.Cmd{}
+	for _, d := range w.Docs {
+		_, cmd := d.Model.Update(tea.WindowSizeMsg{Width: w.Width, Height: height})
+		cmds = append(cmds, wrapDoc(d.id, cmd))
+	}
+	return tea.Batch(cmds...)
+}
+
+// Current returns the model of the document that is shown, or nil if
+// every document has been closed
+func (w *Workspace) Current() *Model {
+	if w.Active >= len(w.Docs) {
+		return nil
+	}
+	return w.Docs[w.Active].Model
+}
+
+// Update hands a message to the document it is for
+// the terminal size goes to every document and anything else that did not
+// come from a document, such as key presses, to the one that is shown
+// a panic in a document stops the program with a crash report
+func (w *Workspace) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.crashed(r, debug.Stack())
+			model, cmd = w, tea.Quit
+		}
+	}()
+	switch msg := msg.(type) {
+	case crashMsg:
+		w.crashed(msg.Value, msg.Stack)
+		return w, tea.Quit
+	case docMsg:
+		if open, ok := msg.msg.(openDocMsg); ok {
+			return w, w.Open(open.Model)
+		}
+		for _, d := range w.Docs {
+			if d.id == msg.id {
+				_, cmd := d.Model.Update(msg.msg)
+				return w, wrapDoc(d.id, cmd)
+			}
+		}
+		// the document has been closed since
+		return w, nil
+	case tea.WindowSizeMsg:
+		w.Width, w.Height = msg.Width, msg.Height
+		return w, w.resize()
+	case tea.KeyMsg:
+		if cmd, ok := w.updateTabs(msg); ok {
+			return w, cmd
+		}
+	case controlMsg:
+		if cmd, ok := w.control(msg); ok {
+			return w, cmd
+		}
+	}
+	if len(w.Docs) == 0 {
+		return w, nil
+	}
+	d := w.Docs[w.Active]
+	_, cmd = d.Model.Update(msg)
+	return w, wrapDoc(d.id, cmd)
+}
+
+// View renders the document that is shown
+// View cannot quit so a panic in it stops the program through its context
+// instead, which Update must not do since bubbletea would wait forever for
+// the command it returns
+func (w *Workspace) View() (view string) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.crashed(r, debug.Stack())
+			if w.stop != nil {
+				w.stop()
+			}
+			view = ""
+		}
+	}()
+	m := w.Current()
+	if m == nil {
+		return ""
+	}
+	if len(w.Docs) > 1 {
+		return w.tabBar() + "\n" + m.View()
+	}
+	return m.View()
+}
+
+// tabBar renders the documents that are open as a line of tabs with the
+// one shown in bold, cut short when they do not fit
+func (w *Workspace) tabBar() string {
+	s, width := "", 0
+	for i, d := range w.Docs {
+		label := fmt.Sprintf(" %d %s ", i+1, d.Model.tabLabel())
+		n := utf8.RuneCountInString(label)
+		if width+n > w.Width && w.Width > 0 {
+			break
+		}
+		width += n
+		if i == w.Active {
+			label = style(label, styleBold)
+		} else {
+			label = style(label, styleFaint)
+		}
+		s += label
+	}
+	return s
+}
+
+// tabLabel names the document in the tab bar by the last part of where
+// its input came from
+func (m *Model) tabLabel() string {
+	if m.Source == "" {
+		return "stdin"
+	}
+	label := strings.TrimSuffix(m.Source, "/")
+	if i := strings.LastIndexAny(label, "/\\"); i >= 0 && i < len(label)-1 {
+		label = label[i+1:]
+	}
+	return sanitize(truncate(label, 24))
+}
+
+// updateTabs switches between the documents and closes them, whatever the
+// document that is shown is doing, and reports whether the key was for
+// the tabs
+func (w *Workspace) updateTabs(msg tea.KeyMsg) (tea.Cmd, bool) {
+	m := w.Current()
+	if m == nil {
+		return nil, false
+	}
+	switch {
+	case key.Matches(msg, m.Keys.NextTab):
+		w.Switch((w.Active + 1) % len(w.Docs))
+	case key.Matches(msg, m.Keys.PrevTab):
+		w.Switch((w.Active + len(w.Docs) - 1) % len(w.Docs))
+	case key.Matches(msg, m.Keys.CloseTab):
+		return w.Close(w.Active), true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// control carries out the commands of the control socket that are about
+// the documents rather than the one shown, and reports whether it was one
+func (w *Workspace) control(msg controlMsg) (tea.Cmd, bool) {
+	var cmd tea.Cmd
+	var err error
+	switch msg.Verb {
+	case "open":
+		if msg.Arg == "" {
+			err = fmt.Errorf("no input to open")
+			break
+		}
+		if m := w.Current(); m != nil {
+			cmd = w.Open(m.sibling(msg.Arg, false))
+		} else {
+			cmd = w.Open(NewModel(msg.Arg))
+		}
+	case "close":
+		cmd = w.Close(w.Active)
+	case "tab":
+		var n int
+		if n, err = strconv.Atoi(msg.Arg); err == nil {
+			err = w.Switch(n - 1)
+		}
+	default:
+		return nil, false
+	}
+	msg.Reply <- controlReply(err, map[string]any{"tab": w.Active + 1, "tabs": len(w.Docs)})
+	return cmd, true
+}
+
+// wrapDoc is a utility function that marks the messages of a command as
+// coming from the document with the given id
+// batches are wrapped command by command and the messages bubbletea
+// handles itself and panics are left alone
+func wrapDoc(id int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() (msg tea.Msg) {
+		defer recoverCommand(&msg)
+		msg = cmd()
+		switch m := msg.(type) {
+		case nil, crashMsg:
+			return m
+		case tea.BatchMsg:
+			cmds := make([]tea.Cmd, len(m))
+			for i, c := range m {
+				cmds[i] = wrapDoc(id, c)
+			}
+			return tea.BatchMsg(cmds)
+		}
+		if reflect.TypeOf(msg).PkgPath() == teaPackage {
+			return msg
+		}
+		return docMsg{id: id, msg: msg}
+	}
+}
@@ -0,0 +1,161 @@
+package jv
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// workspaceJson opens each JSON as a document of a workspace on an 80×20
+// screen without styling, without loading them yet
+func workspaceJson(t *testing.T, contents ...string) *Workspace {
+	t.Helper()
+	useColor = false
+	models := []*Model{}
+	for _, content := range contents {
+		m, err := NewModelFromJson([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		models = append(models, m)
+	}
+	w := NewWorkspace(models...)
+	runWorkspace(w, func() tea.Msg { return tea.WindowSizeMsg{Width: 80, Height: 20} })
+	return w
+}
+
+// runWorkspace carries out commands one after the other the way Headless
+// does, handing their messages to the workspace
+func runWorkspace(w *Workspace, cmd tea.Cmd) {
+	queue := []tea.Cmd{cmd}
+	for steps := 0; len(queue) > 0 && steps < headlessSteps; steps++ {
+		cmd, queue = queue[0], queue[1:]
+		if cmd == nil {
+			continue
+		}
+		switch msg := cmd().(type) {
+		case nil, tea.QuitMsg:
+		case docMsg:
+			if _, ok := msg.msg.(spinnerMsg); ok {
+				continue
+			}
+			_, next := w.Update(msg)
+			queue = append(queue, next)
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		default:
+			_, next := w.Update(msg)
+			queue = append(queue, next)
+		}
+	}
+}
+
+func TestWorkspaceRoutesToBackgroundDocument(t *testing.T) {
+	w := workspaceJson(t, `{"a": 1}`, `{"b": 2}`)
+	front, back := w.Docs[0].Model, w.Docs[1].Model
+	runWorkspace(w, wrapDoc(w.Docs[1].id, back.Init()))
+	if back.Loading || back.Data == nil {
+		t.Fatalf("the document in the background did not load")
+	}
+	if !front.Loading || front.Data != nil {
+		t.Errorf("the message for the document in the background reached the one shown")
+	}
+	if w.Current() != front {
+		t.Errorf("loading the document in the background showed it")
+	}
+}
+
+func TestWorkspaceDropsMessagesOfClosedDocument(t *testing.T) {
+	w := workspaceJson(t, `{"a": 1}`, `{"b": 2}`)
+	kept, closed := w.Docs[0].Model, w.Docs[1]
+	load := wrapDoc(closed.id, closed.Model.Init())
+	runWorkspace(w, w.Close(1))
+	runWorkspace(w, load)
+	if len(w.Docs) != 1 || w.Current() != kept {
+		t.Fatalf("closing the second document left %d open", len(w.Docs))
+	}
+	if closed.Model.Data != nil {
+		t.Errorf("the closed document was still loaded")
+	}
+	if !kept.Loading || kept.Data != nil {
+		t.Errorf("the message for the closed document reached another one")
+	}
+}
+
+func TestWorkspaceTabs(t *testing.T) {
+	w := workspaceJson(t, `{"a": 1}`)
+	runWorkspace(w, w.Init())
+	first := w.Current()
+	second, err := NewModelFromJson([]byte(`{"b": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runWorkspace(w, wrapDoc(w.Docs[0].id, openDoc(second)))
+	if len(w.Docs) != 2 || w.Current() != second || second.Data == nil {
+		t.Fatalf("the document was not opened and shown in a new tab")
+	}
+	view := w.View()
+	if !strings.HasPrefix(view, " 1 stdin  2 stdin ") {
+		t.Errorf("no tab bar above the document:\n%s", view)
+	}
+	if second.Height != first.Height || len(strings.Split(view, "\n")) > 20 {
+		t.Errorf("the tab bar does not fit on the screen, the documents are %d and %d high", first.Height, second.Height)
+	}
+	runWorkspace(w, func() tea.Msg { return tea.KeyMsg{Type: tea.KeyCtrlN} })
+	if w.Current() != first {
+		t.Errorf("the next tab after the last is not the first")
+	}
+	runWorkspace(w, func() tea.Msg { return tea.KeyMsg{Type: tea.KeyCtrlX} })
+	if len(w.Docs) != 1 || w.Current() != second {
+		t.Fatalf("closing the tab shown left the wrong one")
+	}
+	if strings.HasPrefix(w.View(), " 1 stdin") {
+		t.Errorf("the tab bar is shown with one document open")
+	}
+}
+
+func TestCompareAcrossDocuments(t *testing.T) {
+	w := workspaceJson(t, `{"spec": {"replicas": 1, "image": "app:1"}}`, `{"spec": {"replicas": 3, "image": "app:1"}}`)
+	runWorkspace(w, w.Init())
+	press := func(k tea.KeyMsg) {
+		runWorkspace(w, func() tea.Msg { return k })
+	}
+	runes := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+	// .spec is marked in the first document and compared from the second
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	press(runes("m"))
+	press(tea.KeyMsg{Type: tea.KeyCtrlN})
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	press(runes("="))
+	m := w.Current()
+	if m != w.Docs[1].Model || m.Detail == nil {
+		t.Fatalf("comparing from the second document showed no differences")
+	}
+	if !strings.HasPrefix(m.Detail.Title, "1 differences from stdin .spec to stdin .spec") {
+		t.Errorf("differences titled %q", m.Detail.Title)
+	}
+	if !strings.Contains(m.Detail.Content, "replicas") || strings.Contains(m.Detail.Content, "image") {
+		t.Errorf("differences are\n%s", m.Detail.Content)
+	}
+}
+
+func TestFollowedLinkOpensTab(t *testing.T) {
+	w := workspaceJson(t, `{"links": {"next": "https://example.com/items?page=2"}}`)
+	runWorkspace(w, w.Init())
+	link := linkMsg{Href: "https://example.com/items?page=2", Data: json.RawMessage(`{"items": [1, 2]}`)}
+	runWorkspace(w, wrapDoc(w.Docs[0].id, func() tea.Msg { return link }))
+	if len(w.Docs) != 2 || w.Active != 1 {
+		t.Fatalf("following the link left %d tabs with tab %d shown", len(w.Docs), w.Active+1)
+	}
+	m := w.Current()
+	if m.Source != link.Href || m.Data == nil || m.canRetry() {
+		t.Errorf("the tab shows %q, loaded %v", m.Source, m.Data != nil)
+	}
+	if w.Docs[0].Model.Detail != nil {
+		t.Errorf("the resource was also shown in the detail pane of the first tab")
+	}
+}
@@ -0,0 +1,189 @@
+package jv
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// wrapRows wraps long rows of the listing onto more lines rather than
+// cutting them at the edge of the terminal, it can be set in the config
+// file or from the command line
+var wrapRows = false
+
+// wrapStyled is a utility function that cuts a styled line into lines of
+// at most width characters, the lines after the first indented by indent
+// lines are cut after the last space in them, or where they are full when
+// they have none, and styles open at a cut are closed at the end of the
+// line and opened again after the indent of the next one
+func wrapStyled(s string, width, indent int) []string {
+	if indent >= width {
+		indent = 0
+	}
+	lines := []string{}
+	line := []byte{}
+	active := ""
+	n, limit := 0, width
+	// where the last space of the line is, how many characters come before
+	// it and the styles open at it, space is -1 without one
+	space, spaceN, spaceActive := -1, 0, ""
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "\x1b[") {
+			if loc := sgrPattern.FindStringIndex(s); loc != nil && loc[0] == 0 {
+				seq := s[:loc[1]]
+				line = append(line, seq...)
+				if seq == "\x1b[0m" || seq == "\x1b[m" {
+					active = ""
+				} else {
+					active += seq
+				}
+				s = s[loc[1]:]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		if n == limit {
+			head, rest, open := string(line), "", active
+			// the rest after the space has to fit on the next line
+			if space > 0 && n-spaceN-1 < width-indent {
+				head, rest, open = string(line[:space]), string(line[space+1:]), spaceActive
+				n -= spaceN + 1
+			} else {
+				n = 0
+			}
+			if open != "" {
+				head += "\x1b[0m"
+			}
+			lines = append(lines, head)
+			line = []byte(strings.Repeat(" ", indent) + open + rest)
+			limit = width - indent
+			space = -1
+		}
+		if r == ' ' && n > 0 {
+			space, spaceN, spaceActive = len(line), n, active
+		}
+		line = append(line, s[:size]...)
+		n++
+		s = s[size:]
+	}
+	return append(lines, string(line))
+}
+
+// hangingIndent is a utility function that returns how far the lines a row
+// wraps onto are indented, up to where its value starts unless that is too
+// far along
+func hangingIndent(row string, width int) int {
+	plain := unstyle(row)
+	i := strings.Index(plain, ": ")
+	if i < 0 {
+		return 4
+	}
+	indent := utf8.RuneCountInString(plain[:i]) + 2
+	if indent > width/3 {
+		return 4
+	}
+	return indent
+}
+
+// wrapping checks if the rows of the listing are wrapped, a pinned element
+// beside the rows keeps them cut short since they are laid out in columns
+func (m *Model) wrapping() bool {
+	if !m.Wrap || m.Width == 0 {
+		return false
+	}
+	_, beside := m.besideBase()
+	return !beside
+}
+
+// toggleWrap wraps long rows or cuts them short again
+func (m *Model) toggleWrap() {
+	m.Wrap = !m.Wrap
+	m.syncPage()
+	if m.Wrap {
+		m.Status = "wrapping long rows"
+		return
+	}
+	m.Status = "cutting long rows short"
+}
+
+// listingWidth is how wide the rows of the listing can be drawn
+func (m *Model) listingWidth() int {
+	width := m.Width
+	if m.Outline != nil {
+		width -= m.outlineWidth() + 3
+	}
+	if width < minWidth {
+		width = minWidth
+	}
+	return width
+}
+
+// wrappedRows returns the lines of the rows between start and end once
+// they are wrapped
+func (m *Model) wrappedRows(start, end int) [][]string {
+	if end > len(m.CurrKV) {
+		end = len(m.CurrKV)
+	}
+	if start >= end {
+		return nil
+	}
+	width := m.listingWidth()
+	rows := m.getPageItems(start, end)
+	lines := make([][]string, len(rows))
+	for i, row := range rows {
+		lines[i] = wrapStyled(row, width, hangingIndent(row, width))
+	}
+	return lines
+}
+
+// syncWrap moves the first row shown so that the row under the cursor is
+// shown in full, measuring only the rows between it and the cursor
+// rows take a line or more, so no more rows than there are lines on a page
+// can be between them
+func (m *Model) syncWrap() {
+	height := m.Page.PerPage
+	cursor := m.CurrC.RowNo
+	if m.wrapTop > cursor {
+		m.wrapTop = cursor
+	}
+	if m.wrapTop < 0 {
+		m.wrapTop = 0
+	}
+	start := cursor - height + 1
+	if start < m.wrapTop {
+		start = m.wrapTop
+	}
+	rows := m.wrappedRows(start, cursor+1)
+	lines := 0
+	for i := len(rows) - 1; i >= 0; i-- {
+		lines += len(rows[i])
+		if lines > height && i < len(rows)-1 {
+			m.wrapTop = start + i + 1
+			return
+		}
+	}
+	m.wrapTop = start
+}
+
+// wrappedPage returns the lines of the rows shown from the first row shown,
+// as many rows as fit, and remembers which row every line is of
+// a row longer than the page is cut at its end
+func (m *Model) wrappedPage() []string {
+	height := m.Page.PerPage
+	page := []string{}
+	m.wrapLines = []int{}
+	m.wrapEnd = m.wrapTop
+	for i, lines := range m.wrappedRows(m.wrapTop, m.wrapTop+height) {
+		if len(page)+len(lines) > height {
+			if len(page) > 0 {
+				break
+			}
+			lines = lines[:height]
+		}
+		page = append(page, lines...)
+		for range lines {
+			m.wrapLines = append(m.wrapLines, m.wrapTop+i)
+		}
+		m.wrapEnd = m.wrapTop + i + 1
+	}
+	return page
+}
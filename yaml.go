@@ -0,0 +1,428 @@
+package jv
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlParser reads the subset of YAML that API specs are written in: block
+// mappings and sequences, flow [lists] and {maps}, plain and quoted scalars
+// and | and > block scalars
+// anchors, tags and documents after the first are not supported
+type yamlParser struct {
+	lines []string
+	i     int // line being read
+}
+
+// yamlNumber matches the plain scalars that are numbers, which are kept as
+// json.Number like the numbers of JSON input
+var yamlNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// parseYaml is a utility function that reads a YAML document into the same
+// values that decodeJson returns
+func parseYaml(content []byte) (any, error) {
+	p := &yamlParser{lines: strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")}
+	if !p.skip() {
+		return nil, fmt.Errorf("the document is empty")
+	}
+	if strings.TrimSpace(p.lines[p.i]) == "---" {
+		p.i++
+		if !p.skip() {
+			return nil, nil
+		}
+	}
+	o, err := p.node(p.indent())
+	if err != nil {
+		return nil, err
+	}
+	if p.skip() && strings.TrimSpace(p.lines[p.i]) != "---" && strings.TrimSpace(p.lines[p.i]) != "..." {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return o, nil
+}
+
+// errorf returns an error at the line being read
+func (p *yamlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.i+1, fmt.Sprintf(format, args...))
+}
+
+// skip moves past blank and comment lines and reports whether a line is left
+func (p *yamlParser) skip() bool {
+	for ; p.i < len(p.lines); p.i++ {
+		text := strings.TrimSpace(p.lines[p.i])
+		if text != "" && !strings.HasPrefix(text, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// indent returns the indentation of the line being read
+func (p *yamlParser) indent() int {
+	line := p.lines[p.i]
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// text returns the line being read without its indentation or comment
+func (p *yamlParser) text() string {
+	return stripYamlComment(strings.TrimSpace(p.lines[p.i]))
+}
+
+// node reads the value starting at the line being read, which is indented
+// by indent
+func (p *yamlParser) node(indent int) (any, error) {
+	text := p.text()
+	switch {
+	case text == "-" || strings.HasPrefix(text, "- "):
+		return p.sequence(indent)
+	}
+	if _, _, ok := splitYamlKey(text); ok {
+		return p.mapping(indent)
+	}
+	p.i++
+	return p.inline(text, p.i-1)
+}
+
+// sequence reads the items of a block sequence indented by indent
+func (p *yamlParser) sequence(indent int) (any, error) {
+	items := []any{}
+	for p.skip() && p.indent() == indent {
+		text := p.text()
+		if text != "-" && !strings.HasPrefix(text, "- ") {
+			break
+		}
+		if text == "-" {
+			p.i++
+			item, err := p.child(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		// the dash is blanked out so that what follows it is read like a
+		// value of its own, indented past the dash
+		line := []byte(p.lines[p.i])
+		line[indent] = ' '
+		p.lines[p.i] = string(line)
+		item, err := p.node(p.indent())
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if p.skip() && p.indent() > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return items, nil
+}
+
+// mapping reads the keys and values of a block mapping indented by indent
+func (p *yamlParser) mapping(indent int) (any, error) {
+	m := map[string]any{}
+	for p.skip() && p.indent() == indent {
+		key, rest, ok := splitYamlKey(p.text())
+		if !ok {
+			return nil, p.errorf("expected key: value")
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %s", key)
+		}
+		line := p.i
+		p.i++
+		var val any
+		var err error
+		switch {
+		case rest == "":
+			// a sequence may sit at the same indentation as its key
+			if p.skip() && p.indent() == indent && (p.text() == "-" || strings.HasPrefix(p.text(), "- ")) {
+				val, err = p.sequence(indent)
+			} else {
+				val, err = p.child(indent)
+			}
+		case strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">"):
+			val, err = p.block(indent, rest)
+		default:
+			val, err = p.flow(rest, line)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[key] = val
+	}
+	if p.skip() && p.indent() > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return m, nil
+}
+
+// child reads the value on the lines after a key or dash, which is null
+// unless they are indented further than indent
+func (p *yamlParser) child(indent int) (any, error) {
+	if !p.skip() || p.indent() <= indent {
+		return nil, nil
+	}
+	return p.node(p.indent())
+}
+
+// flow reads a value written on the line of its key, carrying flow
+// collections over the following lines until their brackets are closed
+func (p *yamlParser) flow(text string, line int) (any, error) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		for !yamlBalanced(text) && p.i < len(p.lines) {
+			text += " " + stripYamlComment(strings.TrimSpace(p.lines[p.i]))
+			p.i++
+		}
+	}
+	return p.inline(text, line)
+}
+
+// inline reads a scalar or a flow collection written at the given line
+func (p *yamlParser) inline(text string, line int) (any, error) {
+	o, rest, err := parseYamlFlow(text, false)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", line+1, err)
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("line %d: unexpected %s", line+1, rest)
+	}
+	return o, nil
+}
+
+// block reads a | or > block scalar whose key is indented by indent
+// the header after the key picks whether the last line break is kept
+func (p *yamlParser) block(indent int, header string) (any, error) {
+	lines := []string{}
+	content := -1
+	for ; p.i < len(p.lines); p.i++ {
+		line := p.lines[p.i]
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if n <= indent {
+			break
+		}
+		if content < 0 {
+			content = n
+		}
+		if n < content {
+			return nil, p.errorf("block scalar is less indented than its first line")
+		}
+		lines = append(lines, line[content:])
+	}
+	// trailing blank lines only count with the keep indicator
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines, trailing = lines[:len(lines)-1], trailing+1
+	}
+	var s string
+	if strings.HasPrefix(header, "|") {
+		s = strings.Join(lines, "\n")
+	} else {
+		for i, line := range lines {
+			switch {
+			case i == 0:
+			case line == "" || strings.HasPrefix(line, " "):
+				s += "\n"
+			case lines[i-1] == "" || strings.HasPrefix(lines[i-1], " "):
+			default:
+				s += " "
+			}
+			s += line
+		}
+	}
+	switch {
+	case strings.Contains(header, "-"):
+	case strings.Contains(header, "+"):
+		s += strings.Repeat("\n", trailing+1)
+	case len(lines) > 0:
+		s += "\n"
+	}
+	return s, nil
+}
+
+// splitYamlKey is a utility function that splits key: value, and false if
+// the text is not a mapping entry
+func splitYamlKey(text string) (string, string, bool) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+	end := -1
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") {
+		end = quoteEnd(text)
+		if end < 0 {
+			return "", "", false
+		}
+	}
+	for i := end + 1; i < len(text); i++ {
+		if text[i] != ':' || (i+1 < len(text) && text[i+1] != ' ') {
+			continue
+		}
+		key, err := yamlScalar(strings.TrimSpace(text[:i]))
+		if err != nil {
+			return "", "", false
+		}
+		return fmt.Sprint(key), strings.TrimSpace(text[i+1:]), true
+	}
+	return "", "", false
+}
+
+// parseYamlFlow is a utility function that reads a flow collection or a
+// scalar from the start of text and returns what is left after it
+// inside a collection plain scalars end at a comma or a closing bracket
+func parseYamlFlow(text string, nested bool) (any, string, error) {
+	text = strings.TrimLeft(text, " ")
+	switch {
+	case strings.HasPrefix(text, "["):
+		list := []any{}
+		rest := strings.TrimLeft(text[1:], " ")
+		for !strings.HasPrefix(rest, "]") {
+			item, after, err := parseYamlFlow(rest, true)
+			if err != nil {
+				return nil, "", err
+			}
+			list = append(list, item)
+			if rest = strings.TrimLeft(after, " "); strings.HasPrefix(rest, ",") {
+				rest = strings.TrimLeft(rest[1:], " ")
+			} else if !strings.HasPrefix(rest, "]") {
+				return nil, "", fmt.Errorf("expected , or ] in a flow sequence")
+			}
+		}
+		return list, rest[1:], nil
+	case strings.HasPrefix(text, "{"):
+		m := map[string]any{}
+		rest := strings.TrimLeft(text[1:], " ")
+		for !strings.HasPrefix(rest, "}") {
+			key, after, err := parseYamlFlow(rest, true)
+			if err != nil {
+				return nil, "", err
+			}
+			after = strings.TrimLeft(after, " ")
+			if !strings.HasPrefix(after, ":") {
+				return nil, "", fmt.Errorf("expected : in a flow mapping")
+			}
+			val, after, err := parseYamlFlow(after[1:], true)
+			if err != nil {
+				return nil, "", err
+			}
+			m[fmt.Sprint(key)] = val
+			if rest = strings.TrimLeft(after, " "); strings.HasPrefix(rest, ",") {
+				rest = strings.TrimLeft(rest[1:], " ")
+			} else if !strings.HasPrefix(rest, "}") {
+				return nil, "", fmt.Errorf("expected , or } in a flow mapping")
+			}
+		}
+		return m, rest[1:], nil
+	case strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'"):
+		end := quoteEnd(text)
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated string")
+		}
+		s, err := yamlScalar(text[:end+1])
+		return s, text[end+1:], err
+	}
+	end := len(text)
+	if nested {
+		// a colon only ends a key when a space or the end follows it
+		for i := 0; i < len(text); i++ {
+			c := text[i]
+			if c == ',' || c == ']' || c == '}' || (c == ':' && (i+1 == len(text) || text[i+1] == ' ')) {
+				end = i
+				break
+			}
+		}
+	}
+	s, err := yamlScalar(strings.TrimSpace(text[:end]))
+	return s, text[end:], err
+}
+
+// yamlScalar is a utility function that reads a quoted or plain scalar
+// plain scalars are null, booleans and numbers when they look like them
+func yamlScalar(s string) (any, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		u, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", s)
+		}
+		return u, nil
+	case strings.HasPrefix(s, "'"):
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case strings.HasPrefix(s, "&") || strings.HasPrefix(s, "*") || strings.HasPrefix(s, "!"):
+		return nil, fmt.Errorf("anchors, aliases and tags are not supported")
+	}
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if yamlNumber.MatchString(s) {
+		return json.Number(s), nil
+	}
+	return s, nil
+}
+
+// quoteEnd is a utility function that returns the index of the quote that
+// closes the string text starts with, or -1 if it is not closed
+func quoteEnd(text string) int {
+	q := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case q == '"' && text[i] == '\\':
+			i++
+		case q == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripYamlComment is a utility function that removes a # comment from the
+// end of a line, leaving # inside quotes and words alone
+func stripYamlComment(text string) string {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			// quotes only start strings at the start of a value
+			if i == 0 || strings.ContainsRune(" [{,:", rune(text[i-1])) {
+				if end := quoteEnd(text[i:]); end > 0 {
+					i += end
+				}
+			}
+		case '#':
+			if i == 0 || text[i-1] == ' ' {
+				return strings.TrimSpace(text[:i])
+			}
+		}
+	}
+	return text
+}
+
+// yamlBalanced is a utility function that reports whether the brackets of
+// a flow collection are all closed
+func yamlBalanced(text string) bool {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			if end := quoteEnd(text[i:]); end > 0 {
+				i += end
+			}
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}